@@ -0,0 +1,182 @@
+package main
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// supportedLanguages sind die Sprachen unserer Kundschaft. Die Codes der
+// Auswahlfelder bleiben in allen Sprachen gleich, übersetzt werden nur die
+// Bezeichnungen und Meldungen.
+var supportedLanguages = []string{"de", "fr", "it", "en"}
+
+// defaultLanguage ist die Sprache der E-Mails an den Autosalon
+const defaultLanguage = "de"
+
+// supportedLanguage liefert die unterstützte Sprache eines Tags wie "fr-CH" oder ""
+func supportedLanguage(tag string) string {
+	primary, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+	for _, lang := range supportedLanguages {
+		if primary == lang {
+			return lang
+		}
+	}
+	return ""
+}
+
+// headerValue sucht einen Header ohne Beachtung der Gross-/Kleinschreibung
+func headerValue(headers map[string]string, name string) string {
+	for key, value := range headers {
+		if strings.EqualFold(key, name) {
+			return value
+		}
+	}
+	return ""
+}
+
+// requestLanguage bestimmt die Sprache aus dem Query-Parameter lang oder dem
+// Accept-Language Header. Ohne Angabe bleibt sie leer und die Antworten unverändert.
+func requestLanguage(request events.APIGatewayProxyRequest) string {
+	if lang := supportedLanguage(request.QueryStringParameters["lang"]); lang != "" {
+		return lang
+	}
+
+	best, bestQuality := "", 0.0
+	for _, part := range strings.Split(headerValue(request.Headers, "Accept-Language"), ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		lang := supportedLanguage(tag)
+		if lang == "" {
+			continue
+		}
+
+		quality := 1.0
+		if key, value, ok := strings.Cut(strings.TrimSpace(params), "="); ok && strings.TrimSpace(key) == "q" {
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil {
+				continue
+			}
+			quality = parsed
+		}
+		// Bei gleicher Gewichtung gewinnt die erste Sprache
+		if quality > bestQuality {
+			best, bestQuality = lang, quality
+		}
+	}
+	return best
+}
+
+// languageNames sind die Namen der Sprachen für die E-Mail an den Autosalon
+var languageNames = map[string]string{
+	"de": "Deutsch",
+	"fr": "Französisch",
+	"it": "Italienisch",
+	"en": "Englisch",
+}
+
+// subjectLabels übersetzt die Betreff-Codes des Kontaktformulars
+var subjectLabels = map[string]map[string]string{
+	"fahrzeug-interesse": {"de": "Interesse an einem Fahrzeug", "fr": "Intérêt pour un véhicule", "it": "Interesse per un veicolo", "en": "Interest in a vehicle"},
+	"beratung":           {"de": "Allgemeine Beratung", "fr": "Conseil général", "it": "Consulenza generale", "en": "General advice"},
+	"finanzierung":       {"de": "Finanzierung", "fr": "Financement", "it": "Finanziamento", "en": "Financing"},
+	"service":            {"de": "Service & Wartung", "fr": "Service & entretien", "it": "Servizio & manutenzione", "en": "Service & maintenance"},
+	"sonstiges":          {"de": "Sonstiges", "fr": "Autre", "it": "Altro", "en": "Other"},
+}
+
+// subjectCodes legt die Reihenfolge der Betreffe fest
+var subjectCodes = []string{"fahrzeug-interesse", "beratung", "finanzierung", "service", "sonstiges"}
+
+// zustandLabels übersetzt die Zustands-Codes des Verkaufsformulars
+var zustandLabels = map[string]map[string]string{
+	"sehr-gut":           {"de": "Sehr gut", "fr": "Très bon", "it": "Ottimo", "en": "Very good"},
+	"gut":                {"de": "Gut", "fr": "Bon", "it": "Buono", "en": "Good"},
+	"befriedigend":       {"de": "Befriedigend", "fr": "Satisfaisant", "it": "Discreto", "en": "Fair"},
+	"reparaturbedürftig": {"de": "Reparaturbedürftig", "fr": "À réparer", "it": "Da riparare", "en": "Needs repair"},
+}
+
+// zustandCodes legt die Reihenfolge der Zustände fest
+var zustandCodes = []string{"sehr-gut", "gut", "befriedigend", "reparaturbedürftig"}
+
+// label liefert die Bezeichnung eines Codes in lang, sonst Deutsch, sonst den Code
+func label(labels map[string]map[string]string, code, lang string) string {
+	if translated, ok := labels[code][lang]; ok {
+		return translated
+	}
+	if translated, ok := labels[code][defaultLanguage]; ok {
+		return translated
+	}
+	return code
+}
+
+// messageCatalog übersetzt die englischen Meldungen der API
+var messageCatalog = map[string]map[string]string{
+	"Method not allowed":      {"de": "Methode nicht erlaubt", "fr": "Méthode non autorisée", "it": "Metodo non consentito"},
+	"Invalid request body":    {"de": "Ungültiger Anfrageinhalt", "fr": "Contenu de la requête invalide", "it": "Contenuto della richiesta non valido"},
+	"Unknown form type":       {"de": "Unbekanntes Formular", "fr": "Type de formulaire inconnu", "it": "Tipo di modulo sconosciuto"},
+	"Missing required fields": {"de": "Pflichtfelder fehlen", "fr": "Champs obligatoires manquants", "it": "Campi obbligatori mancanti"},
+	"Failed to send email":    {"de": "E-Mail konnte nicht gesendet werden", "fr": "L'e-mail n'a pas pu être envoyé", "it": "Impossibile inviare l'e-mail"},
+	"Email sent successfully": {"de": "E-Mail erfolgreich gesendet", "fr": "E-mail envoyé avec succès", "it": "E-mail inviata con successo"},
+	"Internal server error":   {"de": "Interner Serverfehler", "fr": "Erreur interne du serveur", "it": "Errore interno del server"},
+}
+
+// translateMessage übersetzt eine Meldung; unbekannte und englische bleiben unverändert
+func translateMessage(lang, message string) string {
+	if translated, ok := messageCatalog[message][lang]; ok {
+		return translated
+	}
+	return message
+}
+
+// addLanguageHeaders setzt Vary und, falls eine Sprache gewünscht ist, Content-Language
+func addLanguageHeaders(response *events.APIGatewayProxyResponse, lang string) {
+	if response.Headers == nil {
+		response.Headers = make(map[string]string)
+	}
+	response.Headers["Vary"] = "Accept-Language"
+	if lang != "" {
+		response.Headers["Content-Language"] = lang
+	}
+}
+
+// errorBody liefert {"error": ...} mit der Meldung in lang
+func errorBody(lang, message string) string {
+	body, _ := json.Marshal(map[string]string{"error": translateMessage(lang, message)})
+	return string(body)
+}
+
+// successBody liefert {"success": true, "message": ...} mit der Meldung in lang
+func successBody(lang, message string) string {
+	body, _ := json.Marshal(map[string]interface{}{"success": true, "message": translateMessage(lang, message)})
+	return string(body)
+}
+
+// FormOption ist ein Eintrag eines Auswahlfelds
+type FormOption struct {
+	Code  string `json:"code"`
+	Label string `json:"label"`
+}
+
+// FormOptions sind die Auswahlfelder der Formulare in einer Sprache
+type FormOptions struct {
+	Language   string       `json:"lang"`
+	Subjects   []FormOption `json:"subjects"`
+	Conditions []FormOption `json:"conditions"`
+}
+
+// formOptions liefert die Auswahlfelder in lang, ohne Angabe auf Deutsch
+func formOptions(lang string) FormOptions {
+	if lang == "" {
+		lang = defaultLanguage
+	}
+
+	options := FormOptions{Language: lang}
+	for _, code := range subjectCodes {
+		options.Subjects = append(options.Subjects, FormOption{Code: code, Label: label(subjectLabels, code, lang)})
+	}
+	for _, code := range zustandCodes {
+		options.Conditions = append(options.Conditions, FormOption{Code: code, Label: label(zustandLabels, code, lang)})
+	}
+	return options
+}
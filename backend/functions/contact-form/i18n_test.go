@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func TestRequestLanguage(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    map[string]string
+		header   string
+		expected string
+	}{
+		{"Swiss French", nil, "fr-CH, fr;q=0.9, en;q=0.8", "fr"},
+		{"Quality wins", nil, "en;q=0.5, it;q=0.8", "it"},
+		{"Unsupported languages are skipped", nil, "es-ES, de-CH;q=0.7", "de"},
+		{"lang parameter wins", map[string]string{"lang": "EN"}, "fr-CH", "en"},
+		{"Nothing requested", nil, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := events.APIGatewayProxyRequest{
+				QueryStringParameters: tt.query,
+				Headers:               map[string]string{"accept-language": tt.header},
+			}
+			if result := requestLanguage(request); result != tt.expected {
+				t.Errorf("requestLanguage() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCatalogsAreComplete(t *testing.T) {
+	for message, translations := range messageCatalog {
+		for _, lang := range []string{"de", "fr", "it"} {
+			if _, ok := translations[lang]; !ok {
+				t.Errorf("%q has no %s translation", message, lang)
+			}
+		}
+	}
+	for _, labels := range []map[string]map[string]string{subjectLabels, zustandLabels} {
+		for code, translations := range labels {
+			for _, lang := range supportedLanguages {
+				if _, ok := translations[lang]; !ok {
+					t.Errorf("%q has no %s label", code, lang)
+				}
+			}
+		}
+	}
+}
+
+func TestHandlerLocalized(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		body     string
+		lang     string
+		status   int
+		expected string
+	}{
+		{"French error", "POST", "{", "fr-CH", 400, "Contenu de la requête invalide"},
+		{"Italian error", "POST", `{"formType":"newsletter"}`, "it", 400, "Tipo di modulo sconosciuto"},
+		{"German error", "POST", `{"formType":"contact","data":{}}`, "de", 400, "Pflichtfelder fehlen"},
+		{"English error", "DELETE", "", "en", 405, "Method not allowed"},
+		{"No language", "POST", "{", "", 400, "Invalid request body"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := Handler(context.Background(), events.APIGatewayProxyRequest{
+				HTTPMethod: tt.method,
+				Body:       tt.body,
+				Headers:    map[string]string{"Accept-Language": tt.lang},
+			})
+			if err != nil {
+				t.Fatalf("Handler() error = %v", err)
+			}
+			if response.StatusCode != tt.status {
+				t.Fatalf("Handler() status = %v, want %v", response.StatusCode, tt.status)
+			}
+			var body struct {
+				Error string `json:"error"`
+			}
+			if err := json.Unmarshal([]byte(response.Body), &body); err != nil || body.Error != tt.expected {
+				t.Errorf("Handler() body = %v, want error %v", response.Body, tt.expected)
+			}
+			if response.Headers["Vary"] != "Accept-Language" {
+				t.Errorf("Handler() Vary = %v", response.Headers["Vary"])
+			}
+		})
+	}
+}
+
+func TestHandlerFormOptions(t *testing.T) {
+	response, err := Handler(context.Background(), events.APIGatewayProxyRequest{
+		HTTPMethod:            "GET",
+		QueryStringParameters: map[string]string{"lang": "fr"},
+	})
+	if err != nil || response.StatusCode != 200 {
+		t.Fatalf("Handler() = %v, %v", response.StatusCode, err)
+	}
+	if response.Headers["Content-Language"] != "fr" {
+		t.Errorf("Handler() Content-Language = %v, want fr", response.Headers["Content-Language"])
+	}
+
+	var options FormOptions
+	if err := json.Unmarshal([]byte(response.Body), &options); err != nil {
+		t.Fatalf("Invalid body %v", response.Body)
+	}
+	if len(options.Subjects) != len(subjectCodes) || options.Subjects[0].Code != "fahrzeug-interesse" || options.Subjects[0].Label != "Intérêt pour un véhicule" {
+		t.Errorf("Unexpected subjects %+v", options.Subjects)
+	}
+	if len(options.Conditions) != len(zustandCodes) || options.Conditions[3].Label != "À réparer" {
+		t.Errorf("Unexpected conditions %+v", options.Conditions)
+	}
+}
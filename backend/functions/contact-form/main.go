@@ -78,10 +78,23 @@ func addCORSHeaders(response *events.APIGatewayProxyResponse) {
 	response.Headers["Access-Control-Allow-Methods"] = "GET,POST,OPTIONS"
 }
 
-// Handler ist die Lambda-Funktion
-func Handler(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+// newResponse liefert eine Antwort mit CORS-Headern in der Sprache lang
+func newResponse(lang string) events.APIGatewayProxyResponse {
 	response := events.APIGatewayProxyResponse{}
 	addCORSHeaders(&response)
+	addLanguageHeaders(&response, lang)
+	return response
+}
+
+// Handler ist die Lambda-Funktion. Meldungen werden in die Sprache des
+// Aufrufers übersetzt, die E-Mails an den Autosalon bleiben Deutsch.
+func Handler(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	return handleForm(request, requestLanguage(request))
+}
+
+// handleForm verarbeitet die Formular-Requests
+func handleForm(request events.APIGatewayProxyRequest, lang string) (events.APIGatewayProxyResponse, error) {
+	response := newResponse(lang)
 
 	// OPTIONS Request für CORS
 	if request.HTTPMethod == "OPTIONS" {
@@ -89,10 +102,25 @@ func Handler(ctx context.Context, request events.APIGatewayProxyRequest) (events
 		return response, nil
 	}
 
-	// Nur POST erlauben
+	// GET liefert die Auswahlfelder der Formulare in der gewünschten Sprache
+	if request.HTTPMethod == "GET" {
+		body, err := json.Marshal(formOptions(lang))
+		if err != nil {
+			log.Printf("Error encoding form options: %v", err)
+			response.StatusCode = 500
+			response.Body = errorBody(lang, "Internal server error")
+			return response, nil
+		}
+		response.StatusCode = 200
+		response.Headers["Content-Type"] = "application/json"
+		response.Body = string(body)
+		return response, nil
+	}
+
+	// Sonst nur POST erlauben
 	if request.HTTPMethod != "POST" {
 		response.StatusCode = 405
-		response.Body = `{"error":"Method not allowed"}`
+		response.Body = errorBody(lang, "Method not allowed")
 		return response, nil
 	}
 
@@ -101,27 +129,26 @@ func Handler(ctx context.Context, request events.APIGatewayProxyRequest) (events
 	if err := json.Unmarshal([]byte(request.Body), &formReq); err != nil {
 		log.Printf("Error parsing request body: %v", err)
 		response.StatusCode = 400
-		response.Body = `{"error":"Invalid request body"}`
+		response.Body = errorBody(lang, "Invalid request body")
 		return response, nil
 	}
 
 	// Je nach Formulartyp verarbeiten
 	switch formReq.FormType {
 	case "contact":
-		return handleContactForm(formReq.Data)
+		return handleContactForm(formReq.Data, lang)
 	case "sell-car":
-		return handleSellCarForm(formReq.Data)
+		return handleSellCarForm(formReq.Data, lang)
 	default:
 		response.StatusCode = 400
-		response.Body = `{"error":"Unknown form type"}`
+		response.Body = errorBody(lang, "Unknown form type")
 		return response, nil
 	}
 }
 
 // handleContactForm verarbeitet das Kontaktformular
-func handleContactForm(data map[string]interface{}) (events.APIGatewayProxyResponse, error) {
-	response := events.APIGatewayProxyResponse{}
-	addCORSHeaders(&response)
+func handleContactForm(data map[string]interface{}, lang string) (events.APIGatewayProxyResponse, error) {
+	response := newResponse(lang)
 
 	// Daten extrahieren
 	name := getString(data, "name")
@@ -133,31 +160,30 @@ func handleContactForm(data map[string]interface{}) (events.APIGatewayProxyRespo
 	// Validierung
 	if name == "" || email == "" || subject == "" || message == "" {
 		response.StatusCode = 400
-		response.Body = `{"error":"Missing required fields"}`
+		response.Body = errorBody(lang, "Missing required fields")
 		return response, nil
 	}
 
 	// E-Mail-Body erstellen
 	emailSubject := fmt.Sprintf("Neue Kontaktanfrage: %s", subject)
-	emailBody := formatContactEmail(name, email, phone, subject, message)
+	emailBody := formatContactEmail(name, email, phone, subject, message, lang)
 
 	// E-Mail senden
 	if err := sendEmail(emailSubject, emailBody, email); err != nil {
 		log.Printf("Error sending email: %v", err)
 		response.StatusCode = 500
-		response.Body = `{"error":"Failed to send email"}`
+		response.Body = errorBody(lang, "Failed to send email")
 		return response, nil
 	}
 
 	response.StatusCode = 200
-	response.Body = `{"success":true,"message":"Email sent successfully"}`
+	response.Body = successBody(lang, "Email sent successfully")
 	return response, nil
 }
 
 // handleSellCarForm verarbeitet das Auto-Verkaufen-Formular
-func handleSellCarForm(data map[string]interface{}) (events.APIGatewayProxyResponse, error) {
-	response := events.APIGatewayProxyResponse{}
-	addCORSHeaders(&response)
+func handleSellCarForm(data map[string]interface{}, lang string) (events.APIGatewayProxyResponse, error) {
+	response := newResponse(lang)
 
 	// Daten extrahieren
 	marke := getString(data, "marke")
@@ -172,29 +198,29 @@ func handleSellCarForm(data map[string]interface{}) (events.APIGatewayProxyRespo
 	// Validierung
 	if marke == "" || modell == "" || baujahr == 0 || kilometerstand == 0 || zustand == "" || name == "" || email == "" {
 		response.StatusCode = 400
-		response.Body = `{"error":"Missing required fields"}`
+		response.Body = errorBody(lang, "Missing required fields")
 		return response, nil
 	}
 
 	// E-Mail-Body erstellen
 	emailSubject := fmt.Sprintf("Auto-Verkaufsanfrage: %s %s (%d)", marke, modell, baujahr)
-	emailBody := formatSellCarEmail(marke, modell, baujahr, kilometerstand, preis, zustand, name, email)
+	emailBody := formatSellCarEmail(marke, modell, baujahr, kilometerstand, preis, zustand, name, email, lang)
 
 	// E-Mail senden
 	if err := sendEmail(emailSubject, emailBody, email); err != nil {
 		log.Printf("Error sending email: %v", err)
 		response.StatusCode = 500
-		response.Body = `{"error":"Failed to send email"}`
+		response.Body = errorBody(lang, "Failed to send email")
 		return response, nil
 	}
 
 	response.StatusCode = 200
-	response.Body = `{"success":true,"message":"Email sent successfully"}`
+	response.Body = successBody(lang, "Email sent successfully")
 	return response, nil
 }
 
 // formatContactEmail formatiert die Kontakt-E-Mail
-func formatContactEmail(name, email, phone, subject, message string) string {
+func formatContactEmail(name, email, phone, subject, message, lang string) string {
 	timestamp := time.Now().Format("02.01.2006 15:04:05")
 
 	html := fmt.Sprintf(`
@@ -235,6 +261,7 @@ func formatContactEmail(name, email, phone, subject, message string) string {
                 <span class="value"><a href="mailto:%s">%s</a></span>
             </div>
             
+            %s
             %s
             
             <div class="field">
@@ -262,14 +289,15 @@ func formatContactEmail(name, email, phone, subject, message string) string {
 			}
 			return ""
 		}(),
-		getSubjectLabel(subject),
+		formatLanguageField(lang),
+		getSubjectLabel(subject, defaultLanguage),
 		strings.ReplaceAll(message, "\n", "<br>"))
 
 	return html
 }
 
 // formatSellCarEmail formatiert die Auto-Verkaufs-E-Mail
-func formatSellCarEmail(marke, modell string, baujahr, kilometerstand, preis int, zustand, name, email string) string {
+func formatSellCarEmail(marke, modell string, baujahr, kilometerstand, preis int, zustand, name, email, lang string) string {
 	timestamp := time.Now().Format("02.01.2006 15:04:05")
 
 	preisStr := "Nicht angegeben"
@@ -344,6 +372,7 @@ func formatSellCarEmail(marke, modell string, baujahr, kilometerstand, preis int
                     <span class="label">E-Mail:</span>
                     <span class="value"><a href="mailto:%s">%s</a></span>
                 </div>
+                %s
             </div>
         </div>
         
@@ -354,7 +383,7 @@ func formatSellCarEmail(marke, modell string, baujahr, kilometerstand, preis int
     </div>
 </body>
 </html>
-`, timestamp, marke, modell, baujahr, kilometerstand, preisStr, getZustandLabel(zustand), name, email, email)
+`, timestamp, marke, modell, baujahr, kilometerstand, preisStr, getZustandLabel(zustand, defaultLanguage), name, email, email, formatLanguageField(lang))
 
 	return html
 }
@@ -407,31 +436,22 @@ func getInt(data map[string]interface{}, key string) int {
 	return 0
 }
 
-func getSubjectLabel(subject string) string {
-	labels := map[string]string{
-		"fahrzeug-interesse": "Interesse an einem Fahrzeug",
-		"beratung":           "Allgemeine Beratung",
-		"finanzierung":       "Finanzierung",
-		"service":            "Service & Wartung",
-		"sonstiges":          "Sonstiges",
+// formatLanguageField zeigt die Sprache des Kunden an, damit in dieser geantwortet werden kann
+func formatLanguageField(lang string) string {
+	if lang == "" || lang == defaultLanguage {
+		return ""
 	}
-	if label, ok := labels[subject]; ok {
-		return label
-	}
-	return subject
+	return fmt.Sprintf(`<div class="field"><span class="label">Sprache:</span><span class="value">%s</span></div>`, languageNames[lang])
 }
 
-func getZustandLabel(zustand string) string {
-	labels := map[string]string{
-		"sehr-gut":           "Sehr gut",
-		"gut":                "Gut",
-		"befriedigend":       "Befriedigend",
-		"reparaturbedürftig": "Reparaturbedürftig",
-	}
-	if label, ok := labels[zustand]; ok {
-		return label
-	}
-	return zustand
+// getSubjectLabel liefert die Bezeichnung eines Betreffs in lang
+func getSubjectLabel(subject, lang string) string {
+	return label(subjectLabels, subject, lang)
+}
+
+// getZustandLabel liefert die Bezeichnung eines Zustands in lang
+func getZustandLabel(zustand, lang string) string {
+	return label(zustandLabels, zustand, lang)
 }
 
 func main() {
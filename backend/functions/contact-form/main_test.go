@@ -87,21 +87,26 @@ func TestGetInt(t *testing.T) {
 func TestGetSubjectLabel(t *testing.T) {
 	tests := []struct {
 		subject  string
+		lang     string
 		expected string
 	}{
-		{"fahrzeug-interesse", "Interesse an einem Fahrzeug"},
-		{"beratung", "Allgemeine Beratung"},
-		{"finanzierung", "Finanzierung"},
-		{"service", "Service & Wartung"},
-		{"sonstiges", "Sonstiges"},
-		{"unknown", "unknown"}, // fallback to original value
+		{"fahrzeug-interesse", "de", "Interesse an einem Fahrzeug"},
+		{"beratung", "de", "Allgemeine Beratung"},
+		{"finanzierung", "de", "Finanzierung"},
+		{"service", "de", "Service & Wartung"},
+		{"sonstiges", "de", "Sonstiges"},
+		{"unknown", "de", "unknown"}, // fallback to original value
+		{"beratung", "fr", "Conseil général"},
+		{"service", "it", "Servizio & manutenzione"},
+		{"finanzierung", "en", "Financing"},
+		{"sonstiges", "", "Sonstiges"}, // fallback to German
 	}
 
 	for _, tt := range tests {
-		t.Run(tt.subject, func(t *testing.T) {
-			result := getSubjectLabel(tt.subject)
+		t.Run(tt.subject+"/"+tt.lang, func(t *testing.T) {
+			result := getSubjectLabel(tt.subject, tt.lang)
 			if result != tt.expected {
-				t.Errorf("getSubjectLabel(%s) = %v, want %v", tt.subject, result, tt.expected)
+				t.Errorf("getSubjectLabel(%s, %s) = %v, want %v", tt.subject, tt.lang, result, tt.expected)
 			}
 		})
 	}
@@ -110,20 +115,24 @@ func TestGetSubjectLabel(t *testing.T) {
 func TestGetZustandLabel(t *testing.T) {
 	tests := []struct {
 		zustand  string
+		lang     string
 		expected string
 	}{
-		{"sehr-gut", "Sehr gut"},
-		{"gut", "Gut"},
-		{"befriedigend", "Befriedigend"},
-		{"reparaturbedürftig", "Reparaturbedürftig"},
-		{"unknown", "unknown"}, // fallback to original value
+		{"sehr-gut", "de", "Sehr gut"},
+		{"gut", "de", "Gut"},
+		{"befriedigend", "de", "Befriedigend"},
+		{"reparaturbedürftig", "de", "Reparaturbedürftig"},
+		{"unknown", "de", "unknown"}, // fallback to original value
+		{"reparaturbedürftig", "fr", "À réparer"},
+		{"sehr-gut", "it", "Ottimo"},
+		{"befriedigend", "en", "Fair"},
 	}
 
 	for _, tt := range tests {
-		t.Run(tt.zustand, func(t *testing.T) {
-			result := getZustandLabel(tt.zustand)
+		t.Run(tt.zustand+"/"+tt.lang, func(t *testing.T) {
+			result := getZustandLabel(tt.zustand, tt.lang)
 			if result != tt.expected {
-				t.Errorf("getZustandLabel(%s) = %v, want %v", tt.zustand, result, tt.expected)
+				t.Errorf("getZustandLabel(%s, %s) = %v, want %v", tt.zustand, tt.lang, result, tt.expected)
 			}
 		})
 	}
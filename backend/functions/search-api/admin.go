@@ -0,0 +1,286 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// carStore persists cars written through the admin API; nil disables the admin API
+var carStore CarStore
+
+// carStoreFromEnv returns the DynamoDB store named by CARS_TABLE, or nil if it is not set
+func carStoreFromEnv() CarStore {
+	if table := os.Getenv("CARS_TABLE"); table != "" {
+		return newDynamoCarStore(table)
+	}
+	return nil
+}
+
+// jsonResponse marshals v as the response body
+func jsonResponse(status int, headers map[string]string, v interface{}) events.APIGatewayProxyResponse {
+	body, err := json.Marshal(v)
+	if err != nil {
+		log.Printf("Error marshaling response: %v", err)
+		return errorResponse(http.StatusInternalServerError, headers, "", msgInternalError)
+	}
+	return events.APIGatewayProxyResponse{
+		StatusCode: status,
+		Headers:    headers,
+		Body:       string(body),
+	}
+}
+
+// errorResponse returns an ErrorResponse body with the given status and
+// message key. The messages are rendered in lang, English if it is empty.
+func errorResponse(status int, headers map[string]string, lang, key string, validations ...ValidationError) events.APIGatewayProxyResponse {
+	for i, validation := range validations {
+		validations[i].Message = messageText(lang, validation.Code, validation.Params...)
+	}
+	return jsonResponse(status, headers, ErrorResponse{Error: messageText(lang, key), Code: key, Validations: validations})
+}
+
+// validateCar checks a car submitted through the admin API. It applies the
+// limits used for search requests and then parses the car like a catalogue
+// row, so everything accepted here also loads from the store.
+func validateCar(car Car) (Car, []ValidationError) {
+	var errors []ValidationError
+
+	if !validateIntRange(car.ID, 1, math.MaxInt32) {
+		errors = append(errors, newValidationError("id", msgIDPositive))
+	}
+
+	required := []struct {
+		field string
+		value string
+	}{
+		{"title", car.Title},
+		{"first_registration", car.FirstReg},
+		{"car_type", car.CarType},
+		{"transmission", car.Transmission},
+		{"fuel", car.Fuel},
+		{"drive", car.Drive},
+	}
+	for _, r := range required {
+		if strings.TrimSpace(r.value) == "" {
+			errors = append(errors, newValidationError(r.field, msgRequired))
+		}
+	}
+
+	texts := []struct {
+		field string
+		value string
+	}{
+		{"title", car.Title},
+		{"leasing_text", car.LeasingText},
+		{"warranty_text", car.WarrantyText},
+		{"description", car.Description},
+	}
+	for _, text := range texts {
+		if len(text.value) > MaxStringLength {
+			errors = append(errors, newValidationError(text.field, msgTextTooLong, MaxStringLength))
+		}
+	}
+
+	if !validateIntRange(car.PriceCHF, MinPrice, MaxPrice) {
+		errors = append(errors, newValidationError("price_chf", msgPriceRange, MinPrice, MaxPrice))
+	}
+	if !validateIntRange(car.MileageKM, MinMileage, MaxMileage) {
+		errors = append(errors, newValidationError("mileage_km", msgMileageRange, MinMileage, MaxMileage))
+	}
+	if !validateIntRange(car.PowerHP, MinPower, MaxPower) {
+		errors = append(errors, newValidationError("power_hp", msgPowerRange, MinPower, MaxPower))
+	}
+	if !validateIntRange(car.PowerKW, MinPower, MaxPower) {
+		errors = append(errors, newValidationError("power_kw", msgPowerRange, MinPower, MaxPower))
+	}
+
+	for _, url := range car.ImageURLs {
+		url = strings.TrimSpace(url)
+		if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+			errors = append(errors, newValidationError("image_urls", msgInvalidImageURL))
+			break
+		}
+	}
+
+	if len(errors) > 0 {
+		return Car{}, errors
+	}
+
+	parsed, err := parseCarRecord(defaultCSVHeader, carToRecord(car))
+	if err != nil {
+		log.Printf("Error parsing admin car %d: %v", car.ID, err)
+		return Car{}, []ValidationError{newValidationError("car", msgInvalidCar)}
+	}
+	return parsed, nil
+}
+
+// handleAdminCars serves POST /admin/cars and PUT/PATCH/DELETE /admin/cars/{id}.
+// Callers are authenticated by requireRole before it runs.
+func handleAdminCars(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	lang := requestLanguage(request)
+	return adminCarsResponse(ctx, request, lang, languageHeaders(lang)), nil
+}
+
+func adminCarsResponse(ctx context.Context, request events.APIGatewayProxyRequest, lang string, headers map[string]string) events.APIGatewayProxyResponse {
+	if carStore == nil {
+		return errorResponse(http.StatusServiceUnavailable, headers, lang, msgAdminNotConfigured)
+	}
+
+	// Cars of the fallback catalogue move into the store with the first write
+	if source, ok := catalogue.source.(storeCatalogueSource); ok {
+		if err := seedCarStore(ctx, source); err != nil {
+			return storeErrorResponse(err, lang, headers)
+		}
+	}
+
+	if request.Resource == "/admin/cars" {
+		if request.HTTPMethod != "POST" {
+			return errorResponse(http.StatusMethodNotAllowed, headers, lang, msgMethodNotAllowed)
+		}
+		return createCar(ctx, request, lang, headers)
+	}
+
+	id, err := strconv.Atoi(request.PathParameters["id"])
+	if err != nil || id <= 0 {
+		return errorResponse(http.StatusBadRequest, headers, lang, msgInvalidCarID)
+	}
+
+	switch request.HTTPMethod {
+	case "PUT", "PATCH":
+		existing, err := carStore.Get(ctx, id)
+		if err != nil {
+			return storeErrorResponse(err, lang, headers)
+		}
+
+		// PATCH starts from the stored car, so fields missing from the body keep their values
+		car := Car{}
+		if request.HTTPMethod == "PATCH" {
+			car = existing
+		}
+		if err := json.Unmarshal([]byte(request.Body), &car); err != nil {
+			return errorResponse(http.StatusBadRequest, headers, lang, msgInvalidJSON)
+		}
+		return updateCar(ctx, id, existing.Status, car, lang, headers)
+	case "DELETE":
+		if err := carStore.Delete(ctx, id); err != nil {
+			return storeErrorResponse(err, lang, headers)
+		}
+		reloadAfterWrite(ctx)
+		return events.APIGatewayProxyResponse{StatusCode: http.StatusNoContent, Headers: headers}
+	default:
+		return errorResponse(http.StatusMethodNotAllowed, headers, lang, msgMethodNotAllowed)
+	}
+}
+
+// createCar stores a new car, assigning the next free ID if none is given
+func createCar(ctx context.Context, request events.APIGatewayProxyRequest, lang string, headers map[string]string) events.APIGatewayProxyResponse {
+	var car Car
+	if err := json.Unmarshal([]byte(request.Body), &car); err != nil {
+		return errorResponse(http.StatusBadRequest, headers, lang, msgInvalidJSON)
+	}
+
+	if car.ID == 0 {
+		existing, err := carStore.List(ctx)
+		if err != nil {
+			return storeErrorResponse(err, lang, headers)
+		}
+		car.ID = 1
+		if len(existing) > 0 {
+			car.ID = existing[len(existing)-1].ID + 1
+		}
+	}
+
+	if validationErrors := prepareStatus("", &car); len(validationErrors) > 0 {
+		return errorResponse(http.StatusBadRequest, headers, lang, msgValidationFailed, validationErrors...)
+	}
+
+	parsed, validationErrors := validateCar(car)
+	if len(validationErrors) > 0 {
+		return errorResponse(http.StatusBadRequest, headers, lang, msgValidationFailed, validationErrors...)
+	}
+
+	// Brand and model are always derived from the title
+	car.Brand = ""
+	car.Model = ""
+	if err := carStore.Create(ctx, car); err != nil {
+		return storeErrorResponse(err, lang, headers)
+	}
+
+	reloadAfterWrite(ctx)
+	return jsonResponse(http.StatusCreated, headers, parsed)
+}
+
+// updateCar replaces the stored car with the given ID; previousStatus is the stored status
+func updateCar(ctx context.Context, id int, previousStatus string, car Car, lang string, headers map[string]string) events.APIGatewayProxyResponse {
+	if car.ID != 0 && car.ID != id {
+		return errorResponse(http.StatusBadRequest, headers, lang, msgValidationFailed, newValidationError("id", msgIDMismatch))
+	}
+	car.ID = id
+
+	// Cars stored before the status was introduced are available
+	if previousStatus == "" {
+		previousStatus = StatusAvailable
+	}
+	if validationErrors := prepareStatus(previousStatus, &car); len(validationErrors) > 0 {
+		return errorResponse(http.StatusBadRequest, headers, lang, msgValidationFailed, validationErrors...)
+	}
+
+	parsed, validationErrors := validateCar(car)
+	if len(validationErrors) > 0 {
+		return errorResponse(http.StatusBadRequest, headers, lang, msgValidationFailed, validationErrors...)
+	}
+
+	car.Brand = ""
+	car.Model = ""
+	if err := carStore.Update(ctx, car); err != nil {
+		return storeErrorResponse(err, lang, headers)
+	}
+
+	reloadAfterWrite(ctx)
+	return jsonResponse(http.StatusOK, headers, parsed)
+}
+
+// prepareStatus normalizes the submitted status and stamps status changes
+func prepareStatus(previousStatus string, car *Car) []ValidationError {
+	status, err := parseCarStatus(car.Status)
+	if err != nil {
+		return []ValidationError{newValidationError("status", msgInvalidStatus)}
+	}
+	car.Status = status
+	applyStatusChange(previousStatus, car, time.Now())
+	return nil
+}
+
+// storeErrorResponse maps CarStore errors to HTTP responses
+func storeErrorResponse(err error, lang string, headers map[string]string) events.APIGatewayProxyResponse {
+	switch {
+	case errors.Is(err, ErrCarNotFound):
+		return errorResponse(http.StatusNotFound, headers, lang, msgCarNotFound)
+	case errors.Is(err, ErrCarExists):
+		return errorResponse(http.StatusConflict, headers, lang, msgCarExists)
+	default:
+		log.Printf("Error accessing car store: %v", err)
+		return errorResponse(http.StatusInternalServerError, headers, lang, msgInternalError)
+	}
+}
+
+// reloadAfterWrite publishes admin changes on this instance right away;
+// other instances pick them up through CATALOGUE_TTL
+func reloadAfterWrite(ctx context.Context) {
+	if _, ok := catalogue.source.(storeCatalogueSource); !ok {
+		return
+	}
+	if _, err := catalogue.Reload(ctx); err != nil {
+		log.Printf("Error reloading catalogue after admin change: %v", err)
+	}
+}
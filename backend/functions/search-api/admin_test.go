@@ -0,0 +1,276 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+const testAdminKey = "test-admin-key"
+
+// setTestCarStore serves the catalogue from an in-memory store for the duration of a test
+func setTestCarStore(t *testing.T, initial ...Car) *memoryCarStore {
+	t.Helper()
+	setTestAuth(t, &authenticator{apiKeyHashes: testAPIKeyHashes(t, testAdminKey), now: time.Now})
+
+	store := newMemoryCarStore(initial...)
+	originalStore, originalCatalogue := carStore, catalogue
+	carStore = store
+	catalogue = newCatalogueHolder(storeCatalogueSource{Store: store, Fallback: embeddedCatalogueSource{}}, 0)
+	if _, err := catalogue.Reload(context.Background()); err != nil {
+		t.Fatalf("Failed to load catalogue: %v", err)
+	}
+	t.Cleanup(func() { carStore, catalogue = originalStore, originalCatalogue })
+	return store
+}
+
+func testStoredCar(id int, title string) Car {
+	return Car{
+		ID:           id,
+		Title:        title,
+		PriceCHF:     42890,
+		FirstReg:     "08.2021",
+		CarType:      "Limousine",
+		MileageKM:    55000,
+		Transmission: "Automatik",
+		Fuel:         "Diesel",
+		Drive:        "Allrad",
+		PowerHP:      190,
+		PowerKW:      140,
+	}
+}
+
+func adminRequest(method, resource, id, body string) events.APIGatewayProxyRequest {
+	request := events.APIGatewayProxyRequest{
+		Resource:   resource,
+		HTTPMethod: method,
+		Headers:    map[string]string{"x-api-key": testAdminKey},
+		Body:       body,
+	}
+	if id != "" {
+		request.PathParameters = map[string]string{"id": id}
+	}
+	return request
+}
+
+func TestAdminAuthorization(t *testing.T) {
+	setTestCarStore(t, testStoredCar(1, "BMW 520d"))
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+	}{
+		{name: "Missing key", headers: nil},
+		{name: "Wrong key", headers: map[string]string{"X-Api-Key": "wrong"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := adminRequest("DELETE", "/admin/cars/{id}", "1", "")
+			request.Headers = tt.headers
+
+			response, _ := handleRequest(context.Background(), request)
+			if response.StatusCode != http.StatusUnauthorized {
+				t.Errorf("Expected status %d, got %d", http.StatusUnauthorized, response.StatusCode)
+			}
+		})
+	}
+
+	// Without configured authentication the admin API stays closed
+	auth = nil
+	if response, _ := handleRequest(context.Background(), adminRequest("DELETE", "/admin/cars/{id}", "1", "")); response.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected status %d without authentication, got %d", http.StatusUnauthorized, response.StatusCode)
+	}
+}
+
+func TestAdminCarsWithoutStore(t *testing.T) {
+	setTestAuth(t, &authenticator{apiKeyHashes: testAPIKeyHashes(t, testAdminKey), now: time.Now})
+	original := carStore
+	carStore = nil
+	defer func() { carStore = original }()
+
+	response, _ := handleRequest(context.Background(), adminRequest("POST", "/admin/cars", "", `{}`))
+	if response.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected status %d, got %d", http.StatusServiceUnavailable, response.StatusCode)
+	}
+}
+
+func TestAdminCarsCRUD(t *testing.T) {
+	store := setTestCarStore(t, testStoredCar(1, "BMW 520d"))
+	ctx := context.Background()
+
+	// Create assigns the next ID and publishes the car to the search
+	created := testStoredCar(0, "Audi A4 Avant")
+	body, _ := json.Marshal(created)
+	response, _ := handleRequest(ctx, adminRequest("POST", "/admin/cars", "", string(body)))
+	if response.StatusCode != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusCreated, response.StatusCode, response.Body)
+	}
+	var car Car
+	if err := json.Unmarshal([]byte(response.Body), &car); err != nil {
+		t.Fatalf("Invalid response body: %v", err)
+	}
+	if car.ID != 2 || car.Brand != "Audi" {
+		t.Errorf("Expected car 2 with brand Audi, got %d / %q", car.ID, car.Brand)
+	}
+	if result := searchCars(SearchRequest{Brand: "Audi"}); result.Total != 1 {
+		t.Errorf("Expected created car in search results, got %d", result.Total)
+	}
+
+	// Creating an existing ID conflicts
+	body, _ = json.Marshal(testStoredCar(1, "BMW 320d"))
+	response, _ = handleRequest(ctx, adminRequest("POST", "/admin/cars", "", string(body)))
+	if response.StatusCode != http.StatusConflict {
+		t.Errorf("Expected status %d, got %d", http.StatusConflict, response.StatusCode)
+	}
+
+	// PUT replaces the car
+	replaced := testStoredCar(1, "BMW 320d")
+	replaced.PriceCHF = 31000
+	body, _ = json.Marshal(replaced)
+	response, _ = handleRequest(ctx, adminRequest("PUT", "/admin/cars/{id}", "1", string(body)))
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, response.StatusCode, response.Body)
+	}
+	if stored, _ := store.Get(ctx, 1); stored.Title != "BMW 320d" || stored.PriceCHF != 31000 {
+		t.Errorf("Unexpected stored car: %+v", stored)
+	}
+
+	// PATCH only changes the given fields
+	response, _ = handleRequest(ctx, adminRequest("PATCH", "/admin/cars/{id}", "1", `{"price_chf": 29500}`))
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, response.StatusCode, response.Body)
+	}
+	if stored, _ := store.Get(ctx, 1); stored.Title != "BMW 320d" || stored.PriceCHF != 29500 {
+		t.Errorf("Unexpected stored car: %+v", stored)
+	}
+	if result := searchCars(SearchRequest{MaxPrice: intPtr(30000)}); result.Total != 1 {
+		t.Errorf("Expected patched price in search results, got %d", result.Total)
+	}
+
+	// DELETE removes the car
+	response, _ = handleRequest(ctx, adminRequest("DELETE", "/admin/cars/{id}", "2", ""))
+	if response.StatusCode != http.StatusNoContent {
+		t.Errorf("Expected status %d, got %d", http.StatusNoContent, response.StatusCode)
+	}
+	if result := searchCars(SearchRequest{Brand: "Audi"}); result.Total != 0 {
+		t.Errorf("Expected deleted car to be removed from search, got %d", result.Total)
+	}
+
+	// Unknown IDs
+	for _, method := range []string{"PUT", "PATCH", "DELETE"} {
+		body, _ = json.Marshal(testStoredCar(0, "Skoda Octavia"))
+		response, _ = handleRequest(ctx, adminRequest(method, "/admin/cars/{id}", "99", string(body)))
+		if response.StatusCode != http.StatusNotFound {
+			t.Errorf("%s: expected status %d, got %d", method, http.StatusNotFound, response.StatusCode)
+		}
+	}
+}
+
+func TestAdminCarsKeepFallbackCatalogue(t *testing.T) {
+	store := setTestCarStore(t)
+	ctx := context.Background()
+	before := currentCars()
+
+	// Creating a car while autos.csv is served keeps its cars and IDs
+	body, _ := json.Marshal(testStoredCar(0, "Skoda Octavia"))
+	response, _ := handleRequest(ctx, adminRequest("POST", "/admin/cars", "", string(body)))
+	if response.StatusCode != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusCreated, response.StatusCode, response.Body)
+	}
+	var created Car
+	if err := json.Unmarshal([]byte(response.Body), &created); err != nil {
+		t.Fatalf("Invalid response body: %v", err)
+	}
+	if want := before[len(before)-1].ID + 1; created.ID != want {
+		t.Errorf("Expected ID %d, got %d", want, created.ID)
+	}
+
+	after := currentCars()
+	if len(after) != len(before)+1 {
+		t.Fatalf("Expected %d cars, got %d", len(before)+1, len(after))
+	}
+	for i, car := range before {
+		if after[i].ID != car.ID || after[i].Title != car.Title || after[i].WarrantyText != car.WarrantyText {
+			t.Errorf("Car %d changed: %q / %q", car.ID, after[i].Title, after[i].WarrantyText)
+		}
+	}
+
+	// The store keeps the texts unescaped
+	if stored, _ := store.Get(ctx, 1); stored.WarrantyText != "Ab 1. Inverkehrsetzung, 19.08.2021, 24 Monate oder 100'000 km" {
+		t.Errorf("Unexpected stored warranty text %q", stored.WarrantyText)
+	}
+
+	// Cars from autos.csv can be edited
+	response, _ = handleRequest(ctx, adminRequest("PATCH", "/admin/cars/{id}", "2", `{"price_chf": 36900}`))
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, response.StatusCode, response.Body)
+	}
+	if car, ok := findCar(2); !ok || car.PriceCHF != 36900 {
+		t.Errorf("Expected patched price for car 2, got %+v", car)
+	}
+	// Deleting every car empties the catalogue instead of seeding it again
+	for _, car := range after {
+		response, _ = handleRequest(ctx, adminRequest("DELETE", "/admin/cars/{id}", strconv.Itoa(car.ID), ""))
+		if response.StatusCode != http.StatusNoContent {
+			t.Fatalf("Expected status %d, got %d: %s", http.StatusNoContent, response.StatusCode, response.Body)
+		}
+	}
+	if cars := currentCars(); len(cars) != 0 {
+		t.Errorf("Expected an empty catalogue, got %d cars", len(cars))
+	}
+	response, _ = handleRequest(ctx, adminRequest("POST", "/admin/cars", "", string(body)))
+	if response.StatusCode != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusCreated, response.StatusCode, response.Body)
+	}
+	if cars := currentCars(); len(cars) != 1 {
+		t.Errorf("Expected only the new car, got %d cars", len(cars))
+	}
+}
+
+func TestAdminCarsValidation(t *testing.T) {
+	setTestCarStore(t, testStoredCar(1, "BMW 520d"))
+
+	negativePrice := testStoredCar(0, "Audi A4")
+	negativePrice.PriceCHF = -1
+	badImage := testStoredCar(0, "Audi A4")
+	badImage.ImageURLs = []string{"javascript:alert(1)"}
+	missingFuel := testStoredCar(0, "Audi A4")
+	missingFuel.Fuel = ""
+	mismatchedID := testStoredCar(2, "Audi A4")
+
+	tests := []struct {
+		name     string
+		method   string
+		resource string
+		id       string
+		car      interface{}
+		field    string
+	}{
+		{name: "Negative price", method: "POST", resource: "/admin/cars", car: negativePrice, field: "price_chf"},
+		{name: "Invalid image URL", method: "POST", resource: "/admin/cars", car: badImage, field: "image_urls"},
+		{name: "Missing fuel", method: "POST", resource: "/admin/cars", car: missingFuel, field: "fuel"},
+		{name: "ID mismatch", method: "PUT", resource: "/admin/cars/{id}", id: "1", car: mismatchedID, field: "id"},
+		{name: "Invalid JSON", method: "POST", resource: "/admin/cars", car: "not a car"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, _ := json.Marshal(tt.car)
+			response, _ := handleRequest(context.Background(), adminRequest(tt.method, tt.resource, tt.id, string(body)))
+
+			if response.StatusCode != http.StatusBadRequest {
+				t.Fatalf("Expected status %d, got %d", http.StatusBadRequest, response.StatusCode)
+			}
+			if tt.field != "" && !strings.Contains(response.Body, `"field":"`+tt.field+`"`) {
+				t.Errorf("Expected validation error for %s, got %s", tt.field, response.Body)
+			}
+		})
+	}
+}
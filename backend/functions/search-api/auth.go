@@ -0,0 +1,306 @@
+package main
+
+import (
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// Roles granted by API keys and JWT role claims
+const (
+	RoleAdmin = "admin"
+)
+
+// Allowed clock skew when checking exp and nbf
+const jwtLeeway = 30 * time.Second
+
+var (
+	errNoCredentials      = errors.New("no credentials")
+	errInvalidCredentials = errors.New("invalid credentials")
+)
+
+// principal is an authenticated caller
+type principal struct {
+	Subject string
+	Roles   []string
+}
+
+// hasRole reports whether the principal was granted role
+func (p principal) hasRole(role string) bool {
+	for _, r := range p.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// authenticator verifies API keys and JWTs. API keys are only known by their
+// SHA-256 hash and grant the admin role; JWTs carry their roles in the
+// "roles" (list) or "role" (string) claim.
+type authenticator struct {
+	apiKeyHashes [][]byte
+	hmacSecret   []byte
+	rsaKey       *rsa.PublicKey
+	issuer       string
+	audience     string
+	now          func() time.Time
+}
+
+// auth authenticates requests to protected routes; nil rejects every request
+var auth *authenticator
+
+// authenticatorFromEnv configures authentication from the environment:
+// AUTH_API_KEY_SHA256 (comma-separated hex hashes), AUTH_JWT_HS256_SECRET,
+// AUTH_JWT_RS256_PUBLIC_KEY (PEM) and optionally AUTH_JWT_ISSUER and AUTH_JWT_AUDIENCE
+func authenticatorFromEnv() (*authenticator, error) {
+	a := &authenticator{
+		issuer:   os.Getenv("AUTH_JWT_ISSUER"),
+		audience: os.Getenv("AUTH_JWT_AUDIENCE"),
+		now:      time.Now,
+	}
+
+	for _, value := range strings.Split(os.Getenv("AUTH_API_KEY_SHA256"), ",") {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		hash, err := hex.DecodeString(value)
+		if err != nil || len(hash) != sha256.Size {
+			return nil, fmt.Errorf("invalid AUTH_API_KEY_SHA256 entry %q", value)
+		}
+		a.apiKeyHashes = append(a.apiKeyHashes, hash)
+	}
+
+	if secret := os.Getenv("AUTH_JWT_HS256_SECRET"); secret != "" {
+		a.hmacSecret = []byte(secret)
+	}
+
+	if value := os.Getenv("AUTH_JWT_RS256_PUBLIC_KEY"); value != "" {
+		key, err := parseRSAPublicKey([]byte(value))
+		if err != nil {
+			return nil, fmt.Errorf("invalid AUTH_JWT_RS256_PUBLIC_KEY: %w", err)
+		}
+		a.rsaKey = key
+	}
+
+	return a, nil
+}
+
+// parseRSAPublicKey reads a PKIX or PKCS#1 PEM encoded RSA public key
+func parseRSAPublicKey(data []byte) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+	if key, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := parsed.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("not an RSA public key")
+	}
+	return key, nil
+}
+
+// hashAPIKey returns the hex SHA-256 hash used in AUTH_API_KEY_SHA256
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// Authenticate identifies the caller from the X-Api-Key header or a bearer token
+func (a *authenticator) Authenticate(request events.APIGatewayProxyRequest) (principal, error) {
+	if key := headerValue(request.Headers, "X-Api-Key"); key != "" {
+		return a.authenticateAPIKey(key)
+	}
+
+	authorization := headerValue(request.Headers, "Authorization")
+	if authorization == "" {
+		return principal{}, errNoCredentials
+	}
+	scheme, token, found := strings.Cut(authorization, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return principal{}, errInvalidCredentials
+	}
+	return a.verifyJWT(strings.TrimSpace(token))
+}
+
+func (a *authenticator) authenticateAPIKey(key string) (principal, error) {
+	sum := sha256.Sum256([]byte(key))
+	for _, hash := range a.apiKeyHashes {
+		if subtle.ConstantTimeCompare(sum[:], hash) == 1 {
+			return principal{Subject: "api-key", Roles: []string{RoleAdmin}}, nil
+		}
+	}
+	return principal{}, errInvalidCredentials
+}
+
+// jwtClaims holds the registered claims we check plus the role claims
+type jwtClaims struct {
+	Subject   string          `json:"sub"`
+	Issuer    string          `json:"iss"`
+	Audience  json.RawMessage `json:"aud"`
+	ExpiresAt *float64        `json:"exp"`
+	NotBefore *float64        `json:"nbf"`
+	Roles     []string        `json:"roles"`
+	Role      string          `json:"role"`
+}
+
+// verifyJWT checks signature, expiry, issuer and audience of a compact JWT.
+// The algorithm must match a configured key, so an HS256 token can never be
+// verified with the RSA public key as secret.
+func (a *authenticator) verifyJWT(token string) (principal, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return principal{}, errInvalidCredentials
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := decodeJWTSegment(parts[0], &header); err != nil {
+		return principal{}, errInvalidCredentials
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return principal{}, errInvalidCredentials
+	}
+	signed := []byte(parts[0] + "." + parts[1])
+
+	switch header.Alg {
+	case "HS256":
+		if a.hmacSecret == nil {
+			return principal{}, errInvalidCredentials
+		}
+		mac := hmac.New(sha256.New, a.hmacSecret)
+		mac.Write(signed)
+		if !hmac.Equal(signature, mac.Sum(nil)) {
+			return principal{}, errInvalidCredentials
+		}
+	case "RS256":
+		if a.rsaKey == nil {
+			return principal{}, errInvalidCredentials
+		}
+		digest := sha256.Sum256(signed)
+		if err := rsa.VerifyPKCS1v15(a.rsaKey, crypto.SHA256, digest[:], signature); err != nil {
+			return principal{}, errInvalidCredentials
+		}
+	default:
+		return principal{}, errInvalidCredentials
+	}
+
+	var claims jwtClaims
+	if err := decodeJWTSegment(parts[1], &claims); err != nil {
+		return principal{}, errInvalidCredentials
+	}
+
+	now := a.now()
+	if claims.ExpiresAt == nil || now.After(time.Unix(int64(*claims.ExpiresAt), 0).Add(jwtLeeway)) {
+		return principal{}, errInvalidCredentials
+	}
+	if claims.NotBefore != nil && now.Add(jwtLeeway).Before(time.Unix(int64(*claims.NotBefore), 0)) {
+		return principal{}, errInvalidCredentials
+	}
+	if a.issuer != "" && claims.Issuer != a.issuer {
+		return principal{}, errInvalidCredentials
+	}
+	if a.audience != "" && !audienceContains(claims.Audience, a.audience) {
+		return principal{}, errInvalidCredentials
+	}
+
+	roles := claims.Roles
+	if claims.Role != "" {
+		roles = append(roles, claims.Role)
+	}
+	return principal{Subject: claims.Subject, Roles: roles}, nil
+}
+
+func decodeJWTSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// audienceContains handles both forms of the aud claim: a string or a list of strings
+func audienceContains(raw json.RawMessage, audience string) bool {
+	var single string
+	if err := json.Unmarshal(raw, &single); err == nil {
+		return single == audience
+	}
+	var list []string
+	if err := json.Unmarshal(raw, &list); err != nil {
+		return false
+	}
+	for _, value := range list {
+		if value == audience {
+			return true
+		}
+	}
+	return false
+}
+
+// apiHandler handles an API Gateway request
+type apiHandler func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error)
+
+// requireRole wraps next so that it only runs for callers with role. Missing
+// or invalid credentials are answered with 401, a missing role with 403.
+func requireRole(role string, next apiHandler) apiHandler {
+	return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		lang := requestLanguage(request)
+		headers := languageHeaders(lang)
+
+		if auth == nil {
+			headers["WWW-Authenticate"] = "Bearer"
+			return errorResponse(http.StatusUnauthorized, headers, lang, msgAuthRequired), nil
+		}
+
+		caller, err := auth.Authenticate(request)
+		if err != nil {
+			headers["WWW-Authenticate"] = "Bearer"
+			message := msgInvalidCredentials
+			if errors.Is(err, errNoCredentials) {
+				message = msgAuthRequired
+			}
+			return errorResponse(http.StatusUnauthorized, headers, lang, message), nil
+		}
+
+		if !caller.hasRole(role) {
+			return errorResponse(http.StatusForbidden, headers, lang, msgInsufficientPermissions), nil
+		}
+		return next(ctx, request)
+	}
+}
+
+// headerValue looks up a header case-insensitively, as API Gateway keeps the client's casing
+func headerValue(headers map[string]string, name string) string {
+	for key, value := range headers {
+		if strings.EqualFold(key, name) {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,321 @@
+package main
+
+import (
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+const testHMACSecret = "test-hmac-secret"
+
+var testNow = time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// setTestAuth replaces the authenticator for the duration of a test
+func setTestAuth(t *testing.T, a *authenticator) {
+	t.Helper()
+	original := auth
+	auth = a
+	t.Cleanup(func() { auth = original })
+}
+
+func testAPIKeyHashes(t *testing.T, keys ...string) [][]byte {
+	t.Helper()
+	var hashes [][]byte
+	for _, key := range keys {
+		hash, err := hex.DecodeString(hashAPIKey(key))
+		if err != nil {
+			t.Fatalf("Failed to decode hash: %v", err)
+		}
+		hashes = append(hashes, hash)
+	}
+	return hashes
+}
+
+// signTestJWT signs claims with HS256 (key is a []byte secret) or RS256 (key is an *rsa.PrivateKey)
+func signTestJWT(t *testing.T, alg string, key interface{}, claims map[string]interface{}) string {
+	t.Helper()
+
+	header, _ := json.Marshal(map[string]string{"alg": alg, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	var signature []byte
+	switch k := key.(type) {
+	case []byte:
+		mac := hmac.New(sha256.New, k)
+		mac.Write([]byte(signed))
+		signature = mac.Sum(nil)
+	case *rsa.PrivateKey:
+		digest := sha256.Sum256([]byte(signed))
+		var err error
+		signature, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:])
+		if err != nil {
+			t.Fatalf("Failed to sign token: %v", err)
+		}
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func testClaims(overrides map[string]interface{}) map[string]interface{} {
+	claims := map[string]interface{}{
+		"sub":   "dealer@example.com",
+		"iss":   "https://auth.example.com",
+		"aud":   "search-api",
+		"exp":   testNow.Add(time.Hour).Unix(),
+		"roles": []string{RoleAdmin},
+	}
+	for key, value := range overrides {
+		if value == nil {
+			delete(claims, key)
+			continue
+		}
+		claims[key] = value
+	}
+	return claims
+}
+
+func TestAuthenticatorJWT(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PUBLIC KEY", Bytes: x509.MarshalPKCS1PublicKey(&rsaKey.PublicKey)})
+
+	a := &authenticator{
+		hmacSecret: []byte(testHMACSecret),
+		rsaKey:     &rsaKey.PublicKey,
+		issuer:     "https://auth.example.com",
+		audience:   "search-api",
+		now:        func() time.Time { return testNow },
+	}
+
+	tests := []struct {
+		name      string
+		token     string
+		wantErr   bool
+		wantRoles []string
+	}{
+		{
+			name:      "HS256 with roles",
+			token:     signTestJWT(t, "HS256", []byte(testHMACSecret), testClaims(nil)),
+			wantRoles: []string{RoleAdmin},
+		},
+		{
+			name:      "RS256 with single role and audience list",
+			token:     signTestJWT(t, "RS256", rsaKey, testClaims(map[string]interface{}{"roles": nil, "role": "editor", "aud": []string{"other", "search-api"}})),
+			wantRoles: []string{"editor"},
+		},
+		{
+			name:      "Expired within leeway",
+			token:     signTestJWT(t, "HS256", []byte(testHMACSecret), testClaims(map[string]interface{}{"exp": testNow.Add(-10 * time.Second).Unix()})),
+			wantRoles: []string{RoleAdmin},
+		},
+		{
+			name:    "Expired",
+			token:   signTestJWT(t, "HS256", []byte(testHMACSecret), testClaims(map[string]interface{}{"exp": testNow.Add(-time.Hour).Unix()})),
+			wantErr: true,
+		},
+		{
+			name:    "Missing expiry",
+			token:   signTestJWT(t, "HS256", []byte(testHMACSecret), testClaims(map[string]interface{}{"exp": nil})),
+			wantErr: true,
+		},
+		{
+			name:    "Not yet valid",
+			token:   signTestJWT(t, "HS256", []byte(testHMACSecret), testClaims(map[string]interface{}{"nbf": testNow.Add(time.Hour).Unix()})),
+			wantErr: true,
+		},
+		{
+			name:    "Wrong issuer",
+			token:   signTestJWT(t, "HS256", []byte(testHMACSecret), testClaims(map[string]interface{}{"iss": "https://evil.example.com"})),
+			wantErr: true,
+		},
+		{
+			name:    "Wrong audience",
+			token:   signTestJWT(t, "HS256", []byte(testHMACSecret), testClaims(map[string]interface{}{"aud": "contact-form"})),
+			wantErr: true,
+		},
+		{
+			name:    "Wrong HMAC secret",
+			token:   signTestJWT(t, "HS256", []byte("other-secret"), testClaims(nil)),
+			wantErr: true,
+		},
+		{
+			name:    "RS256 signed by another key",
+			token:   signTestJWT(t, "RS256", otherKey, testClaims(nil)),
+			wantErr: true,
+		},
+		{
+			name:    "HS256 signed with the RSA public key",
+			token:   signTestJWT(t, "HS256", publicPEM, testClaims(nil)),
+			wantErr: true,
+		},
+		{
+			name:    "Unsigned token",
+			token:   unsignedTestJWT(testClaims(nil)),
+			wantErr: true,
+		},
+		{
+			name:    "Tampered claims",
+			token:   tamperTestJWT(signTestJWT(t, "HS256", []byte(testHMACSecret), testClaims(map[string]interface{}{"roles": []string{"viewer"}}))),
+			wantErr: true,
+		},
+		{
+			name:    "Malformed token",
+			token:   "not-a-jwt",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := events.APIGatewayProxyRequest{Headers: map[string]string{"authorization": "Bearer " + tt.token}}
+			caller, err := a.Authenticate(request)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error, got %+v", caller)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !slicesEqual(caller.Roles, tt.wantRoles) || caller.Subject != "dealer@example.com" {
+				t.Errorf("Expected roles %v for dealer@example.com, got %+v", tt.wantRoles, caller)
+			}
+		})
+	}
+}
+
+// unsignedTestJWT builds a token with alg "none" and an empty signature
+func unsignedTestJWT(claims map[string]interface{}) string {
+	header, _ := json.Marshal(map[string]string{"alg": "none", "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	return base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload) + "."
+}
+
+// tamperTestJWT swaps the payload for one granting the admin role while keeping the signature
+func tamperTestJWT(token string) string {
+	parts := strings.Split(token, ".")
+	payload, _ := json.Marshal(map[string]interface{}{
+		"sub":   "dealer@example.com",
+		"exp":   testNow.Add(time.Hour).Unix(),
+		"roles": []string{RoleAdmin},
+	})
+	parts[1] = base64.RawURLEncoding.EncodeToString(payload)
+	return strings.Join(parts, ".")
+}
+
+func TestAuthenticatorFromEnv(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	if err != nil {
+		t.Fatalf("Failed to encode key: %v", err)
+	}
+
+	t.Setenv("AUTH_API_KEY_SHA256", hashAPIKey("first")+", "+hashAPIKey("second"))
+	t.Setenv("AUTH_JWT_HS256_SECRET", testHMACSecret)
+	t.Setenv("AUTH_JWT_RS256_PUBLIC_KEY", string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})))
+
+	a, err := authenticatorFromEnv()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(a.apiKeyHashes) != 2 || a.hmacSecret == nil || a.rsaKey == nil {
+		t.Errorf("Unexpected configuration: %+v", a)
+	}
+
+	caller, err := a.Authenticate(events.APIGatewayProxyRequest{Headers: map[string]string{"X-Api-Key": "second"}})
+	if err != nil || !caller.hasRole(RoleAdmin) {
+		t.Errorf("Expected API key to grant admin role, got %+v, %v", caller, err)
+	}
+
+	t.Setenv("AUTH_API_KEY_SHA256", "plaintext-key")
+	if _, err := authenticatorFromEnv(); err == nil {
+		t.Error("Expected error for unhashed API key")
+	}
+
+	t.Setenv("AUTH_API_KEY_SHA256", "")
+	t.Setenv("AUTH_JWT_RS256_PUBLIC_KEY", "not a key")
+	if _, err := authenticatorFromEnv(); err == nil {
+		t.Error("Expected error for invalid public key")
+	}
+}
+
+func TestRequireRole(t *testing.T) {
+	setTestAuth(t, &authenticator{
+		apiKeyHashes: testAPIKeyHashes(t, "secret-key"),
+		hmacSecret:   []byte(testHMACSecret),
+		now:          func() time.Time { return testNow },
+	})
+
+	next := func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		return events.APIGatewayProxyResponse{StatusCode: http.StatusOK, Body: `{"ok": true}`}, nil
+	}
+	handler := requireRole(RoleAdmin, next)
+
+	viewerToken := signTestJWT(t, "HS256", []byte(testHMACSecret), testClaims(map[string]interface{}{"roles": []string{"viewer"}}))
+	adminToken := signTestJWT(t, "HS256", []byte(testHMACSecret), testClaims(nil))
+
+	tests := []struct {
+		name     string
+		headers  map[string]string
+		expected int
+		error    string
+	}{
+		{name: "No credentials", expected: http.StatusUnauthorized, error: "Authentication required"},
+		{name: "Wrong API key", headers: map[string]string{"X-Api-Key": "wrong"}, expected: http.StatusUnauthorized, error: "Invalid credentials"},
+		{name: "Basic auth", headers: map[string]string{"Authorization": "Basic YWRtaW46YWRtaW4="}, expected: http.StatusUnauthorized, error: "Invalid credentials"},
+		{name: "Missing role", headers: map[string]string{"Authorization": "Bearer " + viewerToken}, expected: http.StatusForbidden, error: "Insufficient permissions"},
+		{name: "Admin token", headers: map[string]string{"Authorization": "Bearer " + adminToken}, expected: http.StatusOK},
+		{name: "API key", headers: map[string]string{"x-api-key": "secret-key"}, expected: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := handler(context.Background(), events.APIGatewayProxyRequest{Headers: tt.headers})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if response.StatusCode != tt.expected {
+				t.Fatalf("Expected status %d, got %d", tt.expected, response.StatusCode)
+			}
+			if tt.error == "" {
+				return
+			}
+
+			var body ErrorResponse
+			if err := json.Unmarshal([]byte(response.Body), &body); err != nil {
+				t.Fatalf("Expected JSON error body, got %q", response.Body)
+			}
+			if body.Error != tt.error {
+				t.Errorf("Expected error %q, got %q", tt.error, body.Error)
+			}
+			if tt.expected == http.StatusUnauthorized && response.Headers["WWW-Authenticate"] != "Bearer" {
+				t.Errorf("Expected WWW-Authenticate header, got %v", response.Headers)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package main
+
+import "strings"
+
+// carBrand is a manufacturer in the brand registry. Aliases are the other
+// spellings found in titles, Models are the model names that span several
+// words or would otherwise be cut, e.g. "Model 3" or "Range Rover Sport".
+type carBrand struct {
+	Name    string
+	Aliases []string
+	Models  []string
+}
+
+// brandRegistry lists the known manufacturers. Titles of other brands fall
+// back to the first word as brand and the second as model.
+var brandRegistry = []carBrand{
+	{Name: "Abarth"},
+	{Name: "Alfa Romeo", Aliases: []string{"Alfa"}},
+	{Name: "Alpine"},
+	{Name: "Aston Martin", Models: []string{"DB11", "DB12", "DBS", "DBX", "Vantage"}},
+	{Name: "Audi", Models: []string{"e-tron GT", "Q4 e-tron", "Q8 e-tron"}},
+	{Name: "Bentley", Models: []string{"Continental GT", "Flying Spur"}},
+	{Name: "BMW"},
+	{Name: "BYD"},
+	{Name: "Cadillac"},
+	{Name: "Chevrolet"},
+	{Name: "Citroën"},
+	{Name: "Cupra"},
+	{Name: "Dacia"},
+	{Name: "DS", Aliases: []string{"DS Automobiles"}},
+	{Name: "Ferrari"},
+	{Name: "Fiat"},
+	{Name: "Ford", Models: []string{"Mustang Mach-E"}},
+	{Name: "Genesis"},
+	{Name: "Honda"},
+	{Name: "Hyundai"},
+	{Name: "Jaguar", Models: []string{"F-Pace", "E-Pace", "I-Pace", "F-Type"}},
+	{Name: "Jeep", Models: []string{"Grand Cherokee"}},
+	{Name: "Kia"},
+	{Name: "Lamborghini"},
+	{Name: "Land Rover", Models: []string{"Range Rover Sport", "Range Rover Evoque", "Range Rover Velar", "Range Rover", "Discovery Sport", "Discovery", "Defender"}},
+	{Name: "Lexus"},
+	{Name: "Lotus"},
+	{Name: "Maserati"},
+	{Name: "Mazda", Models: []string{"MX-5", "CX-30", "CX-5", "CX-60"}},
+	{Name: "McLaren"},
+	{Name: "Mercedes-Benz", Aliases: []string{"Mercedes", "MB"}, Models: []string{"AMG GT"}},
+	{Name: "MG"},
+	{Name: "Mini", Models: []string{"John Cooper Works"}},
+	{Name: "Mitsubishi"},
+	{Name: "Nissan"},
+	{Name: "Opel"},
+	{Name: "Peugeot"},
+	{Name: "Polestar"},
+	{Name: "Porsche"},
+	{Name: "Renault"},
+	{Name: "Rolls-Royce", Aliases: []string{"Rolls"}},
+	{Name: "Seat"},
+	{Name: "Škoda"},
+	{Name: "Smart"},
+	{Name: "SsangYong"},
+	{Name: "Subaru"},
+	{Name: "Suzuki"},
+	{Name: "Tesla", Models: []string{"Model 3", "Model S", "Model X", "Model Y"}},
+	{Name: "Toyota", Models: []string{"C-HR", "Land Cruiser"}},
+	{Name: "Volkswagen", Aliases: []string{"VW"}, Models: []string{"ID. Buzz", "T-Roc", "T-Cross"}},
+	{Name: "Volvo"},
+}
+
+// brandIndex finds a brand by the normalized name or alias
+var brandIndex = func() map[string]*carBrand {
+	index := make(map[string]*carBrand)
+	for i := range brandRegistry {
+		brand := &brandRegistry[i]
+		for _, name := range append([]string{brand.Name}, brand.Aliases...) {
+			index[lookupKey(name)] = brand
+		}
+	}
+	return index
+}()
+
+// maxNameWords is the most words a brand alias or model name spans
+const maxNameWords = 3
+
+// matchPrefix returns the longest prefix of words whose normalized form is
+// accepted by match, and the number of words it spans
+func matchPrefix(words []string, match func(key string) bool) int {
+	for n := min(len(words), maxNameWords); n > 0; n-- {
+		if match(lookupKey(strings.Join(words[:n], " "))) {
+			return n
+		}
+	}
+	return 0
+}
+
+// extractBrandAndModel returns the brand and model of a car title, e.g.
+// "Land Rover" and "Range Rover Sport" for "Land Rover Range Rover Sport P400"
+func extractBrandAndModel(title string) (string, string) {
+	words := strings.Fields(title)
+	if len(words) == 0 {
+		return "", ""
+	}
+
+	var brand *carBrand
+	n := matchPrefix(words, func(key string) bool {
+		brand = brandIndex[key]
+		return brand != nil
+	})
+	if brand == nil {
+		// Unknown brand: first word is the brand, the next the model
+		if len(words) > 1 {
+			return words[0], words[1]
+		}
+		return words[0], ""
+	}
+
+	rest := words[n:]
+	if len(rest) == 0 {
+		return brand.Name, ""
+	}
+	var model string
+	n = matchPrefix(rest, func(key string) bool {
+		for _, name := range brand.Models {
+			if lookupKey(name) == key {
+				model = name
+				return true
+			}
+		}
+		return false
+	})
+	if n == 0 {
+		model = rest[0]
+	}
+	return brand.Name, model
+}
+
+// modelMatches checks if a model matches the model filter, ignoring case and diacritics
+func modelMatches(model, filter string) bool {
+	return filter == "" || lookupKey(model) == lookupKey(filter)
+}
+
+// brandModels returns the sorted models of each brand
+func brandModels(models map[string]map[string]bool) map[string][]string {
+	result := make(map[string][]string, len(models))
+	for brand, names := range models {
+		result[brand] = mapKeysToSlice(names)
+	}
+	return result
+}
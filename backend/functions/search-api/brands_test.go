@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func TestBrandRegistryIsUnambiguous(t *testing.T) {
+	owners := make(map[string]string)
+	for _, brand := range brandRegistry {
+		for _, name := range append([]string{brand.Name}, brand.Aliases...) {
+			key := lookupKey(name)
+			if owner, ok := owners[key]; ok && owner != brand.Name {
+				t.Errorf("%q maps to both %s and %s", name, owner, brand.Name)
+			}
+			owners[key] = brand.Name
+		}
+	}
+}
+
+func TestExtractBrandAndModel(t *testing.T) {
+	tests := []struct {
+		title string
+		brand string
+		model string
+	}{
+		{"BMW 520d xDrive 48V M Sport Steptronic", "BMW", "520d"},
+		{"Mercedes-Benz C 200 AMG Line", "Mercedes-Benz", "C"},
+		{"Mercedes GLC 300", "Mercedes-Benz", "GLC"},
+		{"Land Rover Defender 110 D300", "Land Rover", "Defender"},
+		{"Land Rover Range Rover Sport P400e", "Land Rover", "Range Rover Sport"},
+		{"Alfa Romeo Giulia Quadrifoglio", "Alfa Romeo", "Giulia"},
+		{"Aston Martin Vantage V8", "Aston Martin", "Vantage"},
+		{"Tesla Model 3 Long Range AWD", "Tesla", "Model 3"},
+		{"VW T-Roc R-Line", "Volkswagen", "T-Roc"},
+		{"Skoda Octavia Combi", "Škoda", "Octavia"},
+		{"Škoda Octavia Combi Style 2.0 TDI", "Škoda", "Octavia"},
+		{"Rolls Royce Ghost", "Rolls-Royce", "Ghost"},
+		{"Lada Niva 4x4", "Lada", "Niva"},
+		{"Tesla", "Tesla", ""},
+		{"", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			brand, model := extractBrandAndModel(tt.title)
+			if brand != tt.brand || model != tt.model {
+				t.Errorf("Expected %q/%q, got %q/%q", tt.brand, tt.model, brand, model)
+			}
+		})
+	}
+}
+
+func TestHandleSearchModel(t *testing.T) {
+	setTestCars(t, []Car{
+		{ID: 1, Title: "Land Rover Defender 110", Brand: "Land Rover", Model: "Defender", Status: StatusAvailable},
+		{ID: 2, Title: "Land Rover Range Rover Sport", Brand: "Land Rover", Model: "Range Rover Sport", Status: StatusAvailable},
+		{ID: 3, Title: "Land Rover Range Rover Evoque", Brand: "Land Rover", Model: "Range Rover Evoque", Status: StatusSold},
+		{ID: 4, Title: "Škoda Octavia Combi", Brand: "Škoda", Model: "Octavia", Status: StatusAvailable},
+	})
+
+	search := func(query map[string]string) []int {
+		response, err := handleRequest(context.Background(), events.APIGatewayProxyRequest{
+			HTTPMethod:            "GET",
+			Resource:              "/search",
+			QueryStringParameters: query,
+		})
+		if err != nil || response.StatusCode != 200 {
+			t.Fatalf("Unexpected response %d (%v): %s", response.StatusCode, err, response.Body)
+		}
+		var result SearchResponse
+		if err := json.Unmarshal([]byte(response.Body), &result); err != nil {
+			t.Fatalf("Invalid response body: %v", err)
+		}
+		var ids []int
+		for _, car := range result.Cars {
+			ids = append(ids, car.ID)
+		}
+		return ids
+	}
+
+	tests := []struct {
+		query    map[string]string
+		expected []int
+	}{
+		{map[string]string{"model": "range rover sport"}, []int{2}},
+		{map[string]string{"brand": "Land Rover", "model": "Defender"}, []int{1}},
+		{map[string]string{"model": "octavia"}, []int{4}},
+		{map[string]string{"model": "Range Rover"}, nil},
+	}
+	for _, tt := range tests {
+		if ids := search(tt.query); !reflect.DeepEqual(ids, tt.expected) {
+			t.Errorf("%v: expected %v, got %v", tt.query, tt.expected, ids)
+		}
+	}
+
+	// Sold cars do not add models
+	expected := map[string][]string{"Land Rover": {"Defender", "Range Rover Sport"}, "Škoda": {"Octavia"}}
+	if models := getSearchOptions().Models; !reflect.DeepEqual(models, expected) {
+		t.Errorf("Expected models %v, got %v", expected, models)
+	}
+}
@@ -0,0 +1,388 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// Errors returned by CarStore implementations
+var (
+	ErrCarNotFound = errors.New("car not found")
+	ErrCarExists   = errors.New("car already exists")
+)
+
+// CarStore persists the cars managed through the admin API. Cars are stored
+// as submitted; sanitization happens when the catalogue is loaded from the store.
+type CarStore interface {
+	// List returns all cars ordered by ID
+	List(ctx context.Context) ([]Car, error)
+	// Get returns the car with the given ID or ErrCarNotFound
+	Get(ctx context.Context, id int) (Car, error)
+	// Create stores a new car or returns ErrCarExists
+	Create(ctx context.Context, car Car) error
+	// Update replaces an existing car or returns ErrCarNotFound
+	Update(ctx context.Context, car Car) error
+	// Delete removes a car or returns ErrCarNotFound
+	Delete(ctx context.Context, id int) error
+	// Seeded reports whether MarkSeeded was called
+	Seeded(ctx context.Context) (bool, error)
+	// MarkSeeded records that the store holds the catalogue, so it is served
+	// even when empty
+	MarkSeeded(ctx context.Context) error
+}
+
+// carStoreSeededID is the ID of the item that marks a seeded dynamoCarStore.
+// Car IDs start at 1.
+const carStoreSeededID = 0
+
+// memoryCarStore keeps cars in memory. It is used in tests and for local development.
+type memoryCarStore struct {
+	mu     sync.RWMutex
+	cars   map[int]Car
+	seeded bool
+}
+
+func newMemoryCarStore(initial ...Car) *memoryCarStore {
+	s := &memoryCarStore{cars: make(map[int]Car, len(initial))}
+	for _, car := range initial {
+		s.cars[car.ID] = car
+	}
+	return s
+}
+
+func (s *memoryCarStore) List(ctx context.Context) ([]Car, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	list := make([]Car, 0, len(s.cars))
+	for _, car := range s.cars {
+		list = append(list, car)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list, nil
+}
+
+func (s *memoryCarStore) Get(ctx context.Context, id int) (Car, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	car, ok := s.cars[id]
+	if !ok {
+		return Car{}, ErrCarNotFound
+	}
+	return car, nil
+}
+
+func (s *memoryCarStore) Create(ctx context.Context, car Car) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.cars[car.ID]; ok {
+		return ErrCarExists
+	}
+	s.cars[car.ID] = car
+	return nil
+}
+
+func (s *memoryCarStore) Update(ctx context.Context, car Car) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.cars[car.ID]; !ok {
+		return ErrCarNotFound
+	}
+	s.cars[car.ID] = car
+	return nil
+}
+
+func (s *memoryCarStore) Delete(ctx context.Context, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.cars[id]; !ok {
+		return ErrCarNotFound
+	}
+	delete(s.cars, id)
+	return nil
+}
+
+func (s *memoryCarStore) Seeded(ctx context.Context) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.seeded, nil
+}
+
+func (s *memoryCarStore) MarkSeeded(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.seeded = true
+	return nil
+}
+
+// dynamoCarStore stores cars in a DynamoDB table with the numeric partition key
+// "id". The item with carStoreSeededID marks the table as seeded.
+type dynamoCarStore struct {
+	client dynamodbiface.DynamoDBAPI
+	table  string
+}
+
+func newDynamoCarStore(table string) *dynamoCarStore {
+	sess := session.Must(session.NewSession())
+	return &dynamoCarStore{client: dynamodb.New(sess), table: table}
+}
+
+func (s *dynamoCarStore) key(id int) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		"id": {N: aws.String(strconv.Itoa(id))},
+	}
+}
+
+func (s *dynamoCarStore) List(ctx context.Context) ([]Car, error) {
+	var list []Car
+	var unmarshalErr error
+
+	err := s.client.ScanPagesWithContext(ctx, &dynamodb.ScanInput{
+		TableName: aws.String(s.table),
+	}, func(page *dynamodb.ScanOutput, lastPage bool) bool {
+		var cars []Car
+		if err := dynamodbattribute.UnmarshalListOfMaps(page.Items, &cars); err != nil {
+			unmarshalErr = err
+			return false
+		}
+		for _, car := range cars {
+			if car.ID != carStoreSeededID {
+				list = append(list, car)
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error scanning %s: %w", s.table, err)
+	}
+	if unmarshalErr != nil {
+		return nil, fmt.Errorf("error decoding cars: %w", unmarshalErr)
+	}
+
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list, nil
+}
+
+func (s *dynamoCarStore) Get(ctx context.Context, id int) (Car, error) {
+	if id == carStoreSeededID {
+		return Car{}, ErrCarNotFound
+	}
+	output, err := s.client.GetItemWithContext(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(s.table),
+		Key:            s.key(id),
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return Car{}, fmt.Errorf("error reading car %d: %w", id, err)
+	}
+	if len(output.Item) == 0 {
+		return Car{}, ErrCarNotFound
+	}
+
+	var car Car
+	if err := dynamodbattribute.UnmarshalMap(output.Item, &car); err != nil {
+		return Car{}, fmt.Errorf("error decoding car %d: %w", id, err)
+	}
+	return car, nil
+}
+
+func (s *dynamoCarStore) Create(ctx context.Context, car Car) error {
+	return s.put(ctx, car, "attribute_not_exists(id)", ErrCarExists)
+}
+
+func (s *dynamoCarStore) Update(ctx context.Context, car Car) error {
+	return s.put(ctx, car, "attribute_exists(id)", ErrCarNotFound)
+}
+
+// put writes a car if condition holds, returning conditionErr otherwise
+func (s *dynamoCarStore) put(ctx context.Context, car Car, condition string, conditionErr error) error {
+	item, err := dynamodbattribute.MarshalMap(car)
+	if err != nil {
+		return fmt.Errorf("error encoding car %d: %w", car.ID, err)
+	}
+
+	_, err = s.client.PutItemWithContext(ctx, &dynamodb.PutItemInput{
+		TableName:           aws.String(s.table),
+		Item:                item,
+		ConditionExpression: aws.String(condition),
+	})
+	if isConditionalCheckFailed(err) {
+		return conditionErr
+	}
+	if err != nil {
+		return fmt.Errorf("error writing car %d: %w", car.ID, err)
+	}
+	return nil
+}
+
+func (s *dynamoCarStore) Delete(ctx context.Context, id int) error {
+	_, err := s.client.DeleteItemWithContext(ctx, &dynamodb.DeleteItemInput{
+		TableName:           aws.String(s.table),
+		Key:                 s.key(id),
+		ConditionExpression: aws.String("attribute_exists(id)"),
+	})
+	if isConditionalCheckFailed(err) {
+		return ErrCarNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("error deleting car %d: %w", id, err)
+	}
+	return nil
+}
+
+func (s *dynamoCarStore) Seeded(ctx context.Context) (bool, error) {
+	output, err := s.client.GetItemWithContext(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(s.table),
+		Key:            s.key(carStoreSeededID),
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return false, fmt.Errorf("error reading seed marker: %w", err)
+	}
+	return len(output.Item) > 0, nil
+}
+
+func (s *dynamoCarStore) MarkSeeded(ctx context.Context) error {
+	item := s.key(carStoreSeededID)
+	item["seeded"] = &dynamodb.AttributeValue{BOOL: aws.Bool(true)}
+
+	_, err := s.client.PutItemWithContext(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(s.table),
+		Item:      item,
+	})
+	if err != nil {
+		return fmt.Errorf("error writing seed marker: %w", err)
+	}
+	return nil
+}
+
+func isConditionalCheckFailed(err error) bool {
+	var awsErr awserr.Error
+	return errors.As(err, &awsErr) && awsErr.Code() == dynamodb.ErrCodeConditionalCheckFailedException
+}
+
+// storeCatalogueName is the file name of catalogues served from a CarStore
+const storeCatalogueName = "cars.json"
+
+// storeCatalogueSource serves the cars of a CarStore as a JSON catalogue, so
+// that they pass through the same validation as every other catalogue format.
+// Until the store is seeded the fallback source is served instead; afterwards
+// an empty store publishes an empty catalogue.
+type storeCatalogueSource struct {
+	Store    CarStore
+	Fallback catalogueSource
+}
+
+func (s storeCatalogueSource) Fetch(ctx context.Context) (string, string, []byte, error) {
+	list, err := s.Store.List(ctx)
+	if err != nil {
+		return "", "", nil, err
+	}
+	if len(list) == 0 && s.Fallback != nil {
+		seeded, err := s.Store.Seeded(ctx)
+		if err != nil {
+			return "", "", nil, err
+		}
+		if !seeded {
+			return s.Fallback.Fetch(ctx)
+		}
+	}
+
+	data, err := json.Marshal(list)
+	if err != nil {
+		return "", "", nil, err
+	}
+	return storeCatalogueName, "application/json", data, nil
+}
+
+// seedCarStore copies the fallback catalogue into the store before the first
+// admin write and marks it seeded. The store then holds the complete catalogue,
+// so cars from the fallback stay published and can be edited under their IDs.
+// Deleting every car later leaves the store empty instead of seeding it again.
+func seedCarStore(ctx context.Context, source storeCatalogueSource) error {
+	if source.Fallback == nil {
+		return nil
+	}
+
+	seeded, err := source.Store.Seeded(ctx)
+	if err != nil || seeded {
+		return err
+	}
+	// Stores filled before the marker existed already hold the catalogue
+	list, err := source.Store.List(ctx)
+	if err != nil {
+		return err
+	}
+	if len(list) > 0 {
+		return source.Store.MarkSeeded(ctx)
+	}
+
+	name, contentType, data, err := source.Fallback.Fetch(ctx)
+	if err != nil {
+		return fmt.Errorf("error fetching catalogue: %w", err)
+	}
+	cars, err := parseCatalogue(name, contentType, data)
+	if err != nil {
+		return err
+	}
+
+	for _, car := range cars {
+		// Another instance may be seeding at the same time
+		if err := source.Store.Create(ctx, storedCar(car)); err != nil && !errors.Is(err, ErrCarExists) {
+			return err
+		}
+	}
+	return source.Store.MarkSeeded(ctx)
+}
+
+// storedCar returns the columns of a loaded car as the admin API stores them.
+// Loading escapes the texts, the store keeps them as submitted.
+func storedCar(car Car) Car {
+	equipment := make([]string, len(car.Equipment))
+	for i, item := range car.Equipment {
+		equipment[i] = html.UnescapeString(item)
+	}
+
+	return Car{
+		ID:           car.ID,
+		Title:        html.UnescapeString(car.Title),
+		PriceCHF:     car.PriceCHF,
+		LeasingText:  html.UnescapeString(car.LeasingText),
+		FirstReg:     html.UnescapeString(car.FirstReg),
+		CarType:      html.UnescapeString(car.CarType),
+		MileageKM:    car.MileageKM,
+		Transmission: html.UnescapeString(car.Transmission),
+		Fuel:         html.UnescapeString(car.Fuel),
+		Drive:        html.UnescapeString(car.Drive),
+		PowerHP:      car.PowerHP,
+		PowerKW:      car.PowerKW,
+		MFK:          car.MFK,
+		Warranty:     car.Warranty,
+		WarrantyText: html.UnescapeString(car.WarrantyText),
+		Equipment:    equipment,
+		Description:  html.UnescapeString(car.Description),
+		ImageURLs:    car.ImageURLs,
+		Status:       car.Status,
+		ReservedAt:   car.ReservedAt,
+		SoldAt:       car.SoldAt,
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestMemoryCarStore(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryCarStore(testStoredCar(2, "Audi A4"), testStoredCar(1, "BMW 520d"))
+
+	list, _ := store.List(ctx)
+	if len(list) != 2 || list[0].ID != 1 || list[1].ID != 2 {
+		t.Errorf("Expected cars ordered by ID, got %+v", list)
+	}
+
+	if err := store.Create(ctx, testStoredCar(1, "BMW 320d")); !errors.Is(err, ErrCarExists) {
+		t.Errorf("Expected ErrCarExists, got %v", err)
+	}
+	if err := store.Update(ctx, testStoredCar(3, "Skoda Octavia")); !errors.Is(err, ErrCarNotFound) {
+		t.Errorf("Expected ErrCarNotFound, got %v", err)
+	}
+	if err := store.Delete(ctx, 3); !errors.Is(err, ErrCarNotFound) {
+		t.Errorf("Expected ErrCarNotFound, got %v", err)
+	}
+	if _, err := store.Get(ctx, 3); !errors.Is(err, ErrCarNotFound) {
+		t.Errorf("Expected ErrCarNotFound, got %v", err)
+	}
+}
+
+func TestStoreCatalogueSource(t *testing.T) {
+	store := newMemoryCarStore()
+	holder := newCatalogueHolder(storeCatalogueSource{Store: store, Fallback: &fakeCatalogueSource{data: testCatalogueCSV}}, 0)
+
+	// An empty store serves the fallback catalogue
+	if _, err := holder.Reload(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(holder.Snapshot().Cars) != 2 {
+		t.Errorf("Expected 2 cars from the fallback, got %d", len(holder.Snapshot().Cars))
+	}
+
+	// Stored cars are sanitized like any other catalogue
+	store.Create(context.Background(), testStoredCar(7, "<b>BMW</b> 520d"))
+	if _, err := holder.Reload(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	cars := holder.Snapshot().Cars
+	if len(cars) != 1 || cars[0].ID != 7 || cars[0].Title != "&lt;b&gt;BMW&lt;/b&gt; 520d" {
+		t.Errorf("Unexpected catalogue from store: %+v", cars)
+	}
+	// Once seeded, an empty store publishes an empty catalogue
+	store.Delete(context.Background(), 7)
+	store.MarkSeeded(context.Background())
+	if _, err := holder.Reload(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cars := holder.Snapshot().Cars; len(cars) != 0 {
+		t.Errorf("Expected an empty catalogue after seeding, got %d cars", len(cars))
+	}
+}
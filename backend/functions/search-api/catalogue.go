@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"mime"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Supported catalogue formats
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+	FormatXLSX = "xlsx"
+	FormatXML  = "xml"
+)
+
+// Content types mapped to catalogue formats
+var catalogueContentTypes = map[string]string{
+	"text/csv":                    FormatCSV,
+	"application/csv":             FormatCSV,
+	"text/comma-separated-values": FormatCSV,
+	"application/json":            FormatJSON,
+	"text/json":                   FormatJSON,
+	"application/xml":             FormatXML,
+	"text/xml":                    FormatXML,
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet": FormatXLSX,
+}
+
+// detectCatalogueFormat determines the format of a catalogue file from its
+// content type, falling back to the file extension when the content type is
+// missing or generic (e.g. application/octet-stream from S3).
+func detectCatalogueFormat(name, contentType string) (string, error) {
+	if contentType != "" {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err == nil {
+			if format, ok := catalogueContentTypes[mediaType]; ok {
+				return format, nil
+			}
+		}
+	}
+
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".csv":
+		return FormatCSV, nil
+	case ".json":
+		return FormatJSON, nil
+	case ".xlsx":
+		return FormatXLSX, nil
+	case ".xml":
+		return FormatXML, nil
+	}
+
+	return "", fmt.Errorf("unknown catalogue format for %q (content type %q)", name, contentType)
+}
+
+// parseCatalogue parses a catalogue file in any supported format. All formats
+// go through parseCarRecord, so validation and sanitization are identical.
+// XML files and JSON objects are treated as marketplace listing exports, JSON
+// arrays as lists of Car objects.
+func parseCatalogue(name, contentType string, data []byte) ([]Car, error) {
+	format, err := detectCatalogueFormat(name, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case FormatXML:
+		return parseMarketplaceExport(data)
+	case FormatJSON:
+		if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+			return parseMarketplaceExport(data)
+		}
+		return parseCarsJSON(data)
+	case FormatXLSX:
+		records, err := readXLSXRecords(data)
+		if err != nil {
+			return nil, fmt.Errorf("error reading XLSX: %w", err)
+		}
+		return parseCarRecords(records)
+	default:
+		return parseCarsCSV(bytes.NewReader(data))
+	}
+}
+
+// parseCarsJSON reads a JSON array of Car objects. Each car is converted into
+// a record in the canonical column order and parsed like a CSV row; invalid
+// entries are logged and skipped.
+func parseCarsJSON(data []byte) ([]Car, error) {
+	var entries []Car
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("error reading JSON: %w", err)
+	}
+
+	loaded := make([]Car, 0, len(entries))
+	for i, entry := range entries {
+		car, err := parseCarRecord(defaultCSVHeader, carToRecord(entry))
+		if err != nil {
+			log.Printf("Error parsing entry %d: %v", i, err)
+			continue
+		}
+		loaded = append(loaded, car)
+	}
+
+	return loaded, nil
+}
+
+// carToRecord converts a car into a record in the canonical CSV column order
+func carToRecord(car Car) []string {
+	return []string{
+		strconv.Itoa(car.ID),
+		car.Title,
+		strconv.Itoa(car.PriceCHF),
+		car.LeasingText,
+		car.FirstReg,
+		car.CarType,
+		strconv.Itoa(car.MileageKM),
+		car.Transmission,
+		car.Fuel,
+		car.Drive,
+		strconv.Itoa(car.PowerHP),
+		strconv.Itoa(car.PowerKW),
+		strconv.FormatBool(car.MFK),
+		strconv.FormatBool(car.Warranty),
+		car.WarrantyText,
+		strings.Join(car.Equipment, ";"),
+		car.Description,
+		strings.Join(car.ImageURLs, ";"),
+		car.Status,
+		formatStatusTime(car.ReservedAt),
+		formatStatusTime(car.SoldAt),
+	}
+}
@@ -0,0 +1,280 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Maximum size of a catalogue downloaded from CATALOGUE_URL
+const maxCatalogueSize = 50 << 20
+
+// catalogueSnapshot is an immutable view of the loaded cars. A snapshot is
+// never modified after it has been published, so request handlers can keep
+// using it while a newer one is swapped in.
+type catalogueSnapshot struct {
+	Cars     []Car
+	Version  string
+	LoadedAt time.Time
+
+	// Derived from Cars when the snapshot is created
+	Options     SearchOptions
+	suggestions *suggestionIndex
+	responses   *responseCache
+}
+
+// newCatalogueSnapshot creates a snapshot and precomputes its search options
+// and suggestions
+func newCatalogueSnapshot(cars []Car, version string) *catalogueSnapshot {
+	return &catalogueSnapshot{
+		Cars:        cars,
+		Version:     version,
+		LoadedAt:    time.Now(),
+		Options:     buildSearchOptions(cars),
+		suggestions: buildSuggestionIndex(cars),
+		responses:   newResponseCache(MaxCachedResponses),
+	}
+}
+
+// catalogueSource provides the raw catalogue data
+type catalogueSource interface {
+	// Fetch returns the catalogue file name, its content type (may be empty)
+	// and its contents
+	Fetch(ctx context.Context) (name, contentType string, data []byte, err error)
+}
+
+// catalogueHolder holds the current catalogue snapshot and replaces it when
+// the source changes. Readers never block and never observe a partially
+// built catalogue.
+type catalogueHolder struct {
+	current   atomic.Pointer[catalogueSnapshot]
+	reloadMu  sync.Mutex
+	source    catalogueSource
+	ttl       time.Duration
+	lastCheck atomic.Int64 // Unix nanoseconds of the last reload attempt
+}
+
+// catalogue is the catalogue served by the API
+var catalogue = newCatalogueHolder(embeddedCatalogueSource{}, 0)
+
+// newCatalogueHolder creates a holder that refreshes from source once ttl has
+// passed; a ttl of zero disables time-based refreshes
+func newCatalogueHolder(source catalogueSource, ttl time.Duration) *catalogueHolder {
+	h := &catalogueHolder{source: source, ttl: ttl}
+	h.current.Store(newCatalogueSnapshot([]Car{}, ""))
+	return h
+}
+
+// Snapshot returns the current catalogue
+func (h *catalogueHolder) Snapshot() *catalogueSnapshot {
+	return h.current.Load()
+}
+
+// Store publishes a new set of cars. Storing a version identical to the
+// current one is a no-op; the return value reports whether the catalogue changed.
+func (h *catalogueHolder) Store(cars []Car, version string) bool {
+	if h.current.Load().Version == version {
+		return false
+	}
+	h.current.Store(newCatalogueSnapshot(cars, version))
+	return true
+}
+
+// Reload fetches the catalogue from the source and publishes it if it differs
+// from the current one. The current catalogue is kept if loading fails.
+func (h *catalogueHolder) Reload(ctx context.Context) (bool, error) {
+	h.reloadMu.Lock()
+	defer h.reloadMu.Unlock()
+	return h.reload(ctx)
+}
+
+// RefreshIfStale reloads the catalogue when the TTL has expired. Only one
+// request performs the reload; concurrent requests keep serving the current
+// catalogue instead of waiting.
+func (h *catalogueHolder) RefreshIfStale(ctx context.Context) {
+	if h.ttl <= 0 || time.Since(time.Unix(0, h.lastCheck.Load())) < h.ttl {
+		return
+	}
+	if !h.reloadMu.TryLock() {
+		return
+	}
+	defer h.reloadMu.Unlock()
+
+	if _, err := h.reload(ctx); err != nil {
+		log.Printf("Error refreshing catalogue, keeping current version: %v", err)
+	}
+}
+
+// reload must be called with reloadMu held
+func (h *catalogueHolder) reload(ctx context.Context) (bool, error) {
+	h.lastCheck.Store(time.Now().UnixNano())
+
+	name, contentType, data, err := h.source.Fetch(ctx)
+	if err != nil {
+		return false, fmt.Errorf("error fetching catalogue: %w", err)
+	}
+
+	// Identical data is not parsed again
+	version := catalogueVersion(data)
+	if h.current.Load().Version == version {
+		return false, nil
+	}
+
+	loaded, err := parseCatalogue(name, contentType, data)
+	if err != nil {
+		return false, err
+	}
+	// A seeded car store is empty once every car was deleted; other sources
+	// without cars are broken files
+	_, fromStore := h.source.(storeCatalogueSource)
+	if len(loaded) == 0 && !(fromStore && name == storeCatalogueName) {
+		return false, fmt.Errorf("catalogue %s contains no valid cars", name)
+	}
+
+	changed := h.Store(loaded, version)
+	if changed {
+		log.Printf("Loaded %d cars from %s (version %s)", len(loaded), name, version)
+	}
+	return changed, nil
+}
+
+// catalogueVersion identifies catalogue contents
+func catalogueVersion(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+// currentCars returns the cars of the current catalogue
+func currentCars() []Car {
+	return catalogue.Snapshot().Cars
+}
+
+// embeddedCatalogueSource serves the autos.csv compiled into the binary
+type embeddedCatalogueSource struct{}
+
+func (embeddedCatalogueSource) Fetch(ctx context.Context) (string, string, []byte, error) {
+	return "autos.csv", "text/csv", []byte(csvContent), nil
+}
+
+// fileCatalogueSource reads the catalogue from a file shipped with the function
+type fileCatalogueSource struct {
+	Path string
+}
+
+func (s fileCatalogueSource) Fetch(ctx context.Context) (string, string, []byte, error) {
+	data, err := os.ReadFile(s.Path)
+	return s.Path, "", data, err
+}
+
+// urlCatalogueSource downloads the catalogue over HTTP(S), e.g. from the public data bucket
+type urlCatalogueSource struct {
+	URL     string
+	Client  *http.Client
+	MaxSize int64 // Defaults to maxCatalogueSize
+}
+
+func (s urlCatalogueSource) Fetch(ctx context.Context) (string, string, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL, nil)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	client := s.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", "", nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", "", nil, fmt.Errorf("unexpected status %d from %s", resp.StatusCode, s.URL)
+	}
+
+	maxSize := s.MaxSize
+	if maxSize <= 0 {
+		maxSize = maxCatalogueSize
+	}
+	// Read one byte more than allowed, so a cut off catalogue is never published
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxSize+1))
+	if err != nil {
+		return "", "", nil, err
+	}
+	if int64(len(data)) > maxSize {
+		return "", "", nil, fmt.Errorf("catalogue from %s exceeds %d bytes", s.URL, maxSize)
+	}
+	return req.URL.Path, resp.Header.Get("Content-Type"), data, nil
+}
+
+// loadCatalogue configures the catalogue from the environment and performs the initial load
+func loadCatalogue(ctx context.Context) error {
+	source, ttl, err := catalogueSourceFromEnv()
+	if err != nil {
+		return err
+	}
+
+	catalogue = newCatalogueHolder(source, ttl)
+	_, err = catalogue.Reload(ctx)
+	return err
+}
+
+// catalogueSourceFromEnv selects the catalogue source and refresh interval:
+// CATALOGUE_URL or CATALOGUE_FILE, falling back to the embedded autos.csv,
+// and CATALOGUE_TTL (e.g. "5m") for time-based refreshes. When the admin API
+// is backed by a car store, the store is the source and the configured
+// source is only used until the store is seeded.
+func catalogueSourceFromEnv() (catalogueSource, time.Duration, error) {
+	var ttl time.Duration
+	if value := os.Getenv("CATALOGUE_TTL"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, 0, fmt.Errorf("invalid CATALOGUE_TTL: %w", err)
+		}
+		ttl = parsed
+	}
+
+	var source catalogueSource = embeddedCatalogueSource{}
+	if url := os.Getenv("CATALOGUE_URL"); url != "" {
+		source = urlCatalogueSource{URL: url}
+	} else if path := os.Getenv("CATALOGUE_FILE"); path != "" {
+		source = fileCatalogueSource{Path: path}
+	}
+
+	if carStore != nil {
+		source = storeCatalogueSource{Store: carStore, Fallback: source}
+	}
+	return source, ttl, nil
+}
+
+// catalogueReloadEvent is the subset of S3 notifications and EventBridge
+// events used to recognise reload triggers
+type catalogueReloadEvent struct {
+	Records []struct {
+		EventSource string `json:"eventSource"`
+	} `json:"Records"`
+	Source string `json:"source"`
+}
+
+// isCatalogueReloadEvent reports whether a raw Lambda event asks for a catalogue reload
+func isCatalogueReloadEvent(raw json.RawMessage) bool {
+	var event catalogueReloadEvent
+	if err := json.Unmarshal(raw, &event); err != nil {
+		return false
+	}
+	if len(event.Records) > 0 && event.Records[0].EventSource == "aws:s3" {
+		return true
+	}
+	return event.Source == "aws.events"
+}
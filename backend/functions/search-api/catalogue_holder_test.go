@@ -0,0 +1,262 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+const testCatalogueCSV = "id,title,price_chf,first_registration,car_type,mileage_km,transmission,fuel,drive,power_hp,power_kw\n" +
+	"1,BMW 520d,42890,08.2021,Limousine,55000,Automatik,Diesel,Allrad,190,140\n" +
+	"2,Audi A4,38900,07.2020,Kombi,62000,Automatik,Diesel,Front,204,150\n"
+
+// fakeCatalogueSource serves in-memory data and counts fetches
+type fakeCatalogueSource struct {
+	mu      sync.Mutex
+	data    string
+	err     error
+	fetches atomic.Int32
+}
+
+func (s *fakeCatalogueSource) set(data string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data, s.err = data, err
+}
+
+func (s *fakeCatalogueSource) Fetch(ctx context.Context) (string, string, []byte, error) {
+	s.fetches.Add(1)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return "autos.csv", "", []byte(s.data), s.err
+}
+
+func TestCatalogueHolderReload(t *testing.T) {
+	source := &fakeCatalogueSource{data: testCatalogueCSV}
+	holder := newCatalogueHolder(source, 0)
+
+	changed, err := holder.Reload(context.Background())
+	if err != nil || !changed {
+		t.Fatalf("Expected initial load to change the catalogue, got %v, %v", changed, err)
+	}
+	first := holder.Snapshot()
+	if len(first.Cars) != 2 || first.Version == "" {
+		t.Fatalf("Unexpected snapshot: %+v", first)
+	}
+
+	// Loading the same data again is a no-op
+	changed, err = holder.Reload(context.Background())
+	if err != nil || changed {
+		t.Errorf("Expected reload of identical data to be a no-op, got %v, %v", changed, err)
+	}
+	if holder.Snapshot() != first {
+		t.Error("Expected snapshot to be kept for identical data")
+	}
+
+	// New data replaces the snapshot, the old one stays intact for running requests
+	source.set(testCatalogueCSV+"3,Skoda Octavia,29900,01.2022,Kombi,30000,Manuell,Benzin,Front,150,110\n", nil)
+	changed, err = holder.Reload(context.Background())
+	if err != nil || !changed {
+		t.Fatalf("Expected new data to change the catalogue, got %v, %v", changed, err)
+	}
+	if len(holder.Snapshot().Cars) != 3 || len(first.Cars) != 2 {
+		t.Errorf("Expected new snapshot with 3 cars and old one with 2, got %d and %d", len(holder.Snapshot().Cars), len(first.Cars))
+	}
+
+	// Failures keep the current catalogue
+	current := holder.Snapshot()
+	for _, tt := range []struct {
+		data string
+		err  error
+	}{
+		{err: errors.New("bucket unavailable")},
+		{data: "id,title\n1,BMW\n"},
+		{data: "id,title,price_chf,first_registration,car_type,mileage_km,transmission,fuel,drive,power_hp,power_kw\n"},
+	} {
+		source.set(tt.data, tt.err)
+		if _, err := holder.Reload(context.Background()); err == nil {
+			t.Errorf("Expected error for data %q / %v", tt.data, tt.err)
+		}
+		if holder.Snapshot() != current {
+			t.Error("Expected current catalogue to be kept after a failed reload")
+		}
+	}
+}
+
+func TestCatalogueHolderRefreshIfStale(t *testing.T) {
+	source := &fakeCatalogueSource{data: testCatalogueCSV}
+	holder := newCatalogueHolder(source, time.Hour)
+
+	holder.RefreshIfStale(context.Background())
+	if source.fetches.Load() != 1 || len(holder.Snapshot().Cars) != 2 {
+		t.Fatalf("Expected first refresh to load the catalogue, got %d fetches", source.fetches.Load())
+	}
+
+	// Within the TTL the source is not contacted
+	holder.RefreshIfStale(context.Background())
+	if source.fetches.Load() != 1 {
+		t.Errorf("Expected no fetch within TTL, got %d fetches", source.fetches.Load())
+	}
+
+	// Once the TTL has expired the source is checked again
+	holder.lastCheck.Store(time.Now().Add(-2 * time.Hour).UnixNano())
+	holder.RefreshIfStale(context.Background())
+	if source.fetches.Load() != 2 {
+		t.Errorf("Expected fetch after TTL, got %d fetches", source.fetches.Load())
+	}
+
+	// A TTL of zero disables refreshes
+	disabled := newCatalogueHolder(source, 0)
+	disabled.RefreshIfStale(context.Background())
+	if source.fetches.Load() != 2 {
+		t.Errorf("Expected no fetch with TTL disabled, got %d fetches", source.fetches.Load())
+	}
+}
+
+func TestCatalogueHolderConcurrentReaders(t *testing.T) {
+	source := &fakeCatalogueSource{data: testCatalogueCSV}
+	holder := newCatalogueHolder(source, 0)
+	if _, err := holder.Reload(context.Background()); err != nil {
+		t.Fatalf("Failed to load catalogue: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				// Every snapshot is complete: either the 2-car or the 3-car catalogue
+				snapshot := holder.Snapshot()
+				if n := len(snapshot.Cars); n != 2 && n != 3 {
+					t.Errorf("Observed partial catalogue with %d cars", n)
+					return
+				}
+			}
+		}()
+	}
+
+	extra := "3,Skoda Octavia,29900,01.2022,Kombi,30000,Manuell,Benzin,Front,150,110\n"
+	for i := 0; i < 50; i++ {
+		if i%2 == 0 {
+			source.set(testCatalogueCSV+extra, nil)
+		} else {
+			source.set(testCatalogueCSV, nil)
+		}
+		if _, err := holder.Reload(context.Background()); err != nil {
+			t.Fatalf("Reload failed: %v", err)
+		}
+	}
+	close(stop)
+	wg.Wait()
+}
+
+func TestURLCatalogueSource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/autos.csv" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/csv")
+		w.Write([]byte(testCatalogueCSV))
+	}))
+	defer server.Close()
+
+	holder := newCatalogueHolder(urlCatalogueSource{URL: server.URL + "/autos.csv"}, 0)
+	if _, err := holder.Reload(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(holder.Snapshot().Cars) != 2 {
+		t.Errorf("Expected 2 cars, got %d", len(holder.Snapshot().Cars))
+	}
+
+	missing := newCatalogueHolder(urlCatalogueSource{URL: server.URL + "/missing.csv"}, 0)
+	if _, err := missing.Reload(context.Background()); err == nil {
+		t.Error("Expected error for missing catalogue")
+	}
+}
+
+func TestURLCatalogueSourceTooLarge(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/csv")
+		w.Write([]byte(testCatalogueCSV))
+	}))
+	defer server.Close()
+
+	source := urlCatalogueSource{URL: server.URL + "/autos.csv", MaxSize: int64(len(testCatalogueCSV))}
+	holder := newCatalogueHolder(source, 0)
+	if _, err := holder.Reload(context.Background()); err != nil {
+		t.Fatalf("Expected a catalogue of exactly MaxSize to load: %v", err)
+	}
+	previous := holder.Snapshot()
+
+	// A catalogue one byte over the limit must not be cut off and published
+	source.MaxSize--
+	holder.source = source
+	if _, err := holder.Reload(context.Background()); err == nil || !strings.Contains(err.Error(), "exceeds") {
+		t.Fatalf("Expected error for oversized catalogue, got %v", err)
+	}
+	if holder.Snapshot() != previous {
+		t.Error("Expected the previous snapshot to stay published")
+	}
+}
+
+func TestIsCatalogueReloadEvent(t *testing.T) {
+	tests := []struct {
+		name     string
+		event    string
+		expected bool
+	}{
+		{name: "S3 notification", event: `{"Records": [{"eventSource": "aws:s3", "eventName": "ObjectCreated:Put"}]}`, expected: true},
+		{name: "Scheduled event", event: `{"source": "aws.events", "detail-type": "Scheduled Event"}`, expected: true},
+		{name: "API Gateway request", event: `{"resource": "/search", "httpMethod": "POST"}`, expected: false},
+		{name: "SQS message", event: `{"Records": [{"eventSource": "aws:sqs"}]}`, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := isCatalogueReloadEvent(json.RawMessage(tt.event)); result != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestHandleEvent(t *testing.T) {
+	source := &fakeCatalogueSource{data: testCatalogueCSV}
+	original := catalogue
+	catalogue = newCatalogueHolder(source, 0)
+	defer func() { catalogue = original }()
+
+	result, err := handleEvent(context.Background(), json.RawMessage(`{"Records": [{"eventSource": "aws:s3"}]}`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if reloaded, _ := result.(map[string]bool); !reloaded["reloaded"] {
+		t.Errorf("Expected catalogue to be reloaded, got %v", result)
+	}
+
+	result, err = handleEvent(context.Background(), json.RawMessage(`{"resource": "/search", "httpMethod": "POST", "body": "{\"query\": \"Audi\"}"}`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	response, ok := result.(events.APIGatewayProxyResponse)
+	if !ok || response.StatusCode != http.StatusOK || !strings.Contains(response.Body, `"total":1`) {
+		t.Errorf("Unexpected API response: %+v", result)
+	}
+}
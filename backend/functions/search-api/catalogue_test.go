@@ -0,0 +1,253 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestDetectCatalogueFormat(t *testing.T) {
+	tests := []struct {
+		name        string
+		file        string
+		contentType string
+		expected    string
+		wantErr     bool
+	}{
+		{name: "CSV extension", file: "autos.csv", expected: FormatCSV},
+		{name: "JSON extension", file: "catalogue/autos.JSON", expected: FormatJSON},
+		{name: "XLSX extension", file: "Inventar.xlsx", expected: FormatXLSX},
+		{name: "Content type wins", file: "upload", contentType: "application/json; charset=utf-8", expected: FormatJSON},
+		{name: "XLSX content type", file: "upload", contentType: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", expected: FormatXLSX},
+		{name: "Generic content type falls back to extension", file: "autos.csv", contentType: "binary/octet-stream", expected: FormatCSV},
+		{name: "Unknown format", file: "autos.xls", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			format, err := detectCatalogueFormat(tt.file, tt.contentType)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error, got format %q", format)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if format != tt.expected {
+				t.Errorf("Expected format %q, got %q", tt.expected, format)
+			}
+		})
+	}
+}
+
+func TestParseCarsJSON(t *testing.T) {
+	data := `[
+		{
+			"id": 1,
+			"title": "<b>BMW</b> 520d",
+			"brand": "ignored",
+			"price_chf": 42890,
+			"first_registration": "08.2021",
+			"car_type": "Limousine",
+			"mileage_km": 55000,
+			"transmission": "Automatik",
+			"fuel": "Diesel",
+			"drive": "Allrad",
+			"power_hp": 190,
+			"power_kw": 140,
+			"mfk": true,
+			"equipment": ["Sportsitze", " ", "Rückfahrkamera"],
+			"image_urls": ["https://img.example.com/bmw1.jpg", "javascript:alert(1)"]
+		},
+		{
+			"id": 2,
+			"title": "Negative price",
+			"price_chf": -1
+		}
+	]`
+
+	loaded, err := parseCatalogue("autos.json", "", []byte(data))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(loaded) != 1 {
+		t.Fatalf("Expected 1 car, got %d", len(loaded))
+	}
+
+	car := loaded[0]
+	if car.Title != "&lt;b&gt;BMW&lt;/b&gt; 520d" {
+		t.Errorf("Expected title to be sanitized, got %q", car.Title)
+	}
+	if car.Brand != "&lt;b&gt;BMW&lt;/b&gt;" {
+		t.Errorf("Expected brand to be derived from the title, got %q", car.Brand)
+	}
+	if !car.MFK || car.Warranty {
+		t.Errorf("Expected mfk=true and warranty=false, got %v and %v", car.MFK, car.Warranty)
+	}
+	if !slicesEqual(car.Equipment, []string{"Sportsitze", "Rückfahrkamera"}) {
+		t.Errorf("Unexpected equipment: %v", car.Equipment)
+	}
+	if !slicesEqual(car.ImageURLs, []string{"https://img.example.com/bmw1.jpg"}) {
+		t.Errorf("Expected javascript: URL to be filtered out, got %v", car.ImageURLs)
+	}
+
+	if _, err := parseCatalogue("autos.json", "", []byte(`[{"id": "1"}]`)); err == nil {
+		t.Error("Expected error for malformed JSON")
+	}
+}
+
+func TestParseCatalogueXLSX(t *testing.T) {
+	// Columns are reordered, the sheet has an extra column and the second row
+	// leaves the optional trailing cells empty
+	rows := [][]string{
+		{"title", "id", "price_chf", "first_registration", "car_type", "mileage_km", "transmission", "fuel", "drive", "power_hp", "power_kw", "mfk", "notes", "equipment"},
+		{"BMW 520d", "#1", "#42890", "08.2021", "Limousine", "#55000", "Automatik", "Diesel", "Allrad", "#190", "#140", "?1", "intern", "Sportsitze;Rückfahrkamera"},
+		{"Audi A4 Avant", "#2", "38'900.-", "07.2020", "Kombi", "#62000", "Automatik", "Diesel", "Front", "#204", "#150"},
+		{"Broken", "#3", "auf Anfrage", "07.2020", "Kombi", "#62000", "Automatik", "Diesel", "Front", "#204", "#150"},
+		{"VW Golf", "#4", "#21500", "#44409", "Kleinwagen", "#41000", "Manuell", "Benzin", "Front", "#150", "#110"},
+	}
+
+	loaded, err := parseCatalogue("autos.xlsx", "", buildTestWorkbook(t, rows))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(loaded) != 3 {
+		t.Fatalf("Expected 3 cars, got %d", len(loaded))
+	}
+
+	if loaded[0].ID != 1 || loaded[0].PriceCHF != 42890 || !loaded[0].MFK {
+		t.Errorf("Unexpected first car: %+v", loaded[0])
+	}
+	if !slicesEqual(loaded[0].Equipment, []string{"Sportsitze", "Rückfahrkamera"}) {
+		t.Errorf("Unexpected equipment: %v", loaded[0].Equipment)
+	}
+	if loaded[1].ID != 2 || loaded[1].PriceCHF != 38900 || len(loaded[1].Equipment) != 0 {
+		t.Errorf("Unexpected second car: %+v", loaded[1])
+	}
+
+	// A date cell in first_registration holds an Excel serial
+	if loaded[2].FirstReg != "08.2021" || loaded[2].FirstRegYear != 2021 {
+		t.Errorf("Expected date serial to become 08.2021, got %q (%d)", loaded[2].FirstReg, loaded[2].FirstRegYear)
+	}
+	// Other numeric columns are not dates
+	if loaded[2].PriceCHF != 21500 {
+		t.Errorf("Expected price 21500, got %d", loaded[2].PriceCHF)
+	}
+
+	if _, err := parseCatalogue("autos.xlsx", "", []byte("not a zip file")); err == nil {
+		t.Error("Expected error for invalid workbook")
+	}
+}
+
+func TestXLSXColumnIndex(t *testing.T) {
+	tests := map[string]int{"A1": 0, "B7": 1, "Z3": 25, "AA10": 26, "AB2": 27, "XFD1": maxXLSXColumns - 1}
+	for ref, expected := range tests {
+		index, err := xlsxColumnIndex(ref)
+		if err != nil || index != expected {
+			t.Errorf("xlsxColumnIndex(%q) = %d, %v; want %d", ref, index, err, expected)
+		}
+	}
+
+	if _, err := xlsxColumnIndex("12"); err == nil {
+		t.Error("Expected error for reference without column")
+	}
+	for _, ref := range []string{"XFE1", "ZZZZZZZ1"} {
+		if _, err := xlsxColumnIndex(ref); err == nil {
+			t.Errorf("Expected error for %q beyond column XFD", ref)
+		}
+	}
+}
+
+func TestXLSXDate(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{"44409", "2021-08-01"},
+		{"44409.75", "2021-08-01"},
+		{"61", "1900-03-01"},
+		{"1", "1899-12-31"},
+	}
+	for _, tt := range tests {
+		date, ok := xlsxDate(tt.value)
+		if !ok || date.Format("2006-01-02") != tt.expected {
+			t.Errorf("xlsxDate(%q) = %v, %v; want %s", tt.value, date, ok, tt.expected)
+		}
+	}
+
+	for _, value := range []string{"", "0", "-5", "3000000"} {
+		if _, ok := xlsxDate(value); ok {
+			t.Errorf("Expected %q not to be a date", value)
+		}
+	}
+}
+
+// buildTestWorkbook writes a minimal .xlsx workbook. Plain values become shared
+// strings, values prefixed with "#" numeric cells and "?" boolean cells.
+func buildTestWorkbook(t *testing.T, rows [][]string) []byte {
+	t.Helper()
+
+	var shared []string
+	var sheet strings.Builder
+	sheet.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	for r, row := range rows {
+		fmt.Fprintf(&sheet, `<row r="%d">`, r+1)
+		for c, value := range row {
+			ref := fmt.Sprintf("%c%d", 'A'+c, r+1)
+			switch {
+			case value == "":
+				continue
+			case strings.HasPrefix(value, "#"):
+				fmt.Fprintf(&sheet, `<c r="%s"><v>%s</v></c>`, ref, value[1:])
+			case strings.HasPrefix(value, "?"):
+				fmt.Fprintf(&sheet, `<c r="%s" t="b"><v>%s</v></c>`, ref, value[1:])
+			default:
+				fmt.Fprintf(&sheet, `<c r="%s" t="s"><v>%d</v></c>`, ref, len(shared))
+				shared = append(shared, value)
+			}
+		}
+		sheet.WriteString(`</row>`)
+	}
+	sheet.WriteString(`</sheetData></worksheet>`)
+
+	var sharedXML strings.Builder
+	sharedXML.WriteString(`<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	for _, value := range shared {
+		fmt.Fprintf(&sharedXML, `<si><t>%s</t></si>`, value)
+	}
+	sharedXML.WriteString(`</sst>`)
+
+	parts := map[string]string{
+		"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets><sheet name="Fahrzeuge" sheetId="1" r:id="rId1"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`,
+		"xl/sharedStrings.xml":     sharedXML.String(),
+		"xl/worksheets/sheet1.xml": sheet.String(),
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for name, content := range parts {
+		w, err := archive.Create(name)
+		if err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatalf("Failed to close workbook: %v", err)
+	}
+
+	return buf.Bytes()
+}
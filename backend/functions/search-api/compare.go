@@ -0,0 +1,210 @@
+package main
+
+import (
+	"strings"
+)
+
+// Number of cars POST /cars/compare accepts
+const (
+	MinCompareCars = 2
+	MaxCompareCars = 4
+)
+
+// CompareRequest lists the cars to compare
+type CompareRequest struct {
+	CarIDs []int `json:"car_ids"`
+}
+
+// ComparedField is one attribute of the compared cars. Values are in the
+// order of CompareResponse.Cars; Best holds the IDs of the cars with the best
+// value for ranked attributes. With a language, enumerated attributes have
+// their translated Labels in the same order.
+type ComparedField struct {
+	Field  string        `json:"field"`
+	Values []interface{} `json:"values"`
+	Labels []string      `json:"labels,omitempty"`
+	Best   []int         `json:"best,omitempty"`
+}
+
+// EquipmentComparison splits the equipment into items every car has, items
+// several but not all cars have and items only one car has. Partial and
+// Unique are keyed by car ID.
+type EquipmentComparison struct {
+	Shared  []string         `json:"shared"`
+	Partial map[int][]string `json:"partial"`
+	Unique  map[int][]string `json:"unique"`
+}
+
+// CompareResponse lines up the compared cars field by field
+type CompareResponse struct {
+	Cars      []LocalizedCar      `json:"cars"`
+	Fields    []ComparedField     `json:"fields"`
+	Equipment EquipmentComparison `json:"equipment"`
+}
+
+// Ranking of compared attributes
+const (
+	notRanked    = 0
+	lowerIsBest  = -1
+	higherIsBest = 1
+)
+
+// comparedFields are the attributes lined up by compareCars, in display
+// order. For ranked attributes where zeroMissing is set, zero means the value
+// is unknown and never wins; a mileage of zero is a new car.
+var comparedFields = []struct {
+	name        string
+	value       func(Car) interface{}
+	rank        int
+	zeroMissing bool
+}{
+	{"title", func(c Car) interface{} { return c.Title }, notRanked, false},
+	{"brand", func(c Car) interface{} { return c.Brand }, notRanked, false},
+	{"price_chf", func(c Car) interface{} { return c.PriceCHF }, lowerIsBest, true},
+	{"leasing_text", func(c Car) interface{} { return c.LeasingText }, notRanked, false},
+	{"first_registration", func(c Car) interface{} { return c.FirstReg }, notRanked, false},
+	{"car_type", func(c Car) interface{} { return c.CarType }, notRanked, false},
+	{"mileage_km", func(c Car) interface{} { return c.MileageKM }, lowerIsBest, false},
+	{"transmission", func(c Car) interface{} { return c.Transmission }, notRanked, false},
+	{"fuel", func(c Car) interface{} { return c.Fuel }, notRanked, false},
+	{"drive", func(c Car) interface{} { return c.Drive }, notRanked, false},
+	{"power_hp", func(c Car) interface{} { return c.PowerHP }, higherIsBest, true},
+	{"power_kw", func(c Car) interface{} { return c.PowerKW }, higherIsBest, true},
+	{"mfk", func(c Car) interface{} { return c.MFK }, notRanked, false},
+	{"warranty", func(c Car) interface{} { return c.Warranty }, notRanked, false},
+	{"warranty_text", func(c Car) interface{} { return c.WarrantyText }, notRanked, false},
+	{"status", func(c Car) interface{} { return c.Status }, notRanked, false},
+}
+
+// validateCompareRequest checks the number of car IDs and rejects duplicates
+func validateCompareRequest(req CompareRequest) []ValidationError {
+	var errors []ValidationError
+
+	if len(req.CarIDs) < MinCompareCars || len(req.CarIDs) > MaxCompareCars {
+		errors = append(errors, newValidationError("car_ids", msgCompareCount, MinCompareCars, MaxCompareCars))
+	}
+
+	seen := make(map[int]bool)
+	for _, id := range req.CarIDs {
+		if id <= 0 {
+			errors = append(errors, newValidationError("car_ids", msgCarIDInvalid, id))
+		} else if seen[id] {
+			errors = append(errors, newValidationError("car_ids", msgCarIDDuplicate, id))
+		}
+		seen[id] = true
+	}
+
+	return errors
+}
+
+// findCars looks up the cars in the order of ids and returns the IDs not in the catalogue
+func findCars(ids []int) ([]Car, []int) {
+	cars := make([]Car, 0, len(ids))
+	var missing []int
+	for _, id := range ids {
+		if car, ok := findCar(id); ok {
+			cars = append(cars, car)
+		} else {
+			missing = append(missing, id)
+		}
+	}
+	return cars, missing
+}
+
+// compareCars lines up the cars field by field and compares their equipment
+func compareCars(cars []Car) CompareResponse {
+	fields := make([]ComparedField, 0, len(comparedFields))
+	for _, f := range comparedFields {
+		field := ComparedField{Field: f.name, Values: make([]interface{}, len(cars))}
+		for i, car := range cars {
+			field.Values[i] = f.value(car)
+		}
+		if f.rank != notRanked {
+			field.Best = bestCars(cars, field.Values, f.rank, f.zeroMissing)
+		}
+		fields = append(fields, field)
+	}
+
+	localized := make([]LocalizedCar, len(cars))
+	for i, car := range cars {
+		localized[i] = LocalizedCar{Car: car}
+	}
+
+	return CompareResponse{
+		Cars:      localized,
+		Fields:    fields,
+		Equipment: compareEquipment(cars),
+	}
+}
+
+// bestCars returns the IDs of all cars sharing the best value
+func bestCars(cars []Car, values []interface{}, rank int, zeroMissing bool) []int {
+	var best []int
+	bestValue := 0
+	for i, car := range cars {
+		value := values[i].(int)
+		if value == 0 && zeroMissing {
+			continue
+		}
+
+		switch {
+		case best == nil || value*rank > bestValue*rank:
+			best = []int{car.ID}
+			bestValue = value
+		case value == bestValue:
+			best = append(best, car.ID)
+		}
+	}
+	return best
+}
+
+// compareEquipment splits the equipment of the cars into shared, partial and
+// unique items. Items are matched case-insensitively and keep the spelling of the
+// first car listing them.
+func compareEquipment(cars []Car) EquipmentComparison {
+	owners := make(map[string]map[int]bool)
+	var order []string
+	labels := make(map[string]string)
+
+	for _, car := range cars {
+		for _, item := range car.Equipment {
+			key := strings.ToLower(strings.TrimSpace(item))
+			if key == "" {
+				continue
+			}
+			if owners[key] == nil {
+				owners[key] = make(map[int]bool)
+				order = append(order, key)
+				labels[key] = strings.TrimSpace(item)
+			}
+			owners[key][car.ID] = true
+		}
+	}
+
+	comparison := EquipmentComparison{
+		Shared:  make([]string, 0),
+		Partial: make(map[int][]string, len(cars)),
+		Unique:  make(map[int][]string, len(cars)),
+	}
+	for _, car := range cars {
+		comparison.Partial[car.ID] = make([]string, 0)
+		comparison.Unique[car.ID] = make([]string, 0)
+	}
+
+	for _, key := range order {
+		switch len(owners[key]) {
+		case len(cars):
+			comparison.Shared = append(comparison.Shared, labels[key])
+		case 1:
+			for id := range owners[key] {
+				comparison.Unique[id] = append(comparison.Unique[id], labels[key])
+			}
+		default:
+			for id := range owners[key] {
+				comparison.Partial[id] = append(comparison.Partial[id], labels[key])
+			}
+		}
+	}
+
+	return comparison
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func compareTestCars() []Car {
+	return []Car{
+		{ID: 1, Title: "BMW 520d", Brand: "BMW", PriceCHF: 42000, MileageKM: 55000, PowerHP: 190, PowerKW: 140, Status: StatusAvailable,
+			Equipment: []string{"Navigationssystem", "Rückfahrkamera", "Sportsitze"}},
+		{ID: 2, Title: "Audi A6", Brand: "Audi", PriceCHF: 39000, MileageKM: 80000, PowerHP: 204, PowerKW: 150, Status: StatusAvailable,
+			Equipment: []string{"navigationssystem", "Rückfahrkamera", "Matrix LED"}},
+		{ID: 3, Title: "Mercedes E 220d", Brand: "Mercedes", PriceCHF: 39000, MileageKM: 55000, PowerHP: 0, PowerKW: 0, Status: StatusReserved,
+			Equipment: []string{"Navigationssystem", "Sportsitze", "Head-up-Display"}},
+	}
+}
+
+func comparedField(t *testing.T, response CompareResponse, name string) ComparedField {
+	t.Helper()
+	for _, field := range response.Fields {
+		if field.Field == name {
+			return field
+		}
+	}
+	t.Fatalf("Field %q missing from comparison", name)
+	return ComparedField{}
+}
+
+func TestCompareCars(t *testing.T) {
+	response := compareCars(compareTestCars())
+
+	if len(response.Cars) != 3 || response.Cars[0].ID != 1 || response.Cars[2].ID != 3 {
+		t.Fatalf("Expected cars in request order, got %+v", response.Cars)
+	}
+
+	title := comparedField(t, response, "title")
+	if !reflect.DeepEqual(title.Values, []interface{}{"BMW 520d", "Audi A6", "Mercedes E 220d"}) || title.Best != nil {
+		t.Errorf("Unexpected title field: %+v", title)
+	}
+
+	tests := []struct {
+		field    string
+		expected []int
+	}{
+		{"price_chf", []int{2, 3}},  // Tie on the lowest price
+		{"mileage_km", []int{1, 3}}, // Tie on the lowest mileage
+		{"power_hp", []int{2}},      // Highest power
+		{"power_kw", []int{2}},
+	}
+	for _, tt := range tests {
+		if best := comparedField(t, response, tt.field).Best; !reflect.DeepEqual(best, tt.expected) {
+			t.Errorf("%s: expected best %v, got %v", tt.field, tt.expected, best)
+		}
+	}
+
+	expectedShared := []string{"Navigationssystem"}
+	if !reflect.DeepEqual(response.Equipment.Shared, expectedShared) {
+		t.Errorf("Expected shared equipment %v, got %v", expectedShared, response.Equipment.Shared)
+	}
+	// Items of two of the three cars are listed for both
+	expectedPartial := map[int][]string{
+		1: {"Rückfahrkamera", "Sportsitze"},
+		2: {"Rückfahrkamera"},
+		3: {"Sportsitze"},
+	}
+	if !reflect.DeepEqual(response.Equipment.Partial, expectedPartial) {
+		t.Errorf("Expected partial equipment %v, got %v", expectedPartial, response.Equipment.Partial)
+	}
+	expectedUnique := map[int][]string{
+		1: {},
+		2: {"Matrix LED"},
+		3: {"Head-up-Display"},
+	}
+	if !reflect.DeepEqual(response.Equipment.Unique, expectedUnique) {
+		t.Errorf("Expected unique equipment %v, got %v", expectedUnique, response.Equipment.Unique)
+	}
+}
+
+func TestBestCarsIgnoresMissingValues(t *testing.T) {
+	cars := []Car{{ID: 1, PriceCHF: 0, MileageKM: 0}, {ID: 2, PriceCHF: 5000, MileageKM: 1000}}
+	response := compareCars(cars)
+
+	if best := comparedField(t, response, "price_chf").Best; !reflect.DeepEqual(best, []int{2}) {
+		t.Errorf("Expected missing price to be ignored, got %v", best)
+	}
+	if best := comparedField(t, response, "mileage_km").Best; !reflect.DeepEqual(best, []int{1}) {
+		t.Errorf("Expected new car to have the best mileage, got %v", best)
+	}
+	if best := comparedField(t, response, "power_hp").Best; best != nil {
+		t.Errorf("Expected no best power without data, got %v", best)
+	}
+}
+
+func TestValidateCompareRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		ids     []int
+		wantErr bool
+	}{
+		{name: "Two cars", ids: []int{1, 2}},
+		{name: "Four cars", ids: []int{1, 2, 3, 4}},
+		{name: "One car", ids: []int{1}, wantErr: true},
+		{name: "Five cars", ids: []int{1, 2, 3, 4, 5}, wantErr: true},
+		{name: "Duplicate", ids: []int{1, 1}, wantErr: true},
+		{name: "Invalid ID", ids: []int{1, -2}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errors := validateCompareRequest(CompareRequest{CarIDs: tt.ids})
+			if (len(errors) > 0) != tt.wantErr {
+				t.Errorf("Expected error: %v, got %v", tt.wantErr, errors)
+			}
+		})
+	}
+}
+
+func TestHandleCompareCars(t *testing.T) {
+	setTestCars(t, compareTestCars())
+
+	tests := []struct {
+		name     string
+		method   string
+		body     string
+		expected int
+	}{
+		{name: "Compare", method: "POST", body: `{"car_ids": [3, 1]}`, expected: http.StatusOK},
+		{name: "Unknown car", method: "POST", body: `{"car_ids": [1, 99]}`, expected: http.StatusNotFound},
+		{name: "Too many cars", method: "POST", body: `{"car_ids": [1, 2, 3, 4, 5]}`, expected: http.StatusBadRequest},
+		{name: "Invalid JSON", method: "POST", body: `{"car_ids": "1,2"}`, expected: http.StatusBadRequest},
+		{name: "Wrong method", method: "GET", expected: http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := events.APIGatewayProxyRequest{
+				Resource:   "/cars/compare",
+				HTTPMethod: tt.method,
+				Body:       tt.body,
+			}
+
+			response, err := handleRequest(context.Background(), request)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if response.StatusCode != tt.expected {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expected, response.StatusCode, response.Body)
+			}
+			if tt.expected != http.StatusOK {
+				return
+			}
+
+			var body CompareResponse
+			if err := json.Unmarshal([]byte(response.Body), &body); err != nil {
+				t.Fatalf("Invalid response body: %v", err)
+			}
+			if len(body.Cars) != 2 || body.Cars[0].ID != 3 || body.Cars[1].ID != 1 {
+				t.Errorf("Expected cars 3 and 1, got %+v", body.Cars)
+			}
+			if len(body.Equipment.Unique[1]) != 1 || body.Equipment.Unique[1][0] != "Rückfahrkamera" {
+				t.Errorf("Expected unique equipment for car 1, got %v", body.Equipment.Unique)
+			}
+		})
+	}
+}
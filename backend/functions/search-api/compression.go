@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// MinCompressSize is the body size below which responses stay uncompressed,
+// as compression would barely save anything on them
+const MinCompressSize = 1024
+
+// supportedEncodings lists the content encodings in order of preference
+var supportedEncodings = []string{"br", "gzip"}
+
+// negotiateEncoding picks the supported encoding with the highest quality in
+// an Accept-Encoding header, or "" if the client accepts none of them
+func negotiateEncoding(acceptEncoding string) string {
+	qualities := make(map[string]float64)
+	wildcard := 0.0
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(part, ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		quality := 1.0
+		for _, param := range strings.Split(params, ";") {
+			key, value, ok := strings.Cut(param, "=")
+			if ok && strings.EqualFold(strings.TrimSpace(key), "q") {
+				parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+				if err != nil {
+					parsed = 0
+				}
+				quality = parsed
+			}
+		}
+
+		if name == "*" {
+			wildcard = quality
+		} else {
+			qualities[name] = quality
+		}
+	}
+
+	best, bestQuality := "", 0.0
+	for _, encoding := range supportedEncodings {
+		quality, ok := qualities[encoding]
+		if !ok {
+			quality = wildcard
+		}
+		if quality > bestQuality {
+			best, bestQuality = encoding, quality
+		}
+	}
+	return best
+}
+
+// compressBody compresses a body with the given encoding
+func compressBody(encoding, body string) ([]byte, error) {
+	var buf bytes.Buffer
+	var writer io.WriteCloser
+	switch encoding {
+	case "br":
+		writer = brotli.NewWriterLevel(&buf, brotli.DefaultCompression)
+	default:
+		writer = gzip.NewWriter(&buf)
+	}
+
+	if _, err := io.WriteString(writer, body); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// compressResponse compresses bodies of at least MinCompressSize with the
+// encoding negotiated from Accept-Encoding. Compressed bodies are returned
+// base64 encoded, which API Gateway decodes for binary media types.
+func compressResponse(request events.APIGatewayProxyRequest, response events.APIGatewayProxyResponse) events.APIGatewayProxyResponse {
+	if response.IsBase64Encoded || len(response.Body) < MinCompressSize {
+		return response
+	}
+	if response.Headers == nil {
+		response.Headers = make(map[string]string)
+	}
+	addVary(response.Headers, "Accept-Encoding")
+
+	encoding := negotiateEncoding(headerValue(request.Headers, "Accept-Encoding"))
+	if encoding == "" {
+		return response
+	}
+
+	compressed, err := compressBody(encoding, response.Body)
+	if err != nil {
+		log.Printf("Error compressing response with %s: %v", encoding, err)
+		return response
+	}
+
+	response.Headers["Content-Encoding"] = encoding
+	// The compressed representation is only semantically equal to the uncompressed one
+	if etag := response.Headers["ETag"]; etag != "" && !strings.HasPrefix(etag, "W/") {
+		response.Headers["ETag"] = "W/" + etag
+	}
+	response.Body = base64.StdEncoding.EncodeToString(compressed)
+	response.IsBase64Encoded = true
+	return response
+}
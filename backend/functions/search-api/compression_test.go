@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	tests := []struct {
+		header   string
+		expected string
+	}{
+		{header: "gzip, deflate, br", expected: "br"},
+		{header: "gzip", expected: "gzip"},
+		{header: "br;q=0.5, gzip;q=0.8", expected: "gzip"},
+		{header: "GZIP", expected: "gzip"},
+		{header: "*", expected: "br"},
+		{header: "*;q=0.5, br;q=0", expected: "gzip"},
+		{header: "gzip;q=0, br;q=0", expected: ""},
+		{header: "deflate, identity", expected: ""},
+		{header: "", expected: ""},
+	}
+
+	for _, tt := range tests {
+		if result := negotiateEncoding(tt.header); result != tt.expected {
+			t.Errorf("negotiateEncoding(%q): expected %q, got %q", tt.header, tt.expected, result)
+		}
+	}
+}
+
+// decompressBody reverses compressResponse
+func decompressBody(t *testing.T, response events.APIGatewayProxyResponse) string {
+	t.Helper()
+
+	compressed, err := base64.StdEncoding.DecodeString(response.Body)
+	if err != nil {
+		t.Fatalf("Body is not base64: %v", err)
+	}
+
+	var reader io.Reader
+	switch response.Headers["Content-Encoding"] {
+	case "br":
+		reader = brotli.NewReader(bytes.NewReader(compressed))
+	case "gzip":
+		gzipReader, err := gzip.NewReader(bytes.NewReader(compressed))
+		if err != nil {
+			t.Fatalf("Invalid gzip body: %v", err)
+		}
+		reader = gzipReader
+	default:
+		t.Fatalf("Unexpected encoding %q", response.Headers["Content-Encoding"])
+	}
+
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("Error decompressing body: %v", err)
+	}
+	return string(body)
+}
+
+func TestCompressResponse(t *testing.T) {
+	large := `{"description": "` + strings.Repeat("Top gepflegt ", 200) + `"}`
+
+	for _, encoding := range []string{"br", "gzip"} {
+		t.Run(encoding, func(t *testing.T) {
+			request := events.APIGatewayProxyRequest{Headers: map[string]string{"accept-encoding": encoding}}
+			response := compressResponse(request, events.APIGatewayProxyResponse{
+				StatusCode: http.StatusOK,
+				Headers:    map[string]string{"ETag": `"abc"`},
+				Body:       large,
+			})
+
+			if !response.IsBase64Encoded || response.Headers["Vary"] != "Accept-Encoding" || response.Headers["ETag"] != `W/"abc"` {
+				t.Errorf("Unexpected response headers %v (base64 %v)", response.Headers, response.IsBase64Encoded)
+			}
+			if body := decompressBody(t, response); body != large {
+				t.Errorf("Expected original body, got %q", body)
+			}
+			if len(response.Body) >= len(large) {
+				t.Errorf("Expected smaller body, got %d of %d bytes", len(response.Body), len(large))
+			}
+		})
+	}
+
+	small := compressResponse(
+		events.APIGatewayProxyRequest{Headers: map[string]string{"Accept-Encoding": "gzip"}},
+		events.APIGatewayProxyResponse{StatusCode: http.StatusOK, Headers: map[string]string{}, Body: `{"total": 0}`},
+	)
+	if small.IsBase64Encoded || small.Headers["Content-Encoding"] != "" {
+		t.Errorf("Expected small body to stay uncompressed, got %+v", small)
+	}
+
+	identity := compressResponse(events.APIGatewayProxyRequest{}, events.APIGatewayProxyResponse{Headers: map[string]string{}, Body: large})
+	if identity.IsBase64Encoded || identity.Body != large || identity.Headers["Vary"] != "Accept-Encoding" {
+		t.Errorf("Expected uncompressed body with Vary, got %v", identity.Headers)
+	}
+}
+
+func TestHandleRequestCompression(t *testing.T) {
+	setTestCars(t, cursorTestCars())
+
+	request := events.APIGatewayProxyRequest{
+		Resource:   "/search",
+		HTTPMethod: "POST",
+		Body:       `{"limit": 100}`,
+	}
+	plain, _ := handleRequest(context.Background(), request)
+
+	// Binary media types make API Gateway pass the request body base64 encoded as well
+	request.Body = base64.StdEncoding.EncodeToString([]byte(request.Body))
+	request.IsBase64Encoded = true
+	request.Headers = map[string]string{"Accept-Encoding": "gzip, br"}
+	compressed, _ := handleRequest(context.Background(), request)
+
+	if compressed.StatusCode != http.StatusOK || compressed.Headers["Content-Encoding"] != "br" {
+		t.Fatalf("Expected brotli response, got %d %v", compressed.StatusCode, compressed.Headers)
+	}
+	if body := decompressBody(t, compressed); body != plain.Body {
+		t.Errorf("Expected the uncompressed response\n%s\ngot\n%s", plain.Body, body)
+	}
+
+	request.Body = "not base64!"
+	if invalid, _ := handleRequest(context.Background(), request); invalid.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected 400 for invalid base64 body, got %d", invalid.StatusCode)
+	}
+}
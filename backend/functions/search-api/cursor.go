@@ -0,0 +1,173 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"sort"
+	"strings"
+)
+
+// DefaultSort orders search results by ID, which keeps pages stable across reloads
+const DefaultSort = "id"
+
+// searchSort is a sort order of search results. Ties are always broken by ascending ID.
+type searchSort struct {
+	key        func(Car) int
+	descending bool
+}
+
+// searchSorts are the accepted values of SearchRequest.Sort
+var searchSorts = map[string]searchSort{
+	DefaultSort:    {key: func(c Car) int { return c.ID }},
+	"price_asc":    {key: func(c Car) int { return c.PriceCHF }},
+	"price_desc":   {key: func(c Car) int { return c.PriceCHF }, descending: true},
+	"mileage_asc":  {key: func(c Car) int { return c.MileageKM }},
+	"mileage_desc": {key: func(c Car) int { return c.MileageKM }, descending: true},
+	"power_asc":    {key: func(c Car) int { return c.PowerHP }},
+	"power_desc":   {key: func(c Car) int { return c.PowerHP }, descending: true},
+	"year_asc":     {key: func(c Car) int { return c.FirstRegYear }},
+	"year_desc":    {key: func(c Car) int { return c.FirstRegYear }, descending: true},
+}
+
+// before reports whether a comes before b
+func (s searchSort) before(a, b Car) bool {
+	keyA, keyB := s.key(a), s.key(b)
+	if keyA != keyB {
+		return (keyA < keyB) != s.descending
+	}
+	return a.ID < b.ID
+}
+
+// after reports whether car comes after the position of cursor
+func (s searchSort) after(car Car, cursor searchCursor) bool {
+	key := s.key(car)
+	if key != cursor.Key {
+		return (key > cursor.Key) != s.descending
+	}
+	return car.ID > cursor.ID
+}
+
+// sortCars orders cars in place
+func (s searchSort) sortCars(cars []Car) {
+	sort.SliceStable(cars, func(i, j int) bool { return s.before(cars[i], cars[j]) })
+}
+
+// searchCursor is the position after the last car of a page. It is bound to
+// the sort and filters of the request that created it.
+type searchCursor struct {
+	Sort    string `json:"s"`
+	Key     int    `json:"k"`
+	ID      int    `json:"i"`
+	Filters string `json:"f"`
+}
+
+var errInvalidCursor = errors.New("invalid cursor")
+
+// cursorSecret signs cursor tokens
+var cursorSecret = cursorSecretFromEnv()
+
+// cursorSecretFromEnv reads CURSOR_SECRET. Without it every instance signs with
+// a random key, so cursors only work as long as they reach the same instance.
+func cursorSecretFromEnv() []byte {
+	if secret := os.Getenv("CURSOR_SECRET"); secret != "" {
+		return []byte(secret)
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		panic(err)
+	}
+	if os.Getenv("AWS_LAMBDA_FUNCTION_NAME") != "" {
+		log.Printf("CURSOR_SECRET is not set, cursors are only valid on this instance")
+	}
+	return secret
+}
+
+// encodeCursor returns the signed token "<payload>.<signature>", both base64url
+func encodeCursor(cursor searchCursor) string {
+	payload, _ := json.Marshal(cursor)
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(signCursor(encoded))
+}
+
+// decodeCursor verifies the signature of a token and returns its cursor
+func decodeCursor(token string) (searchCursor, error) {
+	var cursor searchCursor
+
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return cursor, errInvalidCursor
+	}
+	decodedSignature, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(decodedSignature, signCursor(encoded)) {
+		return cursor, errInvalidCursor
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || json.Unmarshal(payload, &cursor) != nil {
+		return cursor, errInvalidCursor
+	}
+	if _, ok := searchSorts[cursor.Sort]; !ok {
+		return cursor, errInvalidCursor
+	}
+	return cursor, nil
+}
+
+// signCursor returns the HMAC-SHA256 of an encoded cursor payload
+func signCursor(encoded string) []byte {
+	mac := hmac.New(sha256.New, cursorSecret)
+	mac.Write([]byte(encoded))
+	return mac.Sum(nil)
+}
+
+// searchFilterHash identifies the filters of a request, ignoring pagination
+// and fields, so that a cursor is only used with the search that created it
+func searchFilterHash(req SearchRequest) string {
+	req.Fields = nil
+	req.Sort = ""
+	req.Limit = 0
+	req.Offset = 0
+	req.Cursor = ""
+	encoded, _ := json.Marshal(req)
+	sum := sha256.Sum256(encoded)
+	return hex.EncodeToString(sum[:8])
+}
+
+// searchSortName returns the sort of a request, falling back to DefaultSort
+func searchSortName(req SearchRequest) string {
+	if req.Sort == "" {
+		return DefaultSort
+	}
+	return req.Sort
+}
+
+// validateSearchCursor checks the sort and cursor of a request
+func validateSearchCursor(req *SearchRequest) []ValidationError {
+	var errors []ValidationError
+
+	if _, ok := searchSorts[searchSortName(*req)]; !ok {
+		errors = append(errors, newValidationError("sort", msgUnknownSort))
+	}
+
+	if req.Cursor == "" {
+		return errors
+	}
+	if req.Offset != 0 {
+		errors = append(errors, newValidationError("cursor", msgCursorOrOffset))
+	}
+	cursor, err := decodeCursor(req.Cursor)
+	if err != nil {
+		errors = append(errors, newValidationError("cursor", msgInvalidCursor))
+	} else if cursor.Sort != searchSortName(*req) || cursor.Filters != searchFilterHash(*req) {
+		errors = append(errors, newValidationError("cursor", msgCursorMismatch))
+	}
+
+	return errors
+}
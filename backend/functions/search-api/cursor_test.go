@@ -0,0 +1,195 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func cursorTestCars() []Car {
+	return []Car{
+		{ID: 4, Title: "BMW 520d", Brand: "BMW", PriceCHF: 42890, Status: StatusAvailable},
+		{ID: 1, Title: "Audi A4", Brand: "Audi", PriceCHF: 38900, Status: StatusAvailable},
+		{ID: 3, Title: "BMW 320d", Brand: "BMW", PriceCHF: 38900, Status: StatusAvailable},
+		{ID: 2, Title: "Skoda Octavia", Brand: "Skoda", PriceCHF: 24900, Status: StatusAvailable},
+		{ID: 5, Title: "BMW X5", Brand: "BMW", PriceCHF: 89000, Status: StatusAvailable},
+	}
+}
+
+// pageThrough collects the IDs of all pages by following next_cursor
+func pageThrough(t *testing.T, req SearchRequest, beforeNextPage func()) []int {
+	t.Helper()
+
+	var ids []int
+	for pages := 0; pages < 10; pages++ {
+		if errors := validateSearchRequest(&req); len(errors) > 0 {
+			t.Fatalf("Unexpected validation errors: %v", errors)
+		}
+		response := searchCars(req)
+		for _, car := range response.Cars {
+			ids = append(ids, car.ID)
+		}
+		if response.NextCursor == "" {
+			return ids
+		}
+		req.Cursor = response.NextCursor
+		if beforeNextPage != nil {
+			beforeNextPage()
+		}
+	}
+	t.Fatal("Too many pages")
+	return nil
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	cursor := searchCursor{Sort: "price_desc", Key: 42890, ID: 4, Filters: "abc"}
+	token := encodeCursor(cursor)
+
+	decoded, err := decodeCursor(token)
+	if err != nil || decoded != cursor {
+		t.Fatalf("Expected %+v, got %+v (%v)", cursor, decoded, err)
+	}
+
+	payload, signature, _ := strings.Cut(token, ".")
+	tampered := []string{
+		"",
+		"not-a-cursor",
+		payload + ".",
+		encodeCursor(searchCursor{Sort: "price_desc", Key: 1, ID: 4, Filters: "abc"})[:len(payload)] + "." + signature,
+		payload + "." + signature[1:],
+		encodeCursor(searchCursor{Sort: "colour"}),
+	}
+	for _, token := range tampered {
+		if _, err := decodeCursor(token); err == nil {
+			t.Errorf("Expected error for %q", token)
+		}
+	}
+}
+
+func TestSearchCarsCursorPagination(t *testing.T) {
+	tests := []struct {
+		sort     string
+		expected []int
+	}{
+		{sort: "", expected: []int{1, 2, 3, 4, 5}},
+		{sort: "price_asc", expected: []int{2, 1, 3, 4, 5}},
+		{sort: "price_desc", expected: []int{5, 4, 1, 3, 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sort, func(t *testing.T) {
+			setTestCars(t, cursorTestCars())
+			if ids := pageThrough(t, SearchRequest{Sort: tt.sort, Limit: 2}, nil); !reflect.DeepEqual(ids, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, ids)
+			}
+		})
+	}
+}
+
+func TestSearchCarsCursorSurvivesReload(t *testing.T) {
+	setTestCars(t, cursorTestCars())
+
+	reloaded := false
+	ids := pageThrough(t, SearchRequest{Sort: "price_asc", Limit: 2}, func() {
+		if reloaded {
+			return
+		}
+		reloaded = true
+		// A cheaper car appears and car 3 is sold between the first and second page
+		cars := append(cursorTestCars(), Car{ID: 6, Title: "Fiat Panda", PriceCHF: 9000, Status: StatusAvailable})
+		cars[2].Status = StatusSold
+		setTestCars(t, cars)
+	})
+
+	// Offsets would repeat car 1 on the second page; the cursor continues after it
+	expected := []int{2, 1, 4, 5}
+	if !reflect.DeepEqual(ids, expected) {
+		t.Errorf("Expected %v, got %v", expected, ids)
+	}
+}
+
+func TestSearchCarsOffsetModeUnchanged(t *testing.T) {
+	setTestCars(t, cursorTestCars())
+
+	response := searchCars(SearchRequest{Limit: 2, Offset: 2})
+	if response.Offset != 2 || len(response.Cars) != 2 || response.Cars[0].ID != 3 {
+		t.Errorf("Unexpected offset page: %+v", response)
+	}
+	if response.NextCursor == "" {
+		t.Error("Expected next_cursor on offset pages with more results")
+	}
+
+	if last := searchCars(SearchRequest{Limit: 2, Offset: 4}); last.NextCursor != "" {
+		t.Errorf("Expected no next_cursor on the last page, got %q", last.NextCursor)
+	}
+}
+
+func TestValidateSearchCursor(t *testing.T) {
+	setTestCars(t, cursorTestCars())
+	first := searchCars(SearchRequest{Brand: "BMW", Limit: 1})
+
+	tests := []struct {
+		name  string
+		req   SearchRequest
+		field string
+	}{
+		{name: "Valid", req: SearchRequest{Brand: "BMW", Cursor: first.NextCursor}},
+		{name: "Other limit", req: SearchRequest{Brand: "BMW", Limit: 5, Cursor: first.NextCursor}},
+		{name: "Other fields", req: SearchRequest{Brand: "BMW", Fields: []string{"card"}, Cursor: first.NextCursor}},
+		{name: "Unknown sort", req: SearchRequest{Sort: "colour"}, field: "sort"},
+		{name: "Other filters", req: SearchRequest{Brand: "Audi", Cursor: first.NextCursor}, field: "cursor"},
+		{name: "Other sort", req: SearchRequest{Brand: "BMW", Sort: "price_asc", Cursor: first.NextCursor}, field: "cursor"},
+		{name: "Cursor and offset", req: SearchRequest{Brand: "BMW", Offset: 1, Cursor: first.NextCursor}, field: "cursor"},
+		{name: "Garbage", req: SearchRequest{Cursor: "abc.def"}, field: "cursor"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errors := validateSearchRequest(&tt.req)
+			if tt.field == "" {
+				if len(errors) > 0 {
+					t.Errorf("Expected no errors, got %v", errors)
+				}
+				return
+			}
+			if len(errors) != 1 || errors[0].Field != tt.field {
+				t.Errorf("Expected error on %s, got %v", tt.field, errors)
+			}
+		})
+	}
+}
+
+func TestHandleSearchCursor(t *testing.T) {
+	setTestCars(t, cursorTestCars())
+
+	search := func(body string) SearchResponse {
+		response, err := handleRequest(context.Background(), events.APIGatewayProxyRequest{
+			Resource:   "/search",
+			HTTPMethod: "POST",
+			Body:       body,
+		})
+		if err != nil || response.StatusCode != http.StatusOK {
+			t.Fatalf("Unexpected response %d (%v): %s", response.StatusCode, err, response.Body)
+		}
+		var result SearchResponse
+		if err := json.Unmarshal([]byte(response.Body), &result); err != nil {
+			t.Fatalf("Invalid response body: %v", err)
+		}
+		return result
+	}
+
+	first := search(`{"brand": "BMW", "sort": "price_desc", "limit": 2}`)
+	if len(first.Cars) != 2 || first.NextCursor == "" {
+		t.Fatalf("Expected first page with next_cursor, got %+v", first)
+	}
+
+	second := search(`{"brand": "BMW", "sort": "price_desc", "limit": 2, "cursor": "` + first.NextCursor + `"}`)
+	if len(second.Cars) != 1 || second.Cars[0].ID != 3 || second.NextCursor != "" || second.Total != 3 {
+		t.Errorf("Unexpected second page: %+v", second)
+	}
+}
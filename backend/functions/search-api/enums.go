@@ -0,0 +1,117 @@
+package main
+
+// enumValue is a canonical value of a car attribute. Code is stable and used
+// by the filters, Label is the German display value, and Synonyms are the
+// other spellings found in sheets and marketplace exports.
+type enumValue struct {
+	Code     string
+	Label    string
+	Synonyms []string
+}
+
+// enumVocabulary is the set of canonical values of one attribute
+type enumVocabulary struct {
+	values []enumValue
+	index  map[string]enumValue
+}
+
+// newEnumVocabulary indexes the values by normalized code, label and synonym
+func newEnumVocabulary(values ...enumValue) *enumVocabulary {
+	vocabulary := &enumVocabulary{values: values, index: make(map[string]enumValue)}
+	for _, value := range values {
+		for _, name := range append([]string{value.Code, value.Label}, value.Synonyms...) {
+			vocabulary.index[lookupKey(name)] = value
+		}
+	}
+	return vocabulary
+}
+
+// Lookup returns the canonical value for a code, label or synonym
+func (v *enumVocabulary) Lookup(name string) (enumValue, bool) {
+	value, ok := v.index[lookupKey(name)]
+	return value, ok
+}
+
+// Canonical returns the label and code of a CSV value. Unknown values are
+// kept as written and have no code.
+func (v *enumVocabulary) Canonical(name string) (string, string) {
+	if value, ok := v.Lookup(name); ok {
+		return value.Label, value.Code
+	}
+	return name, ""
+}
+
+// FilterValue normalizes a filter value to its code. Unknown values are kept,
+// so filters on values outside the vocabulary still match by label.
+func (v *enumVocabulary) FilterValue(name string) string {
+	if value, ok := v.Lookup(name); ok {
+		return value.Code
+	}
+	return name
+}
+
+// Matches reports whether a car value matches a filter given as code, label
+// or synonym. Cars without a code are matched by their label.
+func (v *enumVocabulary) Matches(code, label, filter string) bool {
+	if code == "" {
+		code = v.FilterValue(label)
+	}
+	if code == filter {
+		return true
+	}
+	return lookupKey(code) == lookupKey(v.FilterValue(filter))
+}
+
+// Canonical car types
+var carTypeVocabulary = newEnumVocabulary(
+	enumValue{"sedan", "Limousine", []string{"Sedan", "Saloon", "Berline"}},
+	enumValue{"estate", "Kombi", []string{"Estate", "Station Wagon", "Wagon", "Touring", "Break"}},
+	enumValue{"suv", "SUV", []string{"Geländewagen", "Offroader", "Offroad", "Crossover"}},
+	enumValue{"coupe", "Coupé", []string{"Coupe"}},
+	enumValue{"convertible", "Cabriolet", []string{"Cabrio", "Convertible", "Roadster"}},
+	enumValue{"small-car", "Kleinwagen", []string{"Small Car", "Compact"}},
+	enumValue{"van", "Van", []string{"Minivan", "Kleinbus", "MPV"}},
+	enumValue{"pickup", "Pick-up", []string{"Pickup"}},
+)
+
+// Canonical transmissions
+var transmissionVocabulary = newEnumVocabulary(
+	enumValue{"automatic", "Automatik", []string{"Automatic", "Automat", "Automatikgetriebe", "Halbautomatik", "Semi-automatic"}},
+	enumValue{"manual", "Manuell", []string{"Manual", "Schaltgetriebe", "Handschaltung"}},
+)
+
+// Canonical fuels
+var fuelVocabulary = newEnumVocabulary(
+	enumValue{"diesel", "Diesel", nil},
+	enumValue{"petrol", "Benzin", []string{"Petrol", "Gasoline", "Benziner"}},
+	enumValue{"electric", "Elektro", []string{"Electric", "Elektrisch", "EV"}},
+	enumValue{"hybrid", "Hybrid", []string{"Plug-in-Hybrid", "Plugin-Hybrid", "Vollhybrid", "Mild-Hybrid", "Hybrid-Benzin", "Hybrid-Petrol", "Hybrid-Diesel"}},
+	enumValue{"natural-gas", "Erdgas", []string{"CNG", "Natural Gas"}},
+	enumValue{"lpg", "Autogas", []string{"LPG"}},
+	enumValue{"hydrogen", "Wasserstoff", []string{"Hydrogen"}},
+)
+
+// Canonical drives
+var driveVocabulary = newEnumVocabulary(
+	enumValue{"all-wheel", "Allrad", []string{"All-wheel", "AWD", "4x4", "4WD", "Allradantrieb", "Quattro", "xDrive", "4Motion"}},
+	enumValue{"front", "Front", []string{"Frontantrieb", "Vorderrad", "FWD"}},
+	enumValue{"rear", "Hinterrad", []string{"Hinterradantrieb", "Heckantrieb", "Rear", "RWD"}},
+)
+
+// EnumOption is a filterable value of a car attribute
+type EnumOption struct {
+	Code  string `json:"code,omitempty"`
+	Label string `json:"label"`
+}
+
+// enumOptions lists the labels of options with their codes, in the order of the labels
+func enumOptions(vocabulary *enumVocabulary, labels []string) []EnumOption {
+	options := make([]EnumOption, len(labels))
+	for i, label := range labels {
+		options[i] = EnumOption{Label: label}
+		if value, ok := vocabulary.Lookup(label); ok {
+			options[i].Code = value.Code
+		}
+	}
+	return options
+}
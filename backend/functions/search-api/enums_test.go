@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+var enumVocabularies = map[string]*enumVocabulary{
+	"car_type":     carTypeVocabulary,
+	"transmission": transmissionVocabulary,
+	"fuel":         fuelVocabulary,
+	"drive":        driveVocabulary,
+}
+
+func TestEnumVocabulariesAreUnambiguous(t *testing.T) {
+	for attribute, vocabulary := range enumVocabularies {
+		owners := make(map[string]string)
+		for _, value := range vocabulary.values {
+			for _, name := range append([]string{value.Code, value.Label}, value.Synonyms...) {
+				key := lookupKey(name)
+				if owner, ok := owners[key]; ok && owner != value.Code {
+					t.Errorf("%s: %q maps to both %s and %s", attribute, name, owner, value.Code)
+				}
+				owners[key] = value.Code
+			}
+		}
+	}
+}
+
+func TestEnumVocabularyCanonical(t *testing.T) {
+	tests := []struct {
+		vocabulary *enumVocabulary
+		value      string
+		label      string
+		code       string
+	}{
+		{fuelVocabulary, "Diesel", "Diesel", "diesel"},
+		{fuelVocabulary, "diesel", "Diesel", "diesel"},
+		{fuelVocabulary, "Petrol", "Benzin", "petrol"},
+		{fuelVocabulary, "Plug-in Hybrid", "Hybrid", "hybrid"},
+		{transmissionVocabulary, "automatic", "Automatik", "automatic"},
+		{transmissionVocabulary, "Schaltgetriebe", "Manuell", "manual"},
+		{carTypeVocabulary, "coupe", "Coupé", "coupe"},
+		{carTypeVocabulary, "Station-Wagon", "Kombi", "estate"},
+		{driveVocabulary, "4x4", "Allrad", "all-wheel"},
+		{fuelVocabulary, "Wankel", "Wankel", ""},
+	}
+
+	for _, tt := range tests {
+		label, code := tt.vocabulary.Canonical(tt.value)
+		if label != tt.label || code != tt.code {
+			t.Errorf("Canonical(%q): expected %q/%q, got %q/%q", tt.value, tt.label, tt.code, label, code)
+		}
+	}
+}
+
+func TestEnumVocabularyMatches(t *testing.T) {
+	tests := []struct {
+		name     string
+		code     string
+		label    string
+		filter   string
+		expected bool
+	}{
+		{name: "Code", code: "diesel", label: "Diesel", filter: "diesel", expected: true},
+		{name: "Old label", code: "diesel", label: "Diesel", filter: "Diesel", expected: true},
+		{name: "Synonym", code: "petrol", label: "Benzin", filter: "Gasoline", expected: true},
+		{name: "Other value", code: "petrol", label: "Benzin", filter: "diesel", expected: false},
+		{name: "Car without code", label: "Benzin", filter: "petrol", expected: true},
+		{name: "Unknown value", label: "Wankel", filter: "wankel", expected: true},
+		{name: "Unknown filter", code: "petrol", label: "Benzin", filter: "Wankel", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := fuelVocabulary.Matches(tt.code, tt.label, tt.filter); result != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestHandleSearchEnumCodes(t *testing.T) {
+	setTestCars(t, []Car{
+		{ID: 1, Title: "BMW 520d", CarType: "Limousine", CarTypeCode: "sedan", Fuel: "Diesel", FuelCode: "diesel", Transmission: "Automatik", TransmissionCode: "automatic", Status: StatusAvailable},
+		{ID: 2, Title: "VW Golf", CarType: "Kleinwagen", CarTypeCode: "small-car", Fuel: "Benzin", FuelCode: "petrol", Transmission: "Manuell", TransmissionCode: "manual", Status: StatusAvailable},
+	})
+
+	search := func(query map[string]string) []Car {
+		response, err := handleRequest(context.Background(), events.APIGatewayProxyRequest{
+			HTTPMethod:            "GET",
+			Resource:              "/search",
+			QueryStringParameters: query,
+		})
+		if err != nil || response.StatusCode != 200 {
+			t.Fatalf("Unexpected response %d (%v): %s", response.StatusCode, err, response.Body)
+		}
+		var result SearchResponse
+		if err := json.Unmarshal([]byte(response.Body), &result); err != nil {
+			t.Fatalf("Invalid response body: %v", err)
+		}
+		return result.Cars
+	}
+
+	for _, query := range []map[string]string{{"fuel": "diesel"}, {"fuel": "Diesel"}, {"transmission": "automatic"}, {"car_type": "Saloon"}} {
+		if cars := search(query); len(cars) != 1 || cars[0].ID != 1 || cars[0].FuelCode != "diesel" {
+			t.Errorf("%v: unexpected cars %+v", query, cars)
+		}
+	}
+	if cars := search(map[string]string{"fuel": "Petrol"}); len(cars) != 1 || cars[0].ID != 2 {
+		t.Errorf("Expected the petrol car, got %+v", cars)
+	}
+
+	options := getSearchOptions()
+	expected := []EnumOption{{Code: "petrol", Label: "Benzin"}, {Code: "diesel", Label: "Diesel"}}
+	if len(options.FuelOptions) != 2 || options.FuelOptions[0] != expected[0] || options.FuelOptions[1] != expected[1] {
+		t.Errorf("Expected fuel options %v, got %v", expected, options.FuelOptions)
+	}
+	if localized := localizeSearchOptions(options, "en"); localized.FuelOptions[0].Label != "Petrol" || options.FuelOptions[0].Label != "Benzin" {
+		t.Errorf("Unexpected localized fuel options %v", localized.FuelOptions)
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"sort"
+)
+
+// MaxEquipmentFilter bounds the number of features in the equipment filter
+const MaxEquipmentFilter = 20
+
+// equipmentFeature is a canonical equipment item. Code is what the equipment
+// filter uses, Label is shown to customers, and Synonyms are the other
+// spellings found in sheets and marketplace exports.
+type equipmentFeature struct {
+	Code     string
+	Label    string
+	Synonyms []string
+}
+
+// equipmentVocabulary lists the canonical equipment. Items that match none of
+// them are kept as written but cannot be filtered.
+var equipmentVocabulary = []equipmentFeature{
+	{"360-camera", "360° Kamera", []string{"360 Grad Kamera", "Surround View", "Area View"}},
+	{"adaptive-cruise-control", "Adaptiver Tempomat", []string{"ACC", "Abstandstempomat", "Adaptive Cruise Control", "Distronic"}},
+	{"ambient-lighting", "Ambientebeleuchtung", []string{"Ambientes Licht", "Ambiente Beleuchtung", "Ambient Light"}},
+	{"apple-carplay", "Apple CarPlay", []string{"CarPlay"}},
+	{"digital-cockpit", "Digital Cockpit", []string{"Digitales Cockpit", "Virtual Cockpit", "Live Cockpit"}},
+	{"heated-seats", "Sitzheizung", []string{"Beheizte Sitze", "Heated Seats"}},
+	{"keyless", "Keyless Entry", []string{"Keyless Go", "Keyless", "Komfortzugang"}},
+	{"lane-assist", "Spurhalteassistent", []string{"Spurassistent", "Spurhalteassistenz", "Lane Assist"}},
+	{"leather", "Leder", []string{"Ledersitze", "Lederausstattung", "Leather"}},
+	{"led-headlights", "LED Scheinwerfer", []string{"Voll-LED", "Matrix LED", "LED Headlights"}},
+	{"navigation", "Navigationssystem", []string{"Navi", "Navigation", "Navigationsgerät"}},
+	{"panoramic-roof", "Panoramadach", []string{"Glasdach", "Panorama-Glasdach", "Panoramic Roof"}},
+	{"parking-sensors", "Parksensoren", []string{"Parkpilot", "Park Distance Control", "PDC", "Einparkhilfe"}},
+	{"rear-camera", "Rückfahrkamera", []string{"Rear Camera", "Backup Camera"}},
+	{"sport-seats", "Sportsitze", []string{"Sportsitz"}},
+	{"trailer-hitch", "Anhängerkupplung", []string{"AHK"}},
+}
+
+// equipmentIndex finds a feature by the normalized code, label or synonym
+var equipmentIndex = func() map[string]equipmentFeature {
+	index := make(map[string]equipmentFeature)
+	for _, feature := range equipmentVocabulary {
+		for _, name := range append([]string{feature.Code, feature.Label}, feature.Synonyms...) {
+			index[lookupKey(name)] = feature
+		}
+	}
+	return index
+}()
+
+// EquipmentOption is a filterable equipment feature with the number of available cars that have it
+type EquipmentOption struct {
+	Code  string `json:"code"`
+	Label string `json:"label"`
+	Count int    `json:"count"`
+}
+
+// lookupEquipment returns the canonical feature for a code, label or synonym
+func lookupEquipment(name string) (equipmentFeature, bool) {
+	feature, ok := equipmentIndex[lookupKey(name)]
+	return feature, ok
+}
+
+// canonicalEquipment replaces known items by their canonical label, drops
+// duplicates and returns the codes of the known items
+func canonicalEquipment(items []string) ([]string, []string) {
+	equipment := make([]string, 0, len(items))
+	var codes []string
+	seen := make(map[string]bool)
+
+	for _, item := range items {
+		key := lookupKey(item)
+		if feature, ok := equipmentIndex[key]; ok {
+			if seen[feature.Code] {
+				continue
+			}
+			seen[feature.Code] = true
+			equipment = append(equipment, feature.Label)
+			codes = append(codes, feature.Code)
+			continue
+		}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		equipment = append(equipment, item)
+	}
+
+	return equipment, codes
+}
+
+// hasEquipment reports whether the car has all the given feature codes
+func hasEquipment(car Car, codes []string) bool {
+	for _, code := range codes {
+		found := false
+		for _, carCode := range car.EquipmentCodes {
+			if carCode == code {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// equipmentOptions lists the counted features, most common first
+func equipmentOptions(counts map[string]int) []EquipmentOption {
+	options := make([]EquipmentOption, 0, len(counts))
+	for _, feature := range equipmentVocabulary {
+		if count := counts[feature.Code]; count > 0 {
+			options = append(options, EquipmentOption{Code: feature.Code, Label: feature.Label, Count: count})
+		}
+	}
+
+	sort.SliceStable(options, func(i, j int) bool {
+		if options[i].Count != options[j].Count {
+			return options[i].Count > options[j].Count
+		}
+		return options[i].Label < options[j].Label
+	})
+	return options
+}
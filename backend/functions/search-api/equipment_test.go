@@ -0,0 +1,132 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEquipmentVocabularyIsUnambiguous(t *testing.T) {
+	owners := make(map[string]string)
+	for _, feature := range equipmentVocabulary {
+		for _, name := range append([]string{feature.Code, feature.Label}, feature.Synonyms...) {
+			key := lookupKey(name)
+			if owner, ok := owners[key]; ok && owner != feature.Code {
+				t.Errorf("%q maps to both %s and %s", name, owner, feature.Code)
+			}
+			owners[key] = feature.Code
+		}
+	}
+}
+
+func TestCanonicalEquipment(t *testing.T) {
+	tests := []struct {
+		name      string
+		items     []string
+		equipment []string
+		codes     []string
+	}{
+		{
+			name:      "Synonyms get the canonical label",
+			items:     []string{"Ambientes Licht", "Navi", "Glasdach", "360 Kamera"},
+			equipment: []string{"Ambientebeleuchtung", "Navigationssystem", "Panoramadach", "360° Kamera"},
+			codes:     []string{"ambient-lighting", "navigation", "panoramic-roof", "360-camera"},
+		},
+		{
+			name:      "Case, hyphens and diacritics are ignored",
+			items:     []string{"LED-Scheinwerfer", "rueckfahrkamera", "RÜCKFAHRKAMERA"},
+			equipment: []string{"LED Scheinwerfer", "Rückfahrkamera"},
+			codes:     []string{"led-headlights", "rear-camera"},
+		},
+		{
+			name:      "Unknown items are kept once",
+			items:     []string{"Mild-Hybrid", "Sitzheizung", "mild hybrid"},
+			equipment: []string{"Mild-Hybrid", "Sitzheizung"},
+			codes:     []string{"heated-seats"},
+		},
+		{
+			name:      "Marketplace codes",
+			items:     []string{"heated-seats", "trailer-hitch"},
+			equipment: []string{"Sitzheizung", "Anhängerkupplung"},
+			codes:     []string{"heated-seats", "trailer-hitch"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			equipment, codes := canonicalEquipment(tt.items)
+			if !reflect.DeepEqual(equipment, tt.equipment) {
+				t.Errorf("Expected equipment %v, got %v", tt.equipment, equipment)
+			}
+			if !reflect.DeepEqual(codes, tt.codes) {
+				t.Errorf("Expected codes %v, got %v", tt.codes, codes)
+			}
+		})
+	}
+}
+
+func TestSearchCarsEquipmentFilter(t *testing.T) {
+	setTestCars(t, []Car{
+		{ID: 1, Title: "BMW 520d", EquipmentCodes: []string{"rear-camera", "heated-seats", "navigation"}, Status: StatusAvailable},
+		{ID: 2, Title: "Audi A4", EquipmentCodes: []string{"rear-camera", "led-headlights"}, Status: StatusAvailable},
+		{ID: 3, Title: "Fiat Panda", Status: StatusAvailable},
+	})
+
+	tests := []struct {
+		name      string
+		equipment []string
+		expected  []int
+	}{
+		{name: "One feature", equipment: []string{"rear-camera"}, expected: []int{1, 2}},
+		{name: "All features required", equipment: []string{"rear-camera", "heated-seats"}, expected: []int{1}},
+		{name: "Labels and synonyms", equipment: []string{"Rückfahrkamera", "Navi"}, expected: []int{1}},
+		{name: "Nobody has it", equipment: []string{"trailer-hitch"}, expected: []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := SearchRequest{Equipment: tt.equipment}
+			if errors := validateSearchRequest(&req); len(errors) > 0 {
+				t.Fatalf("Unexpected validation errors: %v", errors)
+			}
+
+			ids := []int{}
+			for _, car := range searchCars(req).Cars {
+				ids = append(ids, car.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.expected) {
+				t.Errorf("Expected cars %v, got %v", tt.expected, ids)
+			}
+		})
+	}
+}
+
+func TestValidateSearchRequestEquipment(t *testing.T) {
+	req := SearchRequest{Equipment: []string{"Navi", "Schleudersitz"}}
+	errors := validateSearchRequest(&req)
+	if len(errors) != 1 || errors[0].Field != "equipment" {
+		t.Errorf("Expected one equipment error, got %v", errors)
+	}
+
+	req = SearchRequest{Equipment: make([]string, MaxEquipmentFilter+1)}
+	if errors := validateSearchRequest(&req); len(errors) != 1 {
+		t.Errorf("Expected error for too many features, got %v", errors)
+	}
+}
+
+func TestGetSearchOptionsEquipment(t *testing.T) {
+	setTestCars(t, []Car{
+		{EquipmentCodes: []string{"rear-camera", "heated-seats"}, Status: StatusAvailable},
+		{EquipmentCodes: []string{"rear-camera", "navigation"}, Status: StatusAvailable},
+		{EquipmentCodes: []string{"heated-seats"}, Status: StatusAvailable},
+		{EquipmentCodes: []string{"trailer-hitch"}, Status: StatusSold},
+	})
+
+	expected := []EquipmentOption{
+		{Code: "rear-camera", Label: "Rückfahrkamera", Count: 2},
+		{Code: "heated-seats", Label: "Sitzheizung", Count: 2},
+		{Code: "navigation", Label: "Navigationssystem", Count: 1},
+	}
+	if options := getSearchOptions(); !reflect.DeepEqual(options.Equipment, expected) {
+		t.Errorf("Expected equipment options %v, got %v", expected, options.Equipment)
+	}
+}
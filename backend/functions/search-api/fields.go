@@ -0,0 +1,201 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// carFieldNames are the JSON names of all Car attributes
+var carFieldNames = func() []string {
+	var names []string
+	carType := reflect.TypeOf(Car{})
+	for i := 0; i < carType.NumField(); i++ {
+		name, _, _ := strings.Cut(carType.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			names = append(names, name)
+		}
+	}
+	return names
+}()
+
+// carProjection selects the attributes of the cars in a search response
+type carProjection struct {
+	fields    map[string]bool
+	maxImages int // 0 keeps all images
+}
+
+// fieldProjection is a predefined set of fields that can be requested by name
+type fieldProjection struct {
+	Fields    []string
+	MaxImages int
+}
+
+// fieldProjections are the named projections accepted in SearchRequest.Fields
+var fieldProjections = map[string]fieldProjection{
+	// Car cards on the landing page: title, price, key specs and the first image
+	"card": {
+		Fields: []string{
+			"title", "brand", "model", "price_chf", "leasing_rate_chf", "first_registration_year",
+			"mileage_km", "transmission", "fuel", "power_hp", "image_urls", "status",
+		},
+		MaxImages: 1,
+	},
+	// The detail view shows everything
+	"detail": {Fields: carFieldNames},
+}
+
+// isCarField reports whether name is the JSON name of a Car attribute
+func isCarField(name string) bool {
+	for _, field := range carFieldNames {
+		if field == name {
+			return true
+		}
+	}
+	return false
+}
+
+// validateFields checks the fields of a search request and normalizes them to
+// a sorted list without duplicates
+func validateFields(req *SearchRequest) []ValidationError {
+	if len(req.Fields) == 0 {
+		return nil
+	}
+
+	var errors []ValidationError
+	seen := make(map[string]bool)
+	var fields []string
+	for _, field := range req.Fields {
+		field = strings.ToLower(strings.TrimSpace(field))
+		if _, ok := fieldProjections[field]; !ok && !isCarField(field) {
+			errors = append(errors, newValidationError("fields", msgUnknownField, field))
+			continue
+		}
+		if !seen[field] {
+			seen[field] = true
+			fields = append(fields, field)
+		}
+	}
+
+	sort.Strings(fields)
+	req.Fields = fields
+	return errors
+}
+
+// newCarProjection resolves validated fields and projections. The ID is
+// always included, as cursors and detail links depend on it. Images are only
+// limited if every source of image_urls limits them.
+func newCarProjection(names []string) *carProjection {
+	if len(names) == 0 {
+		return nil
+	}
+
+	projection := &carProjection{fields: map[string]bool{"id": true}}
+	allImages := false
+	for _, name := range names {
+		named, ok := fieldProjections[name]
+		if !ok {
+			projection.fields[name] = true
+			allImages = allImages || name == "image_urls"
+			continue
+		}
+		for _, field := range named.Fields {
+			projection.fields[field] = true
+			if field == "image_urls" {
+				allImages = allImages || named.MaxImages == 0
+				projection.maxImages = max(projection.maxImages, named.MaxImages)
+			}
+		}
+	}
+
+	if allImages {
+		projection.maxImages = 0
+	}
+	return projection
+}
+
+// project returns the selected attributes of a car
+func (p *carProjection) project(car Car) (map[string]json.RawMessage, error) {
+	if p.maxImages > 0 && len(car.ImageURLs) > p.maxImages {
+		car.ImageURLs = car.ImageURLs[:p.maxImages]
+	}
+
+	attributes, err := carAttributes(car)
+	if err != nil {
+		return nil, err
+	}
+	for name := range attributes {
+		if !p.fields[name] {
+			delete(attributes, name)
+		}
+	}
+	return attributes, nil
+}
+
+// carAttributes returns the serialized attributes of a car by JSON name
+func carAttributes(car Car) (map[string]json.RawMessage, error) {
+	encoded, err := json.Marshal(car)
+	if err != nil {
+		return nil, err
+	}
+	var attributes map[string]json.RawMessage
+	if err := json.Unmarshal(encoded, &attributes); err != nil {
+		return nil, err
+	}
+	return attributes, nil
+}
+
+// serializeCar returns the attributes of a car in a search response. With a
+// language the equipment is translated and the translated enumerations are
+// added as "labels", limited to the selected attributes.
+func (r SearchResponse) serializeCar(car Car) (map[string]json.RawMessage, error) {
+	if r.lang != "" {
+		car.Equipment = localizeEquipment(r.lang, car.Equipment)
+	}
+
+	var attributes map[string]json.RawMessage
+	var err error
+	if r.projection != nil {
+		attributes, err = r.projection.project(car)
+	} else {
+		attributes, err = carAttributes(car)
+	}
+	if err != nil || r.lang == "" {
+		return attributes, err
+	}
+
+	labels := carLabels(r.lang, car)
+	for name := range labels {
+		if _, ok := attributes[name]; !ok {
+			delete(labels, name)
+		}
+	}
+	if attributes["labels"], err = json.Marshal(labels); err != nil {
+		return nil, err
+	}
+	return attributes, nil
+}
+
+// MarshalJSON serializes the response, limited to the requested fields and
+// with the labels of the requested language, if any
+func (r SearchResponse) MarshalJSON() ([]byte, error) {
+	type plainResponse SearchResponse
+	if r.projection == nil && r.lang == "" {
+		return json.Marshal(plainResponse(r))
+	}
+
+	cars := make([]map[string]json.RawMessage, 0, len(r.Cars))
+	for _, car := range r.Cars {
+		attributes, err := r.serializeCar(car)
+		if err != nil {
+			return nil, err
+		}
+		cars = append(cars, attributes)
+	}
+
+	return json.Marshal(struct {
+		plainResponse
+		Cars []map[string]json.RawMessage `json:"cars"`
+	}{plainResponse(r), cars})
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func TestValidateFields(t *testing.T) {
+	req := SearchRequest{Fields: []string{"Title", "card", " price_chf", "title"}}
+	if errors := validateFields(&req); len(errors) > 0 {
+		t.Fatalf("Unexpected errors: %v", errors)
+	}
+	if expected := []string{"card", "price_chf", "title"}; !reflect.DeepEqual(req.Fields, expected) {
+		t.Errorf("Expected %v, got %v", expected, req.Fields)
+	}
+
+	req = SearchRequest{Fields: []string{"title", "owner", "Description"}}
+	errors := validateFields(&req)
+	if len(errors) != 1 || errors[0].Field != "fields" || errors[0].Code != msgUnknownField || !reflect.DeepEqual(errors[0].Params, []interface{}{"owner"}) {
+		t.Errorf("Expected error for unknown field, got %v", errors)
+	}
+}
+
+func TestNewCarProjection(t *testing.T) {
+	tests := []struct {
+		names     []string
+		maxImages int
+	}{
+		{names: []string{"card"}, maxImages: 1},
+		{names: []string{"card", "image_urls"}, maxImages: 0},
+		{names: []string{"card", "detail"}, maxImages: 0},
+		{names: []string{"title"}, maxImages: 0},
+	}
+
+	for _, tt := range tests {
+		projection := newCarProjection(tt.names)
+		if projection.maxImages != tt.maxImages || !projection.fields["id"] {
+			t.Errorf("%v: expected %d images and the ID, got %+v", tt.names, tt.maxImages, projection)
+		}
+	}
+
+	if newCarProjection(nil) != nil {
+		t.Error("Expected no projection without fields")
+	}
+}
+
+func TestHandleSearchFields(t *testing.T) {
+	setTestCars(t, []Car{{
+		ID:          1,
+		Title:       "BMW 520d",
+		Brand:       "BMW",
+		PriceCHF:    42890,
+		MileageKM:   55000,
+		Description: "Top gepflegt",
+		Equipment:   []string{"Sitzheizung"},
+		ImageURLs:   []string{"https://img.example.com/1.jpg", "https://img.example.com/2.jpg"},
+		Status:      StatusAvailable,
+	}})
+
+	search := func(request events.APIGatewayProxyRequest) map[string]json.RawMessage {
+		request.Resource = "/search"
+		response, _ := handleRequest(context.Background(), request)
+		if response.StatusCode != 200 {
+			t.Fatalf("Unexpected response %d: %s", response.StatusCode, response.Body)
+		}
+		var result struct {
+			Cars  []map[string]json.RawMessage `json:"cars"`
+			Total int                          `json:"total"`
+		}
+		if err := json.Unmarshal([]byte(response.Body), &result); err != nil || len(result.Cars) != 1 || result.Total != 1 {
+			t.Fatalf("Unexpected body %s", response.Body)
+		}
+		return result.Cars[0]
+	}
+	keys := func(attributes map[string]json.RawMessage) []string {
+		var names []string
+		for name := range attributes {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return names
+	}
+
+	selected := search(events.APIGatewayProxyRequest{HTTPMethod: "POST", Body: `{"fields": ["title", "price_chf"]}`})
+	if expected := []string{"id", "price_chf", "title"}; !reflect.DeepEqual(keys(selected), expected) {
+		t.Errorf("Expected %v, got %v", expected, keys(selected))
+	}
+
+	card := search(events.APIGatewayProxyRequest{HTTPMethod: "GET", QueryStringParameters: map[string]string{"fields": "card"}})
+	if _, ok := card["description"]; ok {
+		t.Error("Expected card without description")
+	}
+	if string(card["image_urls"]) != `["https://img.example.com/1.jpg"]` || string(card["mileage_km"]) != "55000" {
+		t.Errorf("Unexpected card %v", keys(card))
+	}
+
+	detail := search(events.APIGatewayProxyRequest{HTTPMethod: "GET", QueryStringParameters: map[string]string{"fields": "detail"}})
+	full := search(events.APIGatewayProxyRequest{HTTPMethod: "GET"})
+	if !reflect.DeepEqual(detail, full) {
+		t.Errorf("Expected the detail projection to match the full car, got %v", keys(detail))
+	}
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"os"
+	"strconv"
+)
+
+// Limits for POST /financing/quote
+const (
+	MinFinancingTerm   = 6
+	MaxFinancingTerm   = 120
+	MaxYearlyMileageKM = 100000
+)
+
+// Rappen is an amount of money in Rappen (1/100 CHF). It is encoded as a
+// JSON number in CHF with exactly two decimals.
+type Rappen int64
+
+// MarshalJSON encodes the amount in CHF without going through a float
+func (r Rappen) MarshalJSON() ([]byte, error) {
+	sign := ""
+	value := int64(r)
+	if value < 0 {
+		sign = "-"
+		value = -value
+	}
+	return []byte(fmt.Sprintf("%s%d.%02d", sign, value/100, value%100)), nil
+}
+
+// chf converts whole francs to Rappen
+func chf(francs int) Rappen {
+	return Rappen(francs) * 100
+}
+
+// FinancingConfig holds the interest parameters of financing quotes
+type FinancingConfig struct {
+	// InterestRate is the nominal yearly interest in percent, e.g. "3.9"
+	InterestRate string
+	// IncludedMileageKM is the yearly mileage covered by the base rate
+	IncludedMileageKM int
+	// ExcessMileageRappen is charged per kilometre and year above IncludedMileageKM
+	ExcessMileageRappen int
+}
+
+// financing is the configuration used by POST /financing/quote
+var financing = defaultFinancingConfig()
+
+// defaultFinancingConfig returns the parameters used when no environment is configured
+func defaultFinancingConfig() FinancingConfig {
+	return FinancingConfig{
+		InterestRate:        "3.9",
+		IncludedMileageKM:   10000,
+		ExcessMileageRappen: 5,
+	}
+}
+
+// financingConfigFromEnv reads FINANCING_INTEREST_RATE,
+// FINANCING_INCLUDED_MILEAGE_KM and FINANCING_EXCESS_MILEAGE_RAPPEN,
+// keeping the defaults for unset variables
+func financingConfigFromEnv() (FinancingConfig, error) {
+	config := defaultFinancingConfig()
+
+	if value := os.Getenv("FINANCING_INTEREST_RATE"); value != "" {
+		rate, ok := new(big.Rat).SetString(value)
+		if !ok || rate.Sign() < 0 {
+			return config, fmt.Errorf("invalid FINANCING_INTEREST_RATE %q", value)
+		}
+		config.InterestRate = value
+	}
+
+	integers := []struct {
+		name  string
+		value *int
+	}{
+		{"FINANCING_INCLUDED_MILEAGE_KM", &config.IncludedMileageKM},
+		{"FINANCING_EXCESS_MILEAGE_RAPPEN", &config.ExcessMileageRappen},
+	}
+	for _, i := range integers {
+		value := os.Getenv(i.name)
+		if value == "" {
+			continue
+		}
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			return config, fmt.Errorf("invalid %s %q", i.name, value)
+		}
+		*i.value = parsed
+	}
+
+	return config, nil
+}
+
+// FinancingRequest asks for a quote for a catalogue car or a given price
+type FinancingRequest struct {
+	CarID            int `json:"car_id,omitempty"`
+	PriceCHF         int `json:"price_chf,omitempty"`
+	DownPaymentCHF   int `json:"down_payment_chf"`
+	ResidualValueCHF int `json:"residual_value_chf"`
+	TermMonths       int `json:"term_months"`
+	YearlyMileageKM  int `json:"yearly_mileage_km,omitempty"`
+}
+
+// FinancingQuote is the result of POST /financing/quote. MonthlyRateCHF
+// includes MileageSurchargeCHF; TotalInterestCHF excludes it.
+type FinancingQuote struct {
+	CarID               int    `json:"car_id,omitempty"`
+	PriceCHF            Rappen `json:"price_chf"`
+	DownPaymentCHF      Rappen `json:"down_payment_chf"`
+	ResidualValueCHF    Rappen `json:"residual_value_chf"`
+	FinancedAmountCHF   Rappen `json:"financed_amount_chf"`
+	TermMonths          int    `json:"term_months"`
+	YearlyMileageKM     int    `json:"yearly_mileage_km"`
+	InterestRate        string `json:"interest_rate"`
+	MileageSurchargeCHF Rappen `json:"mileage_surcharge_chf"`
+	MonthlyRateCHF      Rappen `json:"monthly_rate_chf"`
+	TotalInterestCHF    Rappen `json:"total_interest_chf"`
+	TotalCostCHF        Rappen `json:"total_cost_chf"`
+}
+
+// validateFinancingRequest checks the request once the price is known
+func validateFinancingRequest(req FinancingRequest, price int) []ValidationError {
+	var errors []ValidationError
+
+	if req.CarID != 0 && req.PriceCHF != 0 {
+		errors = append(errors, newValidationError("price_chf", msgCarOrPrice))
+	} else if !validateIntRange(price, 1, MaxPrice) {
+		errors = append(errors, newValidationError("price_chf", msgFinancingPriceRange, MaxPrice))
+	}
+
+	if !validateIntRange(req.TermMonths, MinFinancingTerm, MaxFinancingTerm) {
+		errors = append(errors, newValidationError("term_months", msgTermRange, MinFinancingTerm, MaxFinancingTerm))
+	}
+
+	if !validateIntRange(req.YearlyMileageKM, 0, MaxYearlyMileageKM) {
+		errors = append(errors, newValidationError("yearly_mileage_km", msgYearlyMileageRange, MaxYearlyMileageKM))
+	}
+
+	// The down payment and residual value can only be checked against a valid price
+	if len(errors) > 0 && errors[0].Field == "price_chf" {
+		return errors
+	}
+	if req.DownPaymentCHF < 0 || req.DownPaymentCHF >= price {
+		errors = append(errors, newValidationError("down_payment_chf", msgDownPaymentRange))
+	} else if req.ResidualValueCHF < 0 || req.ResidualValueCHF >= price-req.DownPaymentCHF {
+		errors = append(errors, newValidationError("residual_value_chf", msgResidualValueRange))
+	}
+
+	return errors
+}
+
+// quoteFinancing calculates the quote for a validated request. The monthly
+// rate is an annuity paid at the end of each month that leaves the residual
+// value as final payment; it is calculated exactly and rounded to the Rappen.
+func quoteFinancing(req FinancingRequest, price int, config FinancingConfig) FinancingQuote {
+	mileage := req.YearlyMileageKM
+	if mileage == 0 {
+		mileage = config.IncludedMileageKM
+	}
+
+	financed := chf(price - req.DownPaymentCHF)
+	residual := chf(req.ResidualValueCHF)
+	annuity := monthlyAnnuity(financed, residual, req.TermMonths, config.InterestRate)
+
+	// Kilometres above the included mileage are spread over the twelve monthly rates
+	surcharge := Rappen(0)
+	if excess := mileage - config.IncludedMileageKM; excess > 0 {
+		surcharge = roundRappen(new(big.Rat).SetFrac64(int64(excess)*int64(config.ExcessMileageRappen), 12))
+	}
+
+	monthly := annuity + surcharge
+	totalCost := chf(req.DownPaymentCHF) + monthly*Rappen(req.TermMonths) + residual
+
+	return FinancingQuote{
+		CarID:               req.CarID,
+		PriceCHF:            chf(price),
+		DownPaymentCHF:      chf(req.DownPaymentCHF),
+		ResidualValueCHF:    residual,
+		FinancedAmountCHF:   financed,
+		TermMonths:          req.TermMonths,
+		YearlyMileageKM:     mileage,
+		InterestRate:        config.InterestRate,
+		MileageSurchargeCHF: surcharge,
+		MonthlyRateCHF:      monthly,
+		TotalInterestCHF:    annuity*Rappen(req.TermMonths) + residual - financed,
+		TotalCostCHF:        totalCost,
+	}
+}
+
+// monthlyAnnuity returns the rate that pays off financed down to residual in
+// term months at the yearly interest rate in percent:
+//
+//	rate = (financed * f - residual) * r / (f - 1), r = yearly / 12 / 100, f = (1 + r)^term
+func monthlyAnnuity(financed, residual Rappen, term int, interestRate string) Rappen {
+	r, ok := new(big.Rat).SetString(interestRate)
+	if !ok {
+		r = new(big.Rat)
+	}
+	r.Quo(r, big.NewRat(1200, 1))
+
+	if r.Sign() == 0 {
+		return roundRappen(big.NewRat(int64(financed-residual), int64(term)))
+	}
+
+	growth := new(big.Rat).Add(big.NewRat(1, 1), r)
+	f := big.NewRat(1, 1)
+	for i := 0; i < term; i++ {
+		f.Mul(f, growth)
+	}
+
+	rate := new(big.Rat).Mul(big.NewRat(int64(financed), 1), f)
+	rate.Sub(rate, big.NewRat(int64(residual), 1))
+	rate.Mul(rate, r)
+	rate.Quo(rate, new(big.Rat).Sub(f, big.NewRat(1, 1)))
+	return roundRappen(rate)
+}
+
+// roundRappen rounds an amount in Rappen half away from zero
+func roundRappen(amount *big.Rat) Rappen {
+	num := new(big.Int).Abs(amount.Num())
+	den := amount.Denom()
+
+	// (2 * |num| + den) / (2 * den), truncated
+	rounded := new(big.Int).Mul(num, big.NewInt(2))
+	rounded.Add(rounded, den)
+	rounded.Quo(rounded, new(big.Int).Mul(den, big.NewInt(2)))
+
+	if amount.Sign() < 0 {
+		rounded.Neg(rounded)
+	}
+	return Rappen(rounded.Int64())
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func TestMonthlyAnnuity(t *testing.T) {
+	tests := []struct {
+		name     string
+		financed int
+		residual int
+		term     int
+		rate     string
+		expected Rappen
+	}{
+		{name: "Plain loan", financed: 25000, term: 12, rate: "4.9", expected: 213904},
+		{name: "Leasing with residual value", financed: 42890, residual: 15000, term: 48, rate: "3.9", expected: 67723},
+		{name: "Long term", financed: 32890, term: 60, rate: "3.9", expected: 60424},
+		{name: "Fractional rate", financed: 53910, residual: 20000, term: 48, rate: "2.95", expected: 79899},
+		{name: "Zero interest", financed: 25000, residual: 10000, term: 36, rate: "0", expected: 41667},
+		{name: "Zero interest rounds half up", financed: 100, term: 8, rate: "0", expected: 1250},
+		{name: "Zero interest rounds down", financed: 100, term: 7, rate: "0", expected: 1429},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := monthlyAnnuity(chf(tt.financed), chf(tt.residual), tt.term, tt.rate); result != tt.expected {
+				t.Errorf("Expected %d Rappen, got %d", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestRoundRappen(t *testing.T) {
+	tests := []struct {
+		num, den int64
+		expected Rappen
+	}{
+		{5, 2, 3},
+		{-5, 2, -3},
+		{7, 3, 2},
+		{25000, 12, 2083},
+		{0, 1, 0},
+	}
+
+	for _, tt := range tests {
+		if result := roundRappen(big.NewRat(tt.num, tt.den)); result != tt.expected {
+			t.Errorf("roundRappen(%d/%d) = %d; want %d", tt.num, tt.den, result, tt.expected)
+		}
+	}
+}
+
+func TestRappenMarshalJSON(t *testing.T) {
+	tests := map[Rappen]string{0: "0.00", 5: "0.05", 67723: "677.23", 100: "1.00", -1205: "-12.05"}
+	for amount, expected := range tests {
+		body, err := json.Marshal(amount)
+		if err != nil || string(body) != expected {
+			t.Errorf("Marshal(%d) = %s (%v); want %s", amount, body, err, expected)
+		}
+	}
+}
+
+func TestQuoteFinancing(t *testing.T) {
+	config := FinancingConfig{InterestRate: "3.9", IncludedMileageKM: 10000, ExcessMileageRappen: 5}
+
+	tests := []struct {
+		name      string
+		req       FinancingRequest
+		monthly   Rappen
+		surcharge Rappen
+		interest  Rappen
+		total     Rappen
+	}{
+		{
+			name:     "Included mileage",
+			req:      FinancingRequest{ResidualValueCHF: 15000, TermMonths: 48},
+			monthly:  67723,
+			interest: 461704,
+			total:    67723*48 + 1500000,
+		},
+		{
+			name:      "Excess mileage",
+			req:       FinancingRequest{ResidualValueCHF: 15000, TermMonths: 48, YearlyMileageKM: 15000},
+			monthly:   67723 + 2083,
+			surcharge: 2083,
+			interest:  461704,
+			total:     (67723+2083)*48 + 1500000,
+		},
+		{
+			name:     "Down payment and lower mileage",
+			req:      FinancingRequest{DownPaymentCHF: 10000, TermMonths: 60, YearlyMileageKM: 5000},
+			monthly:  60424,
+			interest: 336440,
+			total:    1000000 + 60424*60,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quote := quoteFinancing(tt.req, 42890, config)
+			if quote.MonthlyRateCHF != tt.monthly || quote.MileageSurchargeCHF != tt.surcharge {
+				t.Errorf("Expected monthly %d with surcharge %d, got %d with %d", tt.monthly, tt.surcharge, quote.MonthlyRateCHF, quote.MileageSurchargeCHF)
+			}
+			if quote.TotalInterestCHF != tt.interest || quote.TotalCostCHF != tt.total {
+				t.Errorf("Expected interest %d and total %d, got %d and %d", tt.interest, tt.total, quote.TotalInterestCHF, quote.TotalCostCHF)
+			}
+			if quote.FinancedAmountCHF != chf(42890-tt.req.DownPaymentCHF) {
+				t.Errorf("Unexpected financed amount %d", quote.FinancedAmountCHF)
+			}
+		})
+	}
+}
+
+func TestValidateFinancingRequest(t *testing.T) {
+	tests := []struct {
+		name  string
+		req   FinancingRequest
+		price int
+		field string
+	}{
+		{name: "Valid", req: FinancingRequest{PriceCHF: 30000, DownPaymentCHF: 5000, ResidualValueCHF: 10000, TermMonths: 36}, price: 30000},
+		{name: "Car and price", req: FinancingRequest{CarID: 1, PriceCHF: 30000, TermMonths: 36}, price: 30000, field: "price_chf"},
+		{name: "No price", req: FinancingRequest{TermMonths: 36}, price: 0, field: "price_chf"},
+		{name: "Term too short", req: FinancingRequest{PriceCHF: 30000, TermMonths: 3}, price: 30000, field: "term_months"},
+		{name: "Term too long", req: FinancingRequest{PriceCHF: 30000, TermMonths: 240}, price: 30000, field: "term_months"},
+		{name: "Down payment above price", req: FinancingRequest{PriceCHF: 30000, DownPaymentCHF: 30000, TermMonths: 36}, price: 30000, field: "down_payment_chf"},
+		{name: "Residual above financed amount", req: FinancingRequest{PriceCHF: 30000, DownPaymentCHF: 20000, ResidualValueCHF: 10000, TermMonths: 36}, price: 30000, field: "residual_value_chf"},
+		{name: "Negative mileage", req: FinancingRequest{PriceCHF: 30000, TermMonths: 36, YearlyMileageKM: -1}, price: 30000, field: "yearly_mileage_km"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errors := validateFinancingRequest(tt.req, tt.price)
+			if tt.field == "" {
+				if len(errors) > 0 {
+					t.Errorf("Expected no errors, got %v", errors)
+				}
+				return
+			}
+			if len(errors) != 1 || errors[0].Field != tt.field {
+				t.Errorf("Expected error on %s, got %v", tt.field, errors)
+			}
+		})
+	}
+}
+
+func TestFinancingConfigFromEnv(t *testing.T) {
+	t.Setenv("FINANCING_INTEREST_RATE", "2.95")
+	t.Setenv("FINANCING_EXCESS_MILEAGE_RAPPEN", "8")
+
+	config, err := financingConfigFromEnv()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if config.InterestRate != "2.95" || config.ExcessMileageRappen != 8 || config.IncludedMileageKM != 10000 {
+		t.Errorf("Unexpected config: %+v", config)
+	}
+
+	t.Setenv("FINANCING_INTEREST_RATE", "drei")
+	if _, err := financingConfigFromEnv(); err == nil {
+		t.Error("Expected error for invalid interest rate")
+	}
+}
+
+func TestHandleFinancingQuote(t *testing.T) {
+	setTestCars(t, []Car{{ID: 1, Title: "BMW 520d", PriceCHF: 42890, Status: StatusAvailable}})
+	original := financing
+	financing = FinancingConfig{InterestRate: "3.9", IncludedMileageKM: 10000, ExcessMileageRappen: 5}
+	t.Cleanup(func() { financing = original })
+
+	tests := []struct {
+		name     string
+		method   string
+		body     string
+		expected int
+		contains string
+	}{
+		{name: "Car", method: "POST", body: `{"car_id": 1, "residual_value_chf": 15000, "term_months": 48}`, expected: http.StatusOK, contains: `"monthly_rate_chf":677.23`},
+		{name: "Price", method: "POST", body: `{"price_chf": 25000, "term_months": 12}`, expected: http.StatusOK, contains: `"monthly_rate_chf":2127.61`},
+		{name: "Unknown car", method: "POST", body: `{"car_id": 99, "term_months": 48}`, expected: http.StatusNotFound},
+		{name: "Invalid term", method: "POST", body: `{"car_id": 1, "term_months": 1}`, expected: http.StatusBadRequest, contains: `"field":"term_months"`},
+		{name: "Invalid JSON", method: "POST", body: `{"car_id": "1"}`, expected: http.StatusBadRequest},
+		{name: "Wrong method", method: "GET", expected: http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := events.APIGatewayProxyRequest{
+				Resource:   "/financing/quote",
+				HTTPMethod: tt.method,
+				Body:       tt.body,
+			}
+
+			response, err := handleRequest(context.Background(), request)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if response.StatusCode != tt.expected {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expected, response.StatusCode, response.Body)
+			}
+			if !strings.Contains(response.Body, tt.contains) {
+				t.Errorf("Expected body to contain %s, got %s", tt.contains, response.Body)
+			}
+		})
+	}
+}
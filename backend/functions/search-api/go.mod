@@ -2,4 +2,10 @@ module search-api
 
 go 1.21
 
-require github.com/aws/aws-lambda-go v1.41.0
+require (
+	github.com/andybalholm/brotli v1.1.1
+	github.com/aws/aws-lambda-go v1.41.0
+	github.com/aws/aws-sdk-go v1.55.7
+)
+
+require github.com/jmespath/go-jmespath v0.4.0 // indirect
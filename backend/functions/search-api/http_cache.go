@@ -0,0 +1,116 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// Default cache headers of search responses. The catalogue refreshes every
+// CATALOGUE_TTL, so shared caches may keep a response about as long.
+const (
+	DefaultCacheControl     = "public, max-age=60"
+	DefaultSurrogateControl = "max-age=300"
+)
+
+// CacheConfig holds the cache headers of cacheable responses; an empty value omits the header
+type CacheConfig struct {
+	CacheControl     string
+	SurrogateControl string
+}
+
+// httpCache is the cache configuration used by the handlers
+var httpCache = CacheConfig{CacheControl: DefaultCacheControl, SurrogateControl: DefaultSurrogateControl}
+
+// cacheConfigFromEnv reads CACHE_CONTROL and SURROGATE_CONTROL. Unset
+// variables keep the defaults, empty ones disable the header.
+func cacheConfigFromEnv() CacheConfig {
+	config := CacheConfig{CacheControl: DefaultCacheControl, SurrogateControl: DefaultSurrogateControl}
+	if value, ok := os.LookupEnv("CACHE_CONTROL"); ok {
+		config.CacheControl = value
+	}
+	if value, ok := os.LookupEnv("SURROGATE_CONTROL"); ok {
+		config.SurrogateControl = value
+	}
+	return config
+}
+
+// responseCacheKey identifies a response by endpoint and normalized request
+func responseCacheKey(endpoint string, normalized interface{}) string {
+	encoded, _ := json.Marshal(normalized)
+	return endpoint + "\x00" + string(encoded)
+}
+
+// responseETag returns a strong ETag for a response that depends only on the
+// catalogue version and the normalized request. Cursor tokens in search
+// results depend on the signing key, so the key takes part as well.
+func responseETag(version, key string) string {
+	hash := sha256.New()
+	for _, part := range [][]byte{[]byte(version), []byte(key), signCursor("etag")} {
+		hash.Write(part)
+		hash.Write([]byte{0})
+	}
+	return `"` + hex.EncodeToString(hash.Sum(nil)[:16]) + `"`
+}
+
+// etagMatches reports whether an If-None-Match header matches etag. The
+// comparison is weak, as CloudFront marks ETags of compressed responses weak.
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// cachedResponse serves a response of the catalogue snapshot for a
+// normalized request. A GET whose If-None-Match matches is answered with
+// 304 Not Modified, and bodies are built once per snapshot while they stay
+// in its response cache.
+func cachedResponse(request events.APIGatewayProxyRequest, headers map[string]string, snapshot *catalogueSnapshot, endpoint string, normalized interface{}, build func() interface{}) events.APIGatewayProxyResponse {
+	key := responseCacheKey(endpoint, normalized)
+	etag := responseETag(snapshot.Version, key)
+	if request.HTTPMethod == "GET" && etagMatches(headerValue(request.Headers, "If-None-Match"), etag) {
+		setCacheHeaders(headers, request.HTTPMethod, etag)
+		return events.APIGatewayProxyResponse{StatusCode: http.StatusNotModified, Headers: headers}
+	}
+
+	if body, ok := snapshot.responses.Get(key); ok {
+		setCacheHeaders(headers, request.HTTPMethod, etag)
+		return events.APIGatewayProxyResponse{StatusCode: http.StatusOK, Headers: headers, Body: body}
+	}
+
+	response := jsonResponse(http.StatusOK, headers, build())
+	// After a reload during the request the body may belong to the newer catalogue
+	if response.StatusCode == http.StatusOK && catalogue.Snapshot() == snapshot {
+		snapshot.responses.Add(key, response.Body)
+		setCacheHeaders(headers, request.HTTPMethod, etag)
+	}
+	return response
+}
+
+// setCacheHeaders adds the ETag, Vary and the configured cache headers. A 304
+// gets the same Vary as the 200 it replaces, whose body may be compressed.
+// Shared caches do not key on POST bodies, so only GET responses are public.
+func setCacheHeaders(headers map[string]string, method, etag string) {
+	headers["ETag"] = etag
+	addVary(headers, "Accept-Language")
+	addVary(headers, "Accept-Encoding")
+	if method != "GET" {
+		headers["Cache-Control"] = "no-store"
+		return
+	}
+	if httpCache.CacheControl != "" {
+		headers["Cache-Control"] = httpCache.CacheControl
+	}
+	if httpCache.SurrogateControl != "" {
+		headers["Surrogate-Control"] = httpCache.SurrogateControl
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func TestEtagMatches(t *testing.T) {
+	etag := `"abc"`
+	tests := []struct {
+		header   string
+		expected bool
+	}{
+		{header: `"abc"`, expected: true},
+		{header: `W/"abc"`, expected: true},
+		{header: `"xyz", "abc"`, expected: true},
+		{header: `*`, expected: true},
+		{header: `"xyz"`, expected: false},
+		{header: `abc`, expected: false},
+		{header: ``, expected: false},
+	}
+
+	for _, tt := range tests {
+		if result := etagMatches(tt.header, etag); result != tt.expected {
+			t.Errorf("etagMatches(%q): expected %v, got %v", tt.header, tt.expected, result)
+		}
+	}
+}
+
+func TestCacheConfigFromEnv(t *testing.T) {
+	config := cacheConfigFromEnv()
+	if config.CacheControl != DefaultCacheControl || config.SurrogateControl != DefaultSurrogateControl {
+		t.Errorf("Expected defaults, got %+v", config)
+	}
+
+	t.Setenv("CACHE_CONTROL", "public, max-age=30")
+	t.Setenv("SURROGATE_CONTROL", "")
+	config = cacheConfigFromEnv()
+	if config.CacheControl != "public, max-age=30" || config.SurrogateControl != "" {
+		t.Errorf("Unexpected config %+v", config)
+	}
+}
+
+func TestHandleRequestConditional(t *testing.T) {
+	setTestCars(t, cursorTestCars())
+
+	get := func(resource string, query map[string]string, ifNoneMatch string) events.APIGatewayProxyResponse {
+		response, err := handleRequest(context.Background(), events.APIGatewayProxyRequest{
+			Resource:              resource,
+			HTTPMethod:            "GET",
+			QueryStringParameters: query,
+			Headers:               map[string]string{"if-none-match": ifNoneMatch},
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return response
+	}
+
+	for _, resource := range []string{"/search/options", "/search"} {
+		t.Run(resource, func(t *testing.T) {
+			first := get(resource, nil, "")
+			etag := first.Headers["ETag"]
+			if first.StatusCode != http.StatusOK || etag == "" {
+				t.Fatalf("Expected 200 with ETag, got %d %v", first.StatusCode, first.Headers)
+			}
+			if first.Headers["Cache-Control"] != DefaultCacheControl || first.Headers["Surrogate-Control"] != DefaultSurrogateControl {
+				t.Errorf("Unexpected cache headers %v", first.Headers)
+			}
+
+			notModified := get(resource, nil, etag)
+			if notModified.StatusCode != http.StatusNotModified || notModified.Body != "" || notModified.Headers["ETag"] != etag {
+				t.Errorf("Expected 304 with ETag, got %d %q %v", notModified.StatusCode, notModified.Body, notModified.Headers)
+			}
+			if vary := notModified.Headers["Vary"]; vary != first.Headers["Vary"] || vary != "Accept-Language, Accept-Encoding" {
+				t.Errorf("Expected the Vary of the 200 on the 304, got %q and %q", first.Headers["Vary"], vary)
+			}
+
+			// Another request or another catalogue version yields another tag
+			if other := get(resource, map[string]string{"brand": "BMW"}, etag); resource == "/search" && other.StatusCode != http.StatusOK {
+				t.Errorf("Expected 200 for other filters, got %d", other.StatusCode)
+			}
+			catalogue.Store(cursorTestCars()[:2], "reloaded")
+			if reloaded := get(resource, nil, etag); reloaded.StatusCode != http.StatusOK || reloaded.Headers["ETag"] == etag {
+				t.Errorf("Expected new ETag after reload, got %d %v", reloaded.StatusCode, reloaded.Headers)
+			}
+			catalogue.Store(cursorTestCars(), t.Name())
+		})
+	}
+}
+
+func TestHandleSearchETagIgnoresRequestForm(t *testing.T) {
+	setTestCars(t, cursorTestCars())
+
+	post, _ := handleRequest(context.Background(), events.APIGatewayProxyRequest{
+		Resource:   "/search",
+		HTTPMethod: "POST",
+		Body:       `{"brand": "BMW", "equipment": ["Navi"], "limit": 10}`,
+	})
+	get, _ := handleRequest(context.Background(), events.APIGatewayProxyRequest{
+		Resource:              "/search",
+		HTTPMethod:            "GET",
+		QueryStringParameters: map[string]string{"brand": "BMW", "equipment": "navigation"},
+	})
+	if post.Headers["ETag"] == "" || post.Headers["ETag"] != get.Headers["ETag"] {
+		t.Errorf("Expected equal ETags for equal searches, got %q and %q", post.Headers["ETag"], get.Headers["ETag"])
+	}
+	if post.Headers["Cache-Control"] != "no-store" || post.Headers["Surrogate-Control"] != "" {
+		t.Errorf("Expected POST responses not to be cached, got %v", post.Headers)
+	}
+
+	// POST always gets the full response
+	conditional, _ := handleRequest(context.Background(), events.APIGatewayProxyRequest{
+		Resource:   "/search",
+		HTTPMethod: "POST",
+		Body:       `{"brand": "BMW"}`,
+		Headers:    map[string]string{"If-None-Match": post.Headers["ETag"]},
+	})
+	if conditional.StatusCode != http.StatusOK {
+		t.Errorf("Expected 200 for POST, got %d", conditional.StatusCode)
+	}
+}
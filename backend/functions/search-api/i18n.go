@@ -0,0 +1,454 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// supportedLanguages are the languages of the Swiss customers. Catalogue
+// values are maintained in German and API messages are written in English;
+// both are translated when a client asks for a language.
+var supportedLanguages = []string{"de", "fr", "it", "en"}
+
+// supportedLanguage returns the supported language of a tag like "fr-CH", or ""
+func supportedLanguage(tag string) string {
+	primary, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+	for _, lang := range supportedLanguages {
+		if primary == lang {
+			return lang
+		}
+	}
+	return ""
+}
+
+// requestLanguage returns the language of a request from the lang query
+// parameter or the Accept-Language header. Without either, "" keeps the
+// responses untranslated.
+func requestLanguage(request events.APIGatewayProxyRequest) string {
+	if lang := supportedLanguage(request.QueryStringParameters["lang"]); lang != "" {
+		return lang
+	}
+
+	best, bestQuality := "", 0.0
+	for _, part := range strings.Split(headerValue(request.Headers, "Accept-Language"), ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		lang := supportedLanguage(tag)
+		if lang == "" {
+			continue
+		}
+
+		quality := 1.0
+		if key, value, ok := strings.Cut(strings.TrimSpace(params), "="); ok && strings.TrimSpace(key) == "q" {
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil {
+				continue
+			}
+			quality = parsed
+		}
+		// Earlier tags win on equal quality
+		if quality > bestQuality {
+			best, bestQuality = lang, quality
+		}
+	}
+	return best
+}
+
+// enumLabels translates catalogue values and status codes. German labels are
+// only listed where the value itself is not German.
+var enumLabels = map[string]map[string]string{
+	// Car types
+	"Limousine":  {"fr": "Berline", "it": "Berlina", "en": "Saloon"},
+	"Kombi":      {"fr": "Break", "it": "Station wagon", "en": "Estate"},
+	"SUV":        {"fr": "SUV", "it": "SUV", "en": "SUV"},
+	"Coupé":      {"fr": "Coupé", "it": "Coupé", "en": "Coupé"},
+	"Cabriolet":  {"fr": "Cabriolet", "it": "Cabriolet", "en": "Convertible"},
+	"Kleinwagen": {"fr": "Petite voiture", "it": "Utilitaria", "en": "Small car"},
+	"Van":        {"fr": "Monospace", "it": "Monovolume", "en": "Van"},
+	"Pick-up":    {"fr": "Pick-up", "it": "Pick-up", "en": "Pick-up"},
+
+	// Transmissions
+	"Automatik": {"fr": "Automatique", "it": "Automatico", "en": "Automatic"},
+	"Manuell":   {"fr": "Manuelle", "it": "Manuale", "en": "Manual"},
+
+	// Fuels
+	"Diesel":      {"fr": "Diesel", "it": "Diesel", "en": "Diesel"},
+	"Benzin":      {"fr": "Essence", "it": "Benzina", "en": "Petrol"},
+	"Elektro":     {"fr": "Électrique", "it": "Elettrica", "en": "Electric"},
+	"Hybrid":      {"fr": "Hybride", "it": "Ibrida", "en": "Hybrid"},
+	"Erdgas":      {"fr": "Gaz naturel", "it": "Metano", "en": "Natural gas"},
+	"Autogas":     {"fr": "GPL", "it": "GPL", "en": "LPG"},
+	"Wasserstoff": {"fr": "Hydrogène", "it": "Idrogeno", "en": "Hydrogen"},
+
+	// Drives
+	"Allrad":    {"fr": "Intégrale", "it": "Integrale", "en": "All-wheel drive"},
+	"Front":     {"fr": "Traction avant", "it": "Trazione anteriore", "en": "Front-wheel drive"},
+	"Hinterrad": {"fr": "Propulsion", "it": "Trazione posteriore", "en": "Rear-wheel drive"},
+
+	// Status codes
+	StatusAvailable: {"de": "Verfügbar", "fr": "Disponible", "it": "Disponibile", "en": "Available"},
+	StatusReserved:  {"de": "Reserviert", "fr": "Réservé", "it": "Riservato", "en": "Reserved"},
+	StatusSold:      {"de": "Verkauft", "fr": "Vendu", "it": "Venduto", "en": "Sold"},
+}
+
+// equipmentLabels translates the labels of equipmentVocabulary by code
+var equipmentLabels = map[string]map[string]string{
+	"360-camera":              {"fr": "Caméra 360°", "it": "Telecamera 360°", "en": "360° camera"},
+	"adaptive-cruise-control": {"fr": "Régulateur de vitesse adaptatif", "it": "Cruise control adattivo", "en": "Adaptive cruise control"},
+	"ambient-lighting":        {"fr": "Éclairage d'ambiance", "it": "Luci ambiente", "en": "Ambient lighting"},
+	"apple-carplay":           {"fr": "Apple CarPlay", "it": "Apple CarPlay", "en": "Apple CarPlay"},
+	"digital-cockpit":         {"fr": "Cockpit numérique", "it": "Cockpit digitale", "en": "Digital cockpit"},
+	"heated-seats":            {"fr": "Sièges chauffants", "it": "Sedili riscaldati", "en": "Heated seats"},
+	"keyless":                 {"fr": "Accès sans clé", "it": "Accesso senza chiave", "en": "Keyless entry"},
+	"lane-assist":             {"fr": "Assistant de maintien de voie", "it": "Assistente di corsia", "en": "Lane assist"},
+	"leather":                 {"fr": "Cuir", "it": "Pelle", "en": "Leather"},
+	"led-headlights":          {"fr": "Phares LED", "it": "Fari LED", "en": "LED headlights"},
+	"navigation":              {"fr": "Système de navigation", "it": "Navigatore", "en": "Navigation system"},
+	"panoramic-roof":          {"fr": "Toit panoramique", "it": "Tetto panoramico", "en": "Panoramic roof"},
+	"parking-sensors":         {"fr": "Capteurs de stationnement", "it": "Sensori di parcheggio", "en": "Parking sensors"},
+	"rear-camera":             {"fr": "Caméra de recul", "it": "Telecamera posteriore", "en": "Rear camera"},
+	"sport-seats":             {"fr": "Sièges sport", "it": "Sedili sportivi", "en": "Sport seats"},
+	"trailer-hitch":           {"fr": "Attelage", "it": "Gancio di traino", "en": "Trailer hitch"},
+}
+
+// enumLabel returns the label of a catalogue value or status code, falling
+// back to the value itself
+func enumLabel(lang, value string) string {
+	if label, ok := enumLabels[value][lang]; ok {
+		return label
+	}
+	return value
+}
+
+// equipmentLabel returns the label of a canonical feature
+func equipmentLabel(lang string, feature equipmentFeature) string {
+	if label, ok := equipmentLabels[feature.Code][lang]; ok {
+		return label
+	}
+	return feature.Label
+}
+
+// localizeEquipment translates the known items of an equipment list
+func localizeEquipment(lang string, items []string) []string {
+	localized := make([]string, len(items))
+	for i, item := range items {
+		localized[i] = item
+		if feature, ok := lookupEquipment(item); ok {
+			localized[i] = equipmentLabel(lang, feature)
+		}
+	}
+	return localized
+}
+
+// carLabels returns the translated enumerations of a car. The values
+// themselves stay the same in every language, so they remain usable as filters.
+func carLabels(lang string, car Car) map[string]string {
+	return map[string]string{
+		"car_type":     enumLabel(lang, car.CarType),
+		"transmission": enumLabel(lang, car.Transmission),
+		"fuel":         enumLabel(lang, car.Fuel),
+		"drive":        enumLabel(lang, car.Drive),
+		"status":       enumLabel(lang, car.Status),
+	}
+}
+
+// LocalizedCar is a car with the translated enumerations in Labels, like the
+// cars of a search response
+type LocalizedCar struct {
+	Car
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// localizeCar translates the equipment of a car and adds its labels in lang
+func localizeCar(lang string, car Car) LocalizedCar {
+	if lang == "" {
+		return LocalizedCar{Car: car}
+	}
+	car.Equipment = localizeEquipment(lang, car.Equipment)
+	return LocalizedCar{Car: car, Labels: carLabels(lang, car)}
+}
+
+// localizeComparison adds the labels of the cars and enumerated fields and
+// translates the compared equipment
+func localizeComparison(comparison CompareResponse, lang string) CompareResponse {
+	if lang == "" || len(comparison.Cars) == 0 {
+		return comparison
+	}
+
+	for i, car := range comparison.Cars {
+		comparison.Cars[i] = localizeCar(lang, car.Car)
+	}
+	for i, field := range comparison.Fields {
+		if _, ok := comparison.Cars[0].Labels[field.Field]; !ok {
+			continue
+		}
+		comparison.Fields[i].Labels = make([]string, len(comparison.Cars))
+		for j, car := range comparison.Cars {
+			comparison.Fields[i].Labels[j] = car.Labels[field.Field]
+		}
+	}
+
+	comparison.Equipment.Shared = localizeEquipment(lang, comparison.Equipment.Shared)
+	for id, items := range comparison.Equipment.Partial {
+		comparison.Equipment.Partial[id] = localizeEquipment(lang, items)
+	}
+	for id, items := range comparison.Equipment.Unique {
+		comparison.Equipment.Unique[id] = localizeEquipment(lang, items)
+	}
+	return comparison
+}
+
+// localizeSimilarCars adds the labels of the recommended cars in lang
+func localizeSimilarCars(cars []SimilarCar, lang string) []SimilarCar {
+	for i, car := range cars {
+		cars[i].LocalizedCar = localizeCar(lang, car.Car)
+	}
+	return cars
+}
+
+// localizeSearchOptions adds the labels of the filter values in lang
+func localizeSearchOptions(options SearchOptions, lang string) SearchOptions {
+	if lang == "" {
+		return options
+	}
+
+	options.Labels = make(map[string]string)
+	for _, values := range [][]string{options.CarTypes, options.Transmissions, options.Fuels, options.Drives} {
+		for _, value := range values {
+			options.Labels[value] = enumLabel(lang, value)
+		}
+	}
+
+	// The options are shared by all requests of a snapshot and must not be modified
+	options.CarTypeOptions = localizeEnumOptions(lang, options.CarTypeOptions)
+	options.TransmissionOptions = localizeEnumOptions(lang, options.TransmissionOptions)
+	options.FuelOptions = localizeEnumOptions(lang, options.FuelOptions)
+	options.DriveOptions = localizeEnumOptions(lang, options.DriveOptions)
+
+	equipment := make([]EquipmentOption, len(options.Equipment))
+	for i, option := range options.Equipment {
+		equipment[i] = option
+		if feature, ok := lookupEquipment(option.Code); ok {
+			equipment[i].Label = equipmentLabel(lang, feature)
+		}
+	}
+	options.Equipment = equipment
+	return options
+}
+
+// localizeEnumOptions returns a copy of the options with labels in lang
+func localizeEnumOptions(lang string, options []EnumOption) []EnumOption {
+	localized := make([]EnumOption, len(options))
+	for i, option := range options {
+		localized[i] = EnumOption{Code: option.Code, Label: enumLabel(lang, option.Label)}
+	}
+	return localized
+}
+
+// Message keys. Messages are built from a key and its arguments where they
+// are created; errorResponse renders them in the requested language.
+const (
+	// Errors
+	msgMethodNotAllowed        = "method_not_allowed"
+	msgNotFound                = "not_found"
+	msgBodyTooLarge            = "body_too_large"
+	msgInvalidJSON             = "invalid_json"
+	msgInvalidBody             = "invalid_body"
+	msgInternalError           = "internal_error"
+	msgValidationFailed        = "validation_failed"
+	msgCarNotFound             = "car_not_found"
+	msgCarExists               = "car_exists"
+	msgInvalidCarID            = "invalid_car_id"
+	msgAdminNotConfigured      = "admin_not_configured"
+	msgAuthRequired            = "auth_required"
+	msgInvalidCredentials      = "invalid_credentials"
+	msgInsufficientPermissions = "insufficient_permissions"
+
+	// Saved searches
+	msgSavedSearchesNotConfigured = "saved_searches_not_configured"
+	msgSavedSearchNotFound        = "saved_search_not_found"
+	msgInvalidEmail               = "invalid_email"
+	msgTooManySavedSearches       = "too_many_saved_searches"
+	msgEmailFailed                = "email_failed"
+	msgConfirmationSent           = "confirmation_sent"
+	msgSavedSearchConfirmed       = "saved_search_confirmed"
+	msgSavedSearchDeleted         = "saved_search_deleted"
+
+	// Search validation
+	msgQueryTooLong        = "query_too_long"
+	msgQueryInvalid        = "query_invalid"
+	msgBrandInvalid        = "brand_invalid"
+	msgModelInvalid        = "model_invalid"
+	msgMinPriceRange       = "min_price_range"
+	msgMaxPriceRange       = "max_price_range"
+	msgMinMileageRange     = "min_mileage_range"
+	msgMaxMileageRange     = "max_mileage_range"
+	msgMinPowerRange       = "min_power_range"
+	msgMaxPowerRange       = "max_power_range"
+	msgMinYearRange        = "min_year_range"
+	msgMaxYearRange        = "max_year_range"
+	msgTooManyEquipment    = "too_many_equipment"
+	msgUnknownEquipment    = "unknown_equipment"
+	msgMaxLeasingRateRange = "max_leasing_rate_range"
+	msgMinWarrantyRange    = "min_warranty_months_range"
+	msgUnknownField        = "unknown_field"
+	msgUnknownSort         = "unknown_sort"
+	msgCursorOrOffset      = "cursor_or_offset"
+	msgInvalidCursor       = "invalid_cursor"
+	msgCursorMismatch      = "cursor_mismatch"
+	msgNotInteger          = "not_integer"
+	msgNotBoolean          = "not_boolean"
+	msgSingleValue         = "single_value"
+	msgInvalidQuery        = "invalid_query"
+	msgCompareCount        = "compare_count"
+	msgCarIDInvalid        = "car_id_invalid"
+	msgCarIDDuplicate      = "car_id_duplicate"
+	msgCarIDUnknown        = "car_id_unknown"
+	msgCarOrPrice          = "car_or_price"
+	msgFinancingPriceRange = "financing_price_range"
+	msgTermRange           = "term_range"
+	msgYearlyMileageRange  = "yearly_mileage_range"
+	msgDownPaymentRange    = "down_payment_range"
+	msgResidualValueRange  = "residual_value_range"
+
+	// Admin validation
+	msgIDPositive      = "id_positive"
+	msgIDMismatch      = "id_mismatch"
+	msgRequired        = "required"
+	msgTextTooLong     = "text_too_long"
+	msgPriceRange      = "price_range"
+	msgMileageRange    = "mileage_range"
+	msgPowerRange      = "power_range"
+	msgInvalidStatus   = "invalid_status"
+	msgInvalidImageURL = "invalid_image_url"
+	msgInvalidCar      = "invalid_car"
+)
+
+// messageCatalog holds the texts of the message keys. Placeholders (%d, %s,
+// %q) take the arguments of the message in order.
+var messageCatalog = map[string]map[string]string{
+	// Errors
+	msgMethodNotAllowed:        {"en": "Method not allowed", "de": "Methode nicht erlaubt", "fr": "Méthode non autorisée", "it": "Metodo non consentito"},
+	msgNotFound:                {"en": "Not found", "de": "Nicht gefunden", "fr": "Introuvable", "it": "Non trovato"},
+	msgBodyTooLarge:            {"en": "Request body too large", "de": "Anfrage zu gross", "fr": "Requête trop volumineuse", "it": "Richiesta troppo grande"},
+	msgInvalidJSON:             {"en": "Invalid JSON body", "de": "Ungültiger JSON-Body", "fr": "Corps JSON invalide", "it": "Corpo JSON non valido"},
+	msgInvalidBody:             {"en": "Invalid request body", "de": "Ungültiger Anfrageinhalt", "fr": "Contenu de la requête invalide", "it": "Contenuto della richiesta non valido"},
+	msgInternalError:           {"en": "Internal server error", "de": "Interner Serverfehler", "fr": "Erreur interne du serveur", "it": "Errore interno del server"},
+	msgValidationFailed:        {"en": "Validation failed", "de": "Validierung fehlgeschlagen", "fr": "Échec de la validation", "it": "Convalida non riuscita"},
+	msgCarNotFound:             {"en": "Car not found", "de": "Fahrzeug nicht gefunden", "fr": "Véhicule introuvable", "it": "Veicolo non trovato"},
+	msgCarExists:               {"en": "Car already exists", "de": "Fahrzeug existiert bereits", "fr": "Le véhicule existe déjà", "it": "Il veicolo esiste già"},
+	msgInvalidCarID:            {"en": "Invalid car ID", "de": "Ungültige Fahrzeug-ID", "fr": "ID de véhicule invalide", "it": "ID veicolo non valido"},
+	msgAdminNotConfigured:      {"en": "Admin API not configured", "de": "Admin-API nicht konfiguriert", "fr": "API d'administration non configurée", "it": "API di amministrazione non configurata"},
+	msgAuthRequired:            {"en": "Authentication required", "de": "Anmeldung erforderlich", "fr": "Authentification requise", "it": "Autenticazione richiesta"},
+	msgInvalidCredentials:      {"en": "Invalid credentials", "de": "Ungültige Anmeldedaten", "fr": "Identifiants invalides", "it": "Credenziali non valide"},
+	msgInsufficientPermissions: {"en": "Insufficient permissions", "de": "Fehlende Berechtigung", "fr": "Autorisations insuffisantes", "it": "Autorizzazioni insufficienti"},
+
+	// Saved searches
+	msgSavedSearchesNotConfigured: {"en": "Saved searches not configured", "de": "Suchaufträge nicht konfiguriert", "fr": "Recherches enregistrées non configurées", "it": "Ricerche salvate non configurate"},
+	msgSavedSearchNotFound:        {"en": "Saved search not found", "de": "Suchauftrag nicht gefunden", "fr": "Recherche enregistrée introuvable", "it": "Ricerca salvata non trovata"},
+	msgInvalidEmail:               {"en": "Invalid email address", "de": "Ungültige E-Mail-Adresse", "fr": "Adresse e-mail invalide", "it": "Indirizzo e-mail non valido"},
+	msgTooManySavedSearches:       {"en": "Too many saved searches for this email", "de": "Zu viele Suchaufträge für diese E-Mail-Adresse", "fr": "Trop de recherches enregistrées pour cette adresse e-mail", "it": "Troppe ricerche salvate per questo indirizzo e-mail"},
+	msgEmailFailed:                {"en": "Failed to send email", "de": "E-Mail konnte nicht gesendet werden", "fr": "L'e-mail n'a pas pu être envoyé", "it": "Impossibile inviare l'e-mail"},
+	msgConfirmationSent:           {"en": "Confirmation email sent", "de": "Bestätigungs-E-Mail gesendet", "fr": "E-mail de confirmation envoyé", "it": "E-mail di conferma inviata"},
+	msgSavedSearchConfirmed:       {"en": "Saved search confirmed", "de": "Suchauftrag bestätigt", "fr": "Recherche enregistrée confirmée", "it": "Ricerca salvata confermata"},
+	msgSavedSearchDeleted:         {"en": "Saved search deleted", "de": "Suchauftrag gelöscht", "fr": "Recherche enregistrée supprimée", "it": "Ricerca salvata eliminata"},
+
+	// Search validation
+	msgQueryTooLong:        {"en": "Query too long, maximum %d characters", "de": "Suchbegriff zu lang, maximal %d Zeichen", "fr": "Recherche trop longue, %d caractères au maximum", "it": "Ricerca troppo lunga, massimo %d caratteri"},
+	msgQueryInvalid:        {"en": "Query contains invalid characters", "de": "Suchbegriff enthält ungültige Zeichen", "fr": "La recherche contient des caractères invalides", "it": "La ricerca contiene caratteri non validi"},
+	msgBrandInvalid:        {"en": "Brand contains invalid characters", "de": "Marke enthält ungültige Zeichen", "fr": "La marque contient des caractères invalides", "it": "La marca contiene caratteri non validi"},
+	msgModelInvalid:        {"en": "Model contains invalid characters", "de": "Modell enthält ungültige Zeichen", "fr": "Le modèle contient des caractères invalides", "it": "Il modello contiene caratteri non validi"},
+	msgMinPriceRange:       {"en": "Min price must be between %d and %d", "de": "Mindestpreis muss zwischen %d und %d liegen", "fr": "Le prix minimum doit être compris entre %d et %d", "it": "Il prezzo minimo deve essere tra %d e %d"},
+	msgMaxPriceRange:       {"en": "Max price must be between %d and %d", "de": "Höchstpreis muss zwischen %d und %d liegen", "fr": "Le prix maximum doit être compris entre %d et %d", "it": "Il prezzo massimo deve essere tra %d e %d"},
+	msgMinMileageRange:     {"en": "Min mileage must be between %d and %d", "de": "Minimaler Kilometerstand muss zwischen %d und %d liegen", "fr": "Le kilométrage minimum doit être compris entre %d et %d", "it": "Il chilometraggio minimo deve essere tra %d e %d"},
+	msgMaxMileageRange:     {"en": "Max mileage must be between %d and %d", "de": "Maximaler Kilometerstand muss zwischen %d und %d liegen", "fr": "Le kilométrage maximum doit être compris entre %d et %d", "it": "Il chilometraggio massimo deve essere tra %d e %d"},
+	msgMinPowerRange:       {"en": "Min power must be between %d and %d", "de": "Minimale Leistung muss zwischen %d und %d liegen", "fr": "La puissance minimale doit être comprise entre %d et %d", "it": "La potenza minima deve essere tra %d e %d"},
+	msgMaxPowerRange:       {"en": "Max power must be between %d and %d", "de": "Maximale Leistung muss zwischen %d und %d liegen", "fr": "La puissance maximale doit être comprise entre %d et %d", "it": "La potenza massima deve essere tra %d e %d"},
+	msgMinYearRange:        {"en": "Min year must be between %d and %d", "de": "Frühestes Jahr muss zwischen %d und %d liegen", "fr": "L'année minimale doit être comprise entre %d et %d", "it": "L'anno minimo deve essere tra %d e %d"},
+	msgMaxYearRange:        {"en": "Max year must be between %d and %d", "de": "Spätestes Jahr muss zwischen %d und %d liegen", "fr": "L'année maximale doit être comprise entre %d et %d", "it": "L'anno massimo deve essere tra %d e %d"},
+	msgTooManyEquipment:    {"en": "At most %d equipment features allowed", "de": "Höchstens %d Ausstattungsmerkmale erlaubt", "fr": "%d équipements au maximum", "it": "Al massimo %d equipaggiamenti consentiti"},
+	msgUnknownEquipment:    {"en": "Unknown equipment %q", "de": "Unbekannte Ausstattung %q", "fr": "Équipement inconnu %q", "it": "Equipaggiamento sconosciuto %q"},
+	msgMaxLeasingRateRange: {"en": "Max leasing rate must be between %d and %d", "de": "Maximale Leasingrate muss zwischen %d und %d liegen", "fr": "La mensualité de leasing maximale doit être comprise entre %d et %d", "it": "La rata di leasing massima deve essere tra %d e %d"},
+	msgMinWarrantyRange:    {"en": "Min warranty months must be between 0 and %d", "de": "Mindestgarantie muss zwischen 0 und %d Monaten liegen", "fr": "La garantie minimale doit être comprise entre 0 et %d mois", "it": "La garanzia minima deve essere tra 0 e %d mesi"},
+	msgUnknownField:        {"en": "Unknown field: %s", "de": "Unbekanntes Feld: %s", "fr": "Champ inconnu : %s", "it": "Campo sconosciuto: %s"},
+	msgUnknownSort:         {"en": "Unknown sort order", "de": "Unbekannte Sortierung", "fr": "Ordre de tri inconnu", "it": "Ordinamento sconosciuto"},
+	msgCursorOrOffset:      {"en": "Use either cursor or offset", "de": "Entweder cursor oder offset verwenden", "fr": "Utilisez soit cursor soit offset", "it": "Usare cursor oppure offset"},
+	msgInvalidCursor:       {"en": "Invalid cursor", "de": "Ungültiger Cursor", "fr": "Curseur invalide", "it": "Cursore non valido"},
+	msgCursorMismatch:      {"en": "Cursor belongs to a different search", "de": "Cursor gehört zu einer anderen Suche", "fr": "Le curseur appartient à une autre recherche", "it": "Il cursore appartiene a un'altra ricerca"},
+	msgNotInteger:          {"en": "Must be a whole number", "de": "Muss eine ganze Zahl sein", "fr": "Doit être un nombre entier", "it": "Deve essere un numero intero"},
+	msgNotBoolean:          {"en": "Must be true or false", "de": "Muss true oder false sein", "fr": "Doit être true ou false", "it": "Deve essere true o false"},
+	msgSingleValue:         {"en": "Only one value allowed", "de": "Nur ein Wert erlaubt", "fr": "Une seule valeur autorisée", "it": "È consentito un solo valore"},
+	msgInvalidQuery:        {"en": "Invalid query parameters", "de": "Ungültige Query-Parameter", "fr": "Paramètres de requête invalides", "it": "Parametri di query non validi"},
+	msgCompareCount:        {"en": "Between %d and %d car IDs are required", "de": "Zwischen %d und %d Fahrzeug-IDs erforderlich", "fr": "Entre %d et %d ID de véhicule sont requis", "it": "Sono richiesti tra %d e %d ID veicolo"},
+	msgCarIDInvalid:        {"en": "Invalid car ID %d", "de": "Ungültige Fahrzeug-ID %d", "fr": "ID de véhicule %d invalide", "it": "ID veicolo %d non valido"},
+	msgCarIDDuplicate:      {"en": "Duplicate car ID %d", "de": "Doppelte Fahrzeug-ID %d", "fr": "ID de véhicule %d en double", "it": "ID veicolo %d duplicato"},
+	msgCarIDUnknown:        {"en": "Unknown car ID %d", "de": "Unbekannte Fahrzeug-ID %d", "fr": "ID de véhicule %d inconnu", "it": "ID veicolo %d sconosciuto"},
+	msgCarOrPrice:          {"en": "Provide either car_id or price_chf", "de": "Entweder car_id oder price_chf angeben", "fr": "Indiquez soit car_id soit price_chf", "it": "Indicare car_id oppure price_chf"},
+	msgFinancingPriceRange: {"en": "Price must be between 1 and %d", "de": "Preis muss zwischen 1 und %d liegen", "fr": "Le prix doit être compris entre 1 et %d", "it": "Il prezzo deve essere tra 1 e %d"},
+	msgTermRange:           {"en": "Term must be between %d and %d months", "de": "Laufzeit muss zwischen %d und %d Monaten liegen", "fr": "La durée doit être comprise entre %d et %d mois", "it": "La durata deve essere tra %d e %d mesi"},
+	msgYearlyMileageRange:  {"en": "Yearly mileage must be between 0 and %d", "de": "Jährliche Fahrleistung muss zwischen 0 und %d liegen", "fr": "Le kilométrage annuel doit être compris entre 0 et %d", "it": "Il chilometraggio annuo deve essere tra 0 e %d"},
+	msgDownPaymentRange:    {"en": "Down payment must be at least 0 and below the price", "de": "Anzahlung muss mindestens 0 und kleiner als der Preis sein", "fr": "L'acompte doit être d'au moins 0 et inférieur au prix", "it": "L'anticipo deve essere almeno 0 e inferiore al prezzo"},
+	msgResidualValueRange:  {"en": "Residual value must be at least 0 and below the financed amount", "de": "Restwert muss mindestens 0 und kleiner als der finanzierte Betrag sein", "fr": "La valeur résiduelle doit être d'au moins 0 et inférieure au montant financé", "it": "Il valore residuo deve essere almeno 0 e inferiore all'importo finanziato"},
+
+	// Admin validation
+	msgIDPositive:      {"en": "ID must be a positive number", "de": "ID muss eine positive Zahl sein", "fr": "L'ID doit être un nombre positif", "it": "L'ID deve essere un numero positivo"},
+	msgIDMismatch:      {"en": "ID does not match the URL", "de": "ID stimmt nicht mit der URL überein", "fr": "L'ID ne correspond pas à l'URL", "it": "L'ID non corrisponde all'URL"},
+	msgRequired:        {"en": "Field is required", "de": "Pflichtfeld", "fr": "Champ obligatoire", "it": "Campo obbligatorio"},
+	msgTextTooLong:     {"en": "Text too long, maximum %d characters", "de": "Text zu lang, maximal %d Zeichen", "fr": "Texte trop long, %d caractères au maximum", "it": "Testo troppo lungo, massimo %d caratteri"},
+	msgPriceRange:      {"en": "Price must be between %d and %d", "de": "Preis muss zwischen %d und %d liegen", "fr": "Le prix doit être compris entre %d et %d", "it": "Il prezzo deve essere tra %d e %d"},
+	msgMileageRange:    {"en": "Mileage must be between %d and %d", "de": "Kilometerstand muss zwischen %d und %d liegen", "fr": "Le kilométrage doit être compris entre %d et %d", "it": "Il chilometraggio deve essere tra %d e %d"},
+	msgPowerRange:      {"en": "Power must be between %d and %d", "de": "Leistung muss zwischen %d und %d liegen", "fr": "La puissance doit être comprise entre %d et %d", "it": "La potenza deve essere tra %d e %d"},
+	msgInvalidStatus:   {"en": "Status must be available, reserved or sold", "de": "Status muss available, reserved oder sold sein", "fr": "Le statut doit être available, reserved ou sold", "it": "Lo stato deve essere available, reserved o sold"},
+	msgInvalidImageURL: {"en": "Image URLs must start with http:// or https://", "de": "Bild-URLs müssen mit http:// oder https:// beginnen", "fr": "Les URL des images doivent commencer par http:// ou https://", "it": "Gli URL delle immagini devono iniziare con http:// o https://"},
+	msgInvalidCar:      {"en": "Car could not be read from the submitted fields", "de": "Fahrzeug konnte aus den Angaben nicht gelesen werden", "fr": "Le véhicule n'a pas pu être lu à partir des champs envoyés", "it": "Il veicolo non può essere letto dai campi inviati"},
+}
+
+// messageText renders a message key with its arguments in lang, falling back
+// to English
+func messageText(lang, key string, args ...interface{}) string {
+	texts := messageCatalog[key]
+	text, ok := texts[lang]
+	if !ok {
+		text, ok = texts["en"]
+	}
+	if !ok {
+		return key
+	}
+	if len(args) == 0 {
+		return text
+	}
+	return fmt.Sprintf(text, args...)
+}
+
+// newValidationError builds a validation error from a message key. The
+// message is rendered by errorResponse in the language of the request.
+func newValidationError(field, key string, args ...interface{}) ValidationError {
+	return ValidationError{Field: field, Code: key, Params: args}
+}
+
+// languageHeaders returns the CORS headers of a response in lang. Responses
+// depend on Accept-Language, so they vary on it even without a language.
+func languageHeaders(lang string) map[string]string {
+	headers := corsHeaders()
+	addVary(headers, "Accept-Language")
+	if lang != "" {
+		headers["Content-Language"] = lang
+	}
+	return headers
+}
+
+// addVary adds a header name to the Vary header
+func addVary(headers map[string]string, name string) {
+	vary := headers["Vary"]
+	for _, existing := range strings.Split(vary, ",") {
+		if strings.EqualFold(strings.TrimSpace(existing), name) {
+			return
+		}
+	}
+	if vary != "" {
+		vary += ", "
+	}
+	headers["Vary"] = vary + name
+}
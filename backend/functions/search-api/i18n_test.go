@@ -0,0 +1,274 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func TestRequestLanguage(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    map[string]string
+		header   string
+		expected string
+	}{
+		{name: "Swiss French", header: "fr-CH, fr;q=0.9, en;q=0.8", expected: "fr"},
+		{name: "Quality wins", header: "en;q=0.5, it;q=0.8", expected: "it"},
+		{name: "Unsupported languages are skipped", header: "es-ES, de-CH;q=0.7", expected: "de"},
+		{name: "lang parameter wins", query: map[string]string{"lang": "IT"}, header: "fr-CH", expected: "it"},
+		{name: "Unsupported lang parameter", query: map[string]string{"lang": "es"}, header: "en", expected: "en"},
+		{name: "Nothing requested", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := events.APIGatewayProxyRequest{
+				QueryStringParameters: tt.query,
+				Headers:               map[string]string{"accept-language": tt.header},
+			}
+			if lang := requestLanguage(request); lang != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, lang)
+			}
+		})
+	}
+}
+
+func TestMessageText(t *testing.T) {
+	tests := []struct {
+		lang     string
+		key      string
+		args     []interface{}
+		expected string
+	}{
+		{lang: "de", key: msgCarNotFound, expected: "Fahrzeug nicht gefunden"},
+		{lang: "fr", key: msgMinPriceRange, args: []interface{}{0, 10000000}, expected: "Le prix minimum doit être compris entre 0 et 10000000"},
+		{lang: "it", key: msgUnknownEquipment, args: []interface{}{"Schleudersitz"}, expected: `Equipaggiamento sconosciuto "Schleudersitz"`},
+		{lang: "de", key: msgUnknownField, args: []interface{}{"owner"}, expected: "Unbekanntes Feld: owner"},
+		{lang: "en", key: msgCarNotFound, expected: "Car not found"},
+		{lang: "", key: msgCarNotFound, expected: "Car not found"},
+		{lang: "fr", key: "something_new", expected: "something_new"},
+	}
+
+	for _, tt := range tests {
+		if result := messageText(tt.lang, tt.key, tt.args...); result != tt.expected {
+			t.Errorf("messageText(%s, %s): expected %q, got %q", tt.lang, tt.key, tt.expected, result)
+		}
+	}
+}
+
+func TestErrorResponse(t *testing.T) {
+	response := errorResponse(http.StatusBadRequest, languageHeaders("de"), "de", msgValidationFailed,
+		newValidationError("min_price", msgMinPriceRange, MinPrice, MaxPrice),
+		newValidationError("equipment", msgUnknownEquipment, "Schleudersitz"),
+	)
+
+	var body ErrorResponse
+	if err := json.Unmarshal([]byte(response.Body), &body); err != nil {
+		t.Fatalf("Invalid body: %v", err)
+	}
+	expected := []string{
+		"Mindestpreis muss zwischen 0 und 10000000 liegen",
+		`Unbekannte Ausstattung "Schleudersitz"`,
+	}
+	if body.Error != "Validierung fehlgeschlagen" || body.Code != msgValidationFailed || len(body.Validations) != len(expected) {
+		t.Fatalf("Unexpected body %+v", body)
+	}
+	for i, message := range expected {
+		if body.Validations[i].Message != message {
+			t.Errorf("Expected %q, got %q", message, body.Validations[i].Message)
+		}
+	}
+	if response.Headers["Vary"] != "Accept-Language" || response.Headers["Content-Language"] != "de" {
+		t.Errorf("Unexpected language headers %v", response.Headers)
+	}
+
+	// Without a language the messages are English
+	response = errorResponse(http.StatusNotFound, languageHeaders(""), "", msgCarNotFound)
+	if !strings.Contains(response.Body, `"error":"Car not found"`) || response.Headers["Content-Language"] != "" {
+		t.Errorf("Unexpected response %v: %s", response.Headers, response.Body)
+	}
+}
+
+func TestCatalogsAreComplete(t *testing.T) {
+	placeholder := regexp.MustCompile(`%[dsq]`)
+	for key, texts := range messageCatalog {
+		source, ok := texts["en"]
+		if !ok {
+			t.Errorf("%s has no English text", key)
+			continue
+		}
+		sourceVerbs := strings.Join(placeholder.FindAllString(source, -1), "")
+		for _, lang := range []string{"de", "fr", "it"} {
+			translation, ok := texts[lang]
+			if !ok {
+				t.Errorf("%s has no %s translation", key, lang)
+				continue
+			}
+			if verbs := strings.Join(placeholder.FindAllString(translation, -1), ""); verbs != sourceVerbs {
+				t.Errorf("%s translation of %s has placeholders %q instead of %q", lang, key, verbs, sourceVerbs)
+			}
+		}
+	}
+
+	for value, labels := range enumLabels {
+		for _, lang := range []string{"fr", "it", "en"} {
+			if _, ok := labels[lang]; !ok {
+				t.Errorf("%q has no %s label", value, lang)
+			}
+		}
+	}
+
+	for _, vocabulary := range []*enumVocabulary{carTypeVocabulary, transmissionVocabulary, fuelVocabulary, driveVocabulary} {
+		for _, value := range vocabulary.values {
+			if _, ok := enumLabels[value.Label]; !ok {
+				t.Errorf("%q has no labels", value.Label)
+			}
+		}
+	}
+
+	for _, feature := range equipmentVocabulary {
+		for _, lang := range []string{"fr", "it", "en"} {
+			if _, ok := equipmentLabels[feature.Code][lang]; !ok {
+				t.Errorf("Equipment %s has no %s label", feature.Code, lang)
+			}
+		}
+	}
+}
+
+func TestHandleRequestLocalized(t *testing.T) {
+	setTestCars(t, []Car{{
+		ID:             1,
+		Title:          "BMW 520d",
+		Brand:          "BMW",
+		CarType:        "Limousine",
+		Transmission:   "Automatik",
+		Fuel:           "Diesel",
+		Drive:          "Allrad",
+		Equipment:      []string{"Sitzheizung", "Mild-Hybrid"},
+		EquipmentCodes: []string{"heated-seats"},
+		Status:         StatusAvailable,
+	}})
+
+	request := func(method, resource, body, lang string) events.APIGatewayProxyResponse {
+		response, err := handleRequest(context.Background(), events.APIGatewayProxyRequest{
+			HTTPMethod: method,
+			Resource:   resource,
+			Body:       body,
+			Headers:    map[string]string{"Accept-Language": lang},
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return response
+	}
+
+	// Filters keep the internal values in every language
+	response := request("POST", "/search", `{"transmission": "Automatik"}`, "fr-CH")
+	if response.Headers["Content-Language"] != "fr" || !strings.Contains(response.Headers["Vary"], "Accept-Language") {
+		t.Errorf("Unexpected headers %v", response.Headers)
+	}
+	var result struct {
+		Cars []struct {
+			Transmission string            `json:"transmission"`
+			Equipment    []string          `json:"equipment"`
+			Labels       map[string]string `json:"labels"`
+		} `json:"cars"`
+	}
+	if err := json.Unmarshal([]byte(response.Body), &result); err != nil || len(result.Cars) != 1 {
+		t.Fatalf("Unexpected body %s", response.Body)
+	}
+	car := result.Cars[0]
+	if car.Transmission != "Automatik" || car.Labels["transmission"] != "Automatique" || car.Labels["car_type"] != "Berline" || car.Labels["status"] != "Disponible" {
+		t.Errorf("Unexpected car %+v", car)
+	}
+	if !slicesEqual(car.Equipment, []string{"Sièges chauffants", "Mild-Hybrid"}) {
+		t.Errorf("Unexpected equipment %v", car.Equipment)
+	}
+
+	// Labels only cover the selected fields
+	projected := request("POST", "/search", `{"fields": ["fuel"]}`, "it")
+	if !strings.Contains(projected.Body, `"labels":{"fuel":"Diesel"}`) {
+		t.Errorf("Expected only the fuel label, got %s", projected.Body)
+	}
+
+	options := request("GET", "/search/options", "", "en")
+	var localizedOptions SearchOptions
+	if err := json.Unmarshal([]byte(options.Body), &localizedOptions); err != nil {
+		t.Fatalf("Unexpected body %s", options.Body)
+	}
+	if localizedOptions.Labels["Allrad"] != "All-wheel drive" || localizedOptions.Equipment[0].Label != "Heated seats" || localizedOptions.Drives[0] != "Allrad" {
+		t.Errorf("Unexpected options %+v", localizedOptions)
+	}
+	// The shared options are not modified
+	if getSearchOptions().Equipment[0].Label != "Sitzheizung" {
+		t.Error("Expected the precomputed options to stay German")
+	}
+
+	invalid := request("POST", "/search", `{"min_price": -1}`, "de")
+	if !strings.Contains(invalid.Body, `"error":"Validierung fehlgeschlagen"`) || !strings.Contains(invalid.Body, "Mindestpreis muss zwischen 0 und") {
+		t.Errorf("Expected German validation messages, got %s", invalid.Body)
+	}
+
+	// Without a language nothing changes
+	plain := request("POST", "/search", `{}`, "")
+	if strings.Contains(plain.Body, "labels") || plain.Headers["Content-Language"] != "" {
+		t.Errorf("Expected untranslated response, got %s", plain.Body)
+	}
+}
+
+func TestCompareAndSimilarLocalized(t *testing.T) {
+	car := func(id int, title string, equipment ...string) Car {
+		return Car{ID: id, Title: title, Brand: "BMW", PriceCHF: 40000, CarType: "Kombi", Transmission: "Automatik",
+			Fuel: "Benzin", Drive: "Allrad", Equipment: equipment, Status: StatusAvailable}
+	}
+	setTestCars(t, []Car{car(1, "BMW 320i Touring", "Sitzheizung"), car(2, "BMW 330i Touring", "Sitzheizung", "Anhängerkupplung")})
+
+	response, _ := handleRequest(context.Background(), events.APIGatewayProxyRequest{
+		HTTPMethod: "POST",
+		Resource:   "/cars/compare",
+		Body:       `{"car_ids": [1, 2]}`,
+		Headers:    map[string]string{"Accept-Language": "it"},
+	})
+	var comparison CompareResponse
+	if err := json.Unmarshal([]byte(response.Body), &comparison); err != nil {
+		t.Fatalf("Unexpected body %s", response.Body)
+	}
+	if comparison.Cars[0].Labels["fuel"] != "Benzina" || comparison.Cars[0].Fuel != "Benzin" {
+		t.Errorf("Unexpected car %+v", comparison.Cars[0])
+	}
+	for _, field := range comparison.Fields {
+		switch field.Field {
+		case "car_type":
+			if !slicesEqual(field.Labels, []string{"Station wagon", "Station wagon"}) {
+				t.Errorf("Unexpected car_type labels %v", field.Labels)
+			}
+		case "title":
+			if field.Labels != nil {
+				t.Errorf("Expected no labels for the title, got %v", field.Labels)
+			}
+		}
+	}
+	if !slicesEqual(comparison.Equipment.Shared, []string{"Sedili riscaldati"}) || !slicesEqual(comparison.Equipment.Unique[2], []string{"Gancio di traino"}) {
+		t.Errorf("Unexpected equipment %+v", comparison.Equipment)
+	}
+
+	response, _ = handleRequest(context.Background(), events.APIGatewayProxyRequest{
+		HTTPMethod:     "GET",
+		Resource:       "/cars/{id}/similar",
+		PathParameters: map[string]string{"id": "1"},
+		Headers:        map[string]string{"Accept-Language": "fr"},
+	})
+	var similar SimilarCarsResponse
+	if err := json.Unmarshal([]byte(response.Body), &similar); err != nil || len(similar.Cars) != 1 {
+		t.Fatalf("Unexpected body %s", response.Body)
+	}
+	if similar.Cars[0].Labels["drive"] != "Intégrale" || !slicesEqual(similar.Cars[0].Equipment, []string{"Sièges chauffants", "Attelage"}) {
+		t.Errorf("Unexpected similar car %+v", similar.Cars[0])
+	}
+}
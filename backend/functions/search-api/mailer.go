@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ses"
+	"github.com/aws/aws-sdk-go/service/ses/sesiface"
+)
+
+// MailMessage is an HTML email to a single recipient
+type MailMessage struct {
+	To      string
+	Subject string
+	HTML    string
+}
+
+// Mailer sends emails to customers
+type Mailer interface {
+	Send(ctx context.Context, message MailMessage) error
+}
+
+// sesMailer sends emails through SES, like the contact form
+type sesMailer struct {
+	client sesiface.SESAPI
+	sender string
+}
+
+func newSESMailer(sender string) *sesMailer {
+	sess := session.Must(session.NewSession())
+	return &sesMailer{client: ses.New(sess), sender: sender}
+}
+
+func (m *sesMailer) Send(ctx context.Context, message MailMessage) error {
+	_, err := m.client.SendEmailWithContext(ctx, &ses.SendEmailInput{
+		Destination: &ses.Destination{
+			ToAddresses: []*string{aws.String(message.To)},
+		},
+		Message: &ses.Message{
+			Body: &ses.Body{
+				Html: &ses.Content{
+					Charset: aws.String("UTF-8"),
+					Data:    aws.String(message.HTML),
+				},
+			},
+			Subject: &ses.Content{
+				Charset: aws.String("UTF-8"),
+				Data:    aws.String(message.Subject),
+			},
+		},
+		Source: aws.String(m.sender),
+	})
+	if err != nil {
+		return fmt.Errorf("error sending email: %w", err)
+	}
+	return nil
+}
+
+// memoryMailer records sent emails. It is used in tests and for local development.
+type memoryMailer struct {
+	mu   sync.Mutex
+	sent []MailMessage
+	err  error // Returned by Send instead of recording the message
+}
+
+func (m *memoryMailer) Send(ctx context.Context, message MailMessage) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.err != nil {
+		return m.err
+	}
+	m.sent = append(m.sent, message)
+	return nil
+}
+
+// Sent returns the recorded emails
+func (m *memoryMailer) Sent() []MailMessage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]MailMessage(nil), m.sent...)
+}
@@ -3,15 +3,19 @@ package main
 import (
 	"context"
 	_ "embed"
+	"encoding/base64"
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"html"
+	"io"
 	"log"
 	"net/http"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
@@ -32,6 +36,7 @@ const (
 	MaxMileage      = 2000000 // 2M km should be enough
 	MinPower        = 0
 	MaxPower        = 2000 // 2000 HP should be enough
+	MinYear         = 1900 // Up to next year, see maxRegistrationYear
 )
 
 // Regular expressions for validation
@@ -42,46 +47,86 @@ var (
 
 // Car represents a single car entry
 type Car struct {
-	ID           int      `json:"id"`
-	Title        string   `json:"title"`
-	Brand        string   `json:"brand"`
-	PriceCHF     int      `json:"price_chf"`
-	LeasingText  string   `json:"leasing_text"`
-	FirstReg     string   `json:"first_registration"`
-	CarType      string   `json:"car_type"`
-	MileageKM    int      `json:"mileage_km"`
-	Transmission string   `json:"transmission"`
-	Fuel         string   `json:"fuel"`
-	Drive        string   `json:"drive"`
-	PowerHP      int      `json:"power_hp"`
-	PowerKW      int      `json:"power_kw"`
-	MFK          bool     `json:"mfk"`
-	Warranty     bool     `json:"warranty"`
-	WarrantyText string   `json:"warranty_text"`
-	Equipment    []string `json:"equipment"`
-	Description  string   `json:"description"`
-	ImageURLs    []string `json:"image_urls"`
+	ID           int        `json:"id"`
+	Title        string     `json:"title"`
+	Brand        string     `json:"brand"`
+	Model        string     `json:"model"`
+	PriceCHF     int        `json:"price_chf"`
+	LeasingText  string     `json:"leasing_text"`
+	FirstReg     string     `json:"first_registration"`
+	FirstRegYear int        `json:"first_registration_year,omitempty"`
+	CarType      string     `json:"car_type"`
+	MileageKM    int        `json:"mileage_km"`
+	Transmission string     `json:"transmission"`
+	Fuel         string     `json:"fuel"`
+	Drive        string     `json:"drive"`
+	PowerHP      int        `json:"power_hp"`
+	PowerKW      int        `json:"power_kw"`
+	MFK          bool       `json:"mfk"`
+	Warranty     bool       `json:"warranty"`
+	WarrantyText string     `json:"warranty_text"`
+	Equipment    []string   `json:"equipment"`
+	Description  string     `json:"description"`
+	ImageURLs    []string   `json:"image_urls"`
+	Status       string     `json:"status"`
+	ReservedAt   *time.Time `json:"reserved_at,omitempty"`
+	SoldAt       *time.Time `json:"sold_at,omitempty"`
+
+	// Structured values parsed from LeasingText and WarrantyText
+	LeasingRateCHF     int    `json:"leasing_rate_chf,omitempty"`
+	LeasingDownPayment *bool  `json:"leasing_down_payment,omitempty"`
+	WarrantyMonths     int    `json:"warranty_months,omitempty"`
+	WarrantyKM         int    `json:"warranty_km,omitempty"`
+	WarrantyStart      string `json:"warranty_start,omitempty"`
+
+	// Codes of the canonical equipment features, see equipmentVocabulary
+	EquipmentCodes []string `json:"equipment_codes,omitempty"`
+
+	// Codes of the canonical car type, transmission, fuel and drive, see
+	// enums.go. Values outside the vocabularies have no code.
+	CarTypeCode      string `json:"car_type_code,omitempty"`
+	TransmissionCode string `json:"transmission_code,omitempty"`
+	FuelCode         string `json:"fuel_code,omitempty"`
+	DriveCode        string `json:"drive_code,omitempty"`
 }
 
 // SearchOptions represents available search filter options
 type SearchOptions struct {
-	Brands        []string `json:"brands"`
-	CarTypes      []string `json:"car_types"`
-	Transmissions []string `json:"transmissions"`
-	Fuels         []string `json:"fuels"`
-	Drives        []string `json:"drives"`
-	MinPrice      int      `json:"min_price"`
-	MaxPrice      int      `json:"max_price"`
-	MinMileage    int      `json:"min_mileage"`
-	MaxMileage    int      `json:"max_mileage"`
-	MinPower      int      `json:"min_power"`
-	MaxPower      int      `json:"max_power"`
+	Brands        []string            `json:"brands"`
+	Models        map[string][]string `json:"models"` // Models by brand
+	CarTypes      []string            `json:"car_types"`
+	Transmissions []string            `json:"transmissions"`
+	Fuels         []string            `json:"fuels"`
+	Drives        []string            `json:"drives"`
+	MinPrice      int                 `json:"min_price"`
+	MaxPrice      int                 `json:"max_price"`
+	MinMileage    int                 `json:"min_mileage"`
+	MaxMileage    int                 `json:"max_mileage"`
+	MinPower      int                 `json:"min_power"`
+	MaxPower      int                 `json:"max_power"`
+	MinYear       int                 `json:"min_year"`
+	MaxYear       int                 `json:"max_year"`
+	MFK           bool                `json:"mfk"`
+	Warranty      bool                `json:"warranty"`
+
+	Equipment []EquipmentOption `json:"equipment"`
+
+	// Codes and labels of the car types, transmissions, fuels and drives
+	CarTypeOptions      []EnumOption `json:"car_type_options"`
+	TransmissionOptions []EnumOption `json:"transmission_options"`
+	FuelOptions         []EnumOption `json:"fuel_options"`
+	DriveOptions        []EnumOption `json:"drive_options"`
+
+	// Translated labels of the car types, transmissions, fuels and drives,
+	// only present when a language was requested
+	Labels map[string]string `json:"labels,omitempty"`
 }
 
 // SearchRequest represents search parameters
 type SearchRequest struct {
 	Query        string `json:"query,omitempty"`
 	Brand        string `json:"brand,omitempty"`
+	Model        string `json:"model,omitempty"`
 	CarType      string `json:"car_type,omitempty"`
 	Transmission string `json:"transmission,omitempty"`
 	Fuel         string `json:"fuel,omitempty"`
@@ -92,31 +137,59 @@ type SearchRequest struct {
 	MaxMileage   *int   `json:"max_mileage,omitempty"`
 	MinPower     *int   `json:"min_power,omitempty"`
 	MaxPower     *int   `json:"max_power,omitempty"`
-	Limit        int    `json:"limit,omitempty"`
-	Offset       int    `json:"offset,omitempty"`
+	MinYear      *int   `json:"min_year,omitempty"`
+	MaxYear      *int   `json:"max_year,omitempty"`
+	MFK          *bool  `json:"mfk,omitempty"`
+	Warranty     *bool  `json:"warranty,omitempty"`
+	IncludeSold  bool   `json:"include_sold,omitempty"`
+
+	// Equipment feature codes, labels or synonyms; every feature is required
+	Equipment []string `json:"equipment,omitempty"`
+
+	// Filters on the structured leasing and warranty values
+	MaxLeasingRate            *int `json:"max_leasing_rate,omitempty"`
+	LeasingWithoutDownPayment bool `json:"leasing_without_down_payment,omitempty"`
+	MinWarrantyMonths         *int `json:"min_warranty_months,omitempty"`
+
+	// Car attributes in the response: JSON names or the "card" and "detail" projections
+	Fields []string `json:"fields,omitempty"`
+
+	// Sorting and pagination: either Offset or the next_cursor of the previous page
+	Sort   string `json:"sort,omitempty"`
+	Limit  int    `json:"limit,omitempty"`
+	Offset int    `json:"offset,omitempty"`
+	Cursor string `json:"cursor,omitempty"`
 }
 
 // SearchResponse represents search results
 type SearchResponse struct {
-	Cars   []Car `json:"cars"`
-	Total  int   `json:"total"`
-	Limit  int   `json:"limit"`
-	Offset int   `json:"offset"`
+	Cars       []Car  `json:"cars"`
+	Total      int    `json:"total"`
+	Limit      int    `json:"limit"`
+	Offset     int    `json:"offset"`
+	NextCursor string `json:"next_cursor,omitempty"`
+
+	// Limits the serialized car attributes to SearchRequest.Fields
+	projection *carProjection
+	// Language of the car labels, "" for none
+	lang string
 }
 
-// ValidationError represents a validation error
+// ValidationError represents a validation error. Code and Params are the
+// message key and its arguments, see messageCatalog.
 type ValidationError struct {
-	Field   string `json:"field"`
-	Message string `json:"message"`
+	Field   string        `json:"field"`
+	Message string        `json:"message"`
+	Code    string        `json:"code,omitempty"`
+	Params  []interface{} `json:"params,omitempty"`
 }
 
 type ErrorResponse struct {
 	Error       string            `json:"error"`
+	Code        string            `json:"code,omitempty"`
 	Validations []ValidationError `json:"validations,omitempty"`
 }
 
-var cars []Car
-
 // sanitizeString removes potentially dangerous characters and HTML-escapes the result
 func sanitizeString(s string) string {
 	// Trim whitespace
@@ -143,6 +216,11 @@ func validateIntRange(value, min, max int) bool {
 	return value >= min && value <= max
 }
 
+// maxRegistrationYear is the latest accepted first registration year; dealers list cars of next year's model early
+func maxRegistrationYear() int {
+	return time.Now().Year() + 1
+}
+
 // validateSearchRequest validates and sanitizes the search request
 func validateSearchRequest(req *SearchRequest) []ValidationError {
 	var errors []ValidationError
@@ -150,16 +228,10 @@ func validateSearchRequest(req *SearchRequest) []ValidationError {
 	// Validate and sanitize query
 	if req.Query != "" {
 		if len(req.Query) > MaxQueryLength {
-			errors = append(errors, ValidationError{
-				Field:   "query",
-				Message: fmt.Sprintf("Query too long, maximum %d characters", MaxQueryLength),
-			})
+			errors = append(errors, newValidationError("query", msgQueryTooLong, MaxQueryLength))
 		}
 		if !validateString(req.Query, alphanumericRegex) {
-			errors = append(errors, ValidationError{
-				Field:   "query",
-				Message: "Query contains invalid characters",
-			})
+			errors = append(errors, newValidationError("query", msgQueryInvalid))
 		}
 		req.Query = sanitizeString(req.Query)
 	}
@@ -167,118 +239,133 @@ func validateSearchRequest(req *SearchRequest) []ValidationError {
 	// Validate and sanitize brand
 	if req.Brand != "" {
 		if !validateString(req.Brand, alphanumericRegex) {
-			errors = append(errors, ValidationError{
-				Field:   "brand",
-				Message: "Brand contains invalid characters",
-			})
+			errors = append(errors, newValidationError("brand", msgBrandInvalid))
 		}
 		req.Brand = sanitizeString(req.Brand)
 	}
 
-	// Validate car type (must be from predefined list)
-	if req.CarType != "" {
-		req.CarType = sanitizeString(req.CarType)
+	// Validate and sanitize model
+	if req.Model != "" {
+		if !validateString(req.Model, alphanumericRegex) {
+			errors = append(errors, newValidationError("model", msgModelInvalid))
+		}
+		req.Model = sanitizeString(req.Model)
 	}
 
-	// Validate transmission (must be from predefined list)
+	// Normalize car type, transmission, fuel and drive to their codes; labels
+	// and synonyms such as "Diesel" or "Petrol" are accepted as well
+	if req.CarType != "" {
+		req.CarType = carTypeVocabulary.FilterValue(sanitizeString(req.CarType))
+	}
 	if req.Transmission != "" {
-		req.Transmission = sanitizeString(req.Transmission)
+		req.Transmission = transmissionVocabulary.FilterValue(sanitizeString(req.Transmission))
 	}
-
-	// Validate fuel (must be from predefined list)
 	if req.Fuel != "" {
-		req.Fuel = sanitizeString(req.Fuel)
+		req.Fuel = fuelVocabulary.FilterValue(sanitizeString(req.Fuel))
 	}
-
-	// Validate drive (must be from predefined list)
 	if req.Drive != "" {
-		req.Drive = sanitizeString(req.Drive)
+		req.Drive = driveVocabulary.FilterValue(sanitizeString(req.Drive))
 	}
 
 	// Validate price ranges
 	if req.MinPrice != nil {
 		if !validateIntRange(*req.MinPrice, MinPrice, MaxPrice) {
-			errors = append(errors, ValidationError{
-				Field:   "min_price",
-				Message: fmt.Sprintf("Min price must be between %d and %d", MinPrice, MaxPrice),
-			})
+			errors = append(errors, newValidationError("min_price", msgMinPriceRange, MinPrice, MaxPrice))
 		}
 	}
 	if req.MaxPrice != nil {
 		if !validateIntRange(*req.MaxPrice, MinPrice, MaxPrice) {
-			errors = append(errors, ValidationError{
-				Field:   "max_price",
-				Message: fmt.Sprintf("Max price must be between %d and %d", MinPrice, MaxPrice),
-			})
+			errors = append(errors, newValidationError("max_price", msgMaxPriceRange, MinPrice, MaxPrice))
 		}
 	}
 
 	// Validate mileage ranges
 	if req.MinMileage != nil {
 		if !validateIntRange(*req.MinMileage, MinMileage, MaxMileage) {
-			errors = append(errors, ValidationError{
-				Field:   "min_mileage",
-				Message: fmt.Sprintf("Min mileage must be between %d and %d", MinMileage, MaxMileage),
-			})
+			errors = append(errors, newValidationError("min_mileage", msgMinMileageRange, MinMileage, MaxMileage))
 		}
 	}
 	if req.MaxMileage != nil {
 		if !validateIntRange(*req.MaxMileage, MinMileage, MaxMileage) {
-			errors = append(errors, ValidationError{
-				Field:   "max_mileage",
-				Message: fmt.Sprintf("Max mileage must be between %d and %d", MinMileage, MaxMileage),
-			})
+			errors = append(errors, newValidationError("max_mileage", msgMaxMileageRange, MinMileage, MaxMileage))
 		}
 	}
 
 	// Validate power ranges
 	if req.MinPower != nil {
 		if !validateIntRange(*req.MinPower, MinPower, MaxPower) {
-			errors = append(errors, ValidationError{
-				Field:   "min_power",
-				Message: fmt.Sprintf("Min power must be between %d and %d", MinPower, MaxPower),
-			})
+			errors = append(errors, newValidationError("min_power", msgMinPowerRange, MinPower, MaxPower))
 		}
 	}
 	if req.MaxPower != nil {
 		if !validateIntRange(*req.MaxPower, MinPower, MaxPower) {
-			errors = append(errors, ValidationError{
-				Field:   "max_power",
-				Message: fmt.Sprintf("Max power must be between %d and %d", MinPower, MaxPower),
-			})
+			errors = append(errors, newValidationError("max_power", msgMaxPowerRange, MinPower, MaxPower))
+		}
+	}
+
+	// Validate first registration years
+	maxYear := maxRegistrationYear()
+	if req.MinYear != nil {
+		if !validateIntRange(*req.MinYear, MinYear, maxYear) {
+			errors = append(errors, newValidationError("min_year", msgMinYearRange, MinYear, maxYear))
+		}
+	}
+	if req.MaxYear != nil {
+		if !validateIntRange(*req.MaxYear, MinYear, maxYear) {
+			errors = append(errors, newValidationError("max_year", msgMaxYearRange, MinYear, maxYear))
 		}
 	}
 
+	// Validate equipment features and replace them by their codes
+	if len(req.Equipment) > MaxEquipmentFilter {
+		errors = append(errors, newValidationError("equipment", msgTooManyEquipment, MaxEquipmentFilter))
+	} else {
+		for i, item := range req.Equipment {
+			feature, ok := lookupEquipment(item)
+			if !ok {
+				errors = append(errors, newValidationError("equipment", msgUnknownEquipment, sanitizeString(item)))
+				continue
+			}
+			req.Equipment[i] = feature.Code
+		}
+	}
+
+	// Validate leasing and warranty filters
+	if req.MaxLeasingRate != nil {
+		if !validateIntRange(*req.MaxLeasingRate, MinPrice, MaxPrice) {
+			errors = append(errors, newValidationError("max_leasing_rate", msgMaxLeasingRateRange, MinPrice, MaxPrice))
+		}
+	}
+	if req.MinWarrantyMonths != nil {
+		if !validateIntRange(*req.MinWarrantyMonths, 0, MaxWarrantyMonths) {
+			errors = append(errors, newValidationError("min_warranty_months", msgMinWarrantyRange, MaxWarrantyMonths))
+		}
+	}
+
+	errors = append(errors, validateFields(req)...)
+
 	// Validate limit and offset
-	if req.Limit < 0 || req.Limit > MaxLimit {
+	if req.Limit <= 0 || req.Limit > MaxLimit {
 		req.Limit = 10 // Set to default
 	}
 	if req.Offset < 0 || req.Offset > MaxOffset {
 		req.Offset = 0 // Set to default
 	}
 
+	// Validate sort and cursor last, the cursor is bound to the sanitized filters
+	errors = append(errors, validateSearchCursor(req)...)
+
 	return errors
 }
 
-// extractBrandFromTitle extracts the brand from a car title
-func extractBrandFromTitle(title string) string {
-	// Remove common prefixes and clean the title
-	title = strings.TrimSpace(title)
-
-	// Split by space and take the first word as brand
-	parts := strings.Fields(title)
-	if len(parts) == 0 {
-		return ""
-	}
-
-	brand := parts[0]
-
-	// Handle special cases like "Mercedes-Benz"
-	if strings.ToLower(brand) == "mercedes-benz" || strings.ToLower(brand) == "mercedes" {
-		return "Mercedes-Benz"
+// parseFirstRegistrationYear returns the year of a "MM.YYYY" or "M.YYYY"
+// first registration, or 0 if the value has another format
+func parseFirstRegistrationYear(firstReg string) int {
+	parsed, err := time.Parse("1.2006", strings.TrimSpace(firstReg))
+	if err != nil {
+		return 0
 	}
-
-	return brand
+	return parsed.Year()
 }
 
 // normalizeString removes diacritics and converts to lowercase for comparison
@@ -311,6 +398,18 @@ func normalizeString(s string) string {
 	return result.String()
 }
 
+// lookupKey normalizes a name for the vocabularies of equipment, enums and
+// brands, so that "LED-Scheinwerfer" and "led scheinwerfer", "Rückfahrkamera"
+// and "Rueckfahrkamera" or "360° Kamera" and "360 Kamera" compare equal
+func lookupKey(name string) string {
+	name = umlautReplacer.Replace(strings.ToLower(name))
+	name = strings.NewReplacer("-", " ", "_", " ", "/", " ", "°", " ").Replace(normalizeString(name))
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// umlautReplacer spells umlauts the way they are typed without a Swiss keyboard
+var umlautReplacer = strings.NewReplacer("ä", "ae", "ö", "oe", "ü", "ue", "ß", "ss")
+
 // brandMatches checks if a brand matches the search term (case-insensitive, partial)
 func brandMatches(brand, searchTerm string) bool {
 	if searchTerm == "" {
@@ -323,71 +422,183 @@ func brandMatches(brand, searchTerm string) bool {
 	return strings.Contains(normalizedBrand, normalizedSearch)
 }
 
+// csvColumns lists the known autos.csv columns in their canonical order.
+// Columns are looked up by header name, so the sheet may be reordered and may
+// contain additional columns. Required columns must be present in the header;
+// optional columns are treated as empty when missing.
+var csvColumns = []struct {
+	Name     string
+	Required bool
+}{
+	{"id", true},
+	{"title", true},
+	{"price_chf", true},
+	{"leasing_text", false},
+	{"first_registration", true},
+	{"car_type", true},
+	{"mileage_km", true},
+	{"transmission", true},
+	{"fuel", true},
+	{"drive", true},
+	{"power_hp", true},
+	{"power_kw", true},
+	{"mfk", false},
+	{"warranty", false},
+	{"warranty_text", false},
+	{"equipment", false},
+	{"description", false},
+	{"image_urls", false},
+	{"status", false},
+	{"reserved_at", false},
+	{"sold_at", false},
+}
+
+// csvHeader maps CSV column names to their index in a record
+type csvHeader map[string]int
+
+// defaultCSVHeader is the header of a CSV file using the canonical column order
+var defaultCSVHeader = func() csvHeader {
+	header := make(csvHeader, len(csvColumns))
+	for i, column := range csvColumns {
+		header[column.Name] = i
+	}
+	return header
+}()
+
+// normalizeHeaderName trims a header cell and strips a UTF-8 BOM left by spreadsheet exports
+func normalizeHeaderName(name string) string {
+	name = strings.TrimPrefix(name, "\ufeff")
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// parseCSVHeader builds the column mapping from a header row and reports all missing required columns
+func parseCSVHeader(record []string) (csvHeader, error) {
+	header := make(csvHeader, len(record))
+	for i, name := range record {
+		name = normalizeHeaderName(name)
+		if name == "" {
+			continue
+		}
+		if _, exists := header[name]; exists {
+			return nil, fmt.Errorf("duplicate CSV column %q", name)
+		}
+		header[name] = i
+	}
+
+	var missing []string
+	for _, column := range csvColumns {
+		if _, ok := header[column.Name]; column.Required && !ok {
+			missing = append(missing, column.Name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing required CSV columns: %s", strings.Join(missing, ", "))
+	}
+
+	return header, nil
+}
+
+// value returns the value of the named column, or an empty string if the column is not present
+func (h csvHeader) value(record []string, name string) string {
+	i, ok := h[name]
+	if !ok || i >= len(record) {
+		return ""
+	}
+	return record[i]
+}
+
 // loadCarsFromCSV loads car data from the embedded autos.csv file
 func loadCarsFromCSV() error {
-	reader := csv.NewReader(strings.NewReader(csvContent))
+	loaded, err := parseCarsCSV(strings.NewReader(csvContent))
+	if err != nil {
+		return err
+	}
+
+	catalogue.Store(loaded, catalogueVersion([]byte(csvContent)))
+	log.Printf("Loaded %d cars from embedded CSV", len(loaded))
+	return nil
+}
+
+// parseCarsCSV reads cars from CSV data with a header row
+func parseCarsCSV(r io.Reader) ([]Car, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1 // Row lengths are checked against the header in parseCarRecords
 	records, err := reader.ReadAll()
 	if err != nil {
-		return fmt.Errorf("error reading CSV: %w", err)
+		return nil, fmt.Errorf("error reading CSV: %w", err)
+	}
+	return parseCarRecords(records)
+}
+
+// parseCarRecords turns tabular rows, starting with a header row, into cars.
+// Rows that cannot be parsed are logged and skipped; a malformed header fails
+// the whole import.
+func parseCarRecords(records [][]string) ([]Car, error) {
+	if len(records) == 0 {
+		return nil, fmt.Errorf("missing header row")
+	}
+
+	header, err := parseCSVHeader(records[0])
+	if err != nil {
+		return nil, err
 	}
 
-	cars = make([]Car, 0, len(records)-1)
+	loaded := make([]Car, 0, len(records)-1)
 
 	for i, record := range records {
 		if i == 0 { // Skip header
 			continue
 		}
 
-		if len(record) != 18 {
-			log.Printf("Skipping row %d: expected 18 columns, got %d", i, len(record))
+		if len(record) != len(records[0]) {
+			log.Printf("Skipping row %d: expected %d columns, got %d", i, len(records[0]), len(record))
 			continue
 		}
 
-		car, err := parseCarRecord(record)
+		car, err := parseCarRecord(header, record)
 		if err != nil {
 			log.Printf("Error parsing row %d: %v", i, err)
 			continue
 		}
 
-		cars = append(cars, car)
+		loaded = append(loaded, car)
 	}
 
-	log.Printf("Loaded %d cars from embedded CSV", len(cars))
-	return nil
+	return loaded, nil
 }
 
-func parseCarRecord(record []string) (Car, error) {
-	id, err := strconv.Atoi(record[0])
+func parseCarRecord(header csvHeader, record []string) (Car, error) {
+	id, err := strconv.Atoi(header.value(record, "id"))
 	if err != nil {
 		return Car{}, fmt.Errorf("invalid id: %w", err)
 	}
 
-	priceCHF, err := strconv.Atoi(record[2])
+	priceCHF, err := parseSwissInt(header.value(record, "price_chf"))
 	if err != nil {
 		return Car{}, fmt.Errorf("invalid price: %w", err)
 	}
 
-	mileageKM, err := strconv.Atoi(record[6])
+	mileageKM, err := parseSwissInt(header.value(record, "mileage_km"))
 	if err != nil {
 		return Car{}, fmt.Errorf("invalid mileage: %w", err)
 	}
 
-	powerHP, err := strconv.Atoi(record[10])
+	powerHP, err := parseSwissInt(header.value(record, "power_hp"))
 	if err != nil {
 		return Car{}, fmt.Errorf("invalid power HP: %w", err)
 	}
 
-	powerKW, err := strconv.Atoi(record[11])
+	powerKW, err := parseSwissInt(header.value(record, "power_kw"))
 	if err != nil {
 		return Car{}, fmt.Errorf("invalid power KW: %w", err)
 	}
 
-	mfk := strings.ToLower(record[12]) == "true"
-	warranty := strings.ToLower(record[13]) == "true"
+	mfk := strings.ToLower(header.value(record, "mfk")) == "true"
+	warranty := strings.ToLower(header.value(record, "warranty")) == "true"
 
 	equipment := []string{}
-	if record[15] != "" {
-		equipmentParts := strings.Split(record[15], ";")
+	if value := header.value(record, "equipment"); value != "" {
+		equipmentParts := strings.Split(value, ";")
 		for _, part := range equipmentParts {
 			// Sanitize each equipment part
 			sanitized := sanitizeString(part)
@@ -396,10 +607,12 @@ func parseCarRecord(record []string) (Car, error) {
 			}
 		}
 	}
+	// Known items get their canonical label and become filterable
+	equipment, equipmentCodes := canonicalEquipment(equipment)
 
 	imageURLs := []string{}
-	if record[17] != "" {
-		imageParts := strings.Split(record[17], ";")
+	if value := header.value(record, "image_urls"); value != "" {
+		imageParts := strings.Split(value, ";")
 		for _, part := range imageParts {
 			// Basic URL validation - ensure it's a valid HTTP(S) URL
 			part = strings.TrimSpace(part)
@@ -410,34 +623,85 @@ func parseCarRecord(record []string) (Car, error) {
 		}
 	}
 
-	title := sanitizeString(record[1])
-	brand := extractBrandFromTitle(title)
+	status, err := parseCarStatus(header.value(record, "status"))
+	if err != nil {
+		return Car{}, err
+	}
+
+	reservedAt, err := parseStatusTime(header.value(record, "reserved_at"))
+	if err != nil {
+		return Car{}, fmt.Errorf("invalid reserved_at: %w", err)
+	}
+
+	soldAt, err := parseStatusTime(header.value(record, "sold_at"))
+	if err != nil {
+		return Car{}, fmt.Errorf("invalid sold_at: %w", err)
+	}
+
+	// Structured terms are parsed from the raw texts, before HTML escaping
+	leasing := parseLeasingText(header.value(record, "leasing_text"))
+	warrantyTerms := parseWarrantyText(header.value(record, "warranty_text"))
+
+	firstReg := sanitizeString(header.value(record, "first_registration"))
+
+	title := sanitizeString(header.value(record, "title"))
+	brand, model := extractBrandAndModel(title)
+
+	carType, carTypeCode := carTypeVocabulary.Canonical(sanitizeString(header.value(record, "car_type")))
+	transmission, transmissionCode := transmissionVocabulary.Canonical(sanitizeString(header.value(record, "transmission")))
+	fuel, fuelCode := fuelVocabulary.Canonical(sanitizeString(header.value(record, "fuel")))
+	drive, driveCode := driveVocabulary.Canonical(sanitizeString(header.value(record, "drive")))
 
 	return Car{
 		ID:           id,
 		Title:        title,
 		Brand:        brand,
+		Model:        model,
 		PriceCHF:     priceCHF,
-		LeasingText:  sanitizeString(record[3]),
-		FirstReg:     sanitizeString(record[4]),
-		CarType:      sanitizeString(record[5]),
+		LeasingText:  sanitizeString(header.value(record, "leasing_text")),
+		FirstReg:     firstReg,
+		FirstRegYear: parseFirstRegistrationYear(firstReg),
+		CarType:      carType,
 		MileageKM:    mileageKM,
-		Transmission: sanitizeString(record[7]),
-		Fuel:         sanitizeString(record[8]),
-		Drive:        sanitizeString(record[9]),
+		Transmission: transmission,
+		Fuel:         fuel,
+		Drive:        drive,
 		PowerHP:      powerHP,
 		PowerKW:      powerKW,
 		MFK:          mfk,
 		Warranty:     warranty,
-		WarrantyText: sanitizeString(record[14]),
+		WarrantyText: sanitizeString(header.value(record, "warranty_text")),
 		Equipment:    equipment,
-		Description:  sanitizeString(record[16]),
+		Description:  sanitizeString(header.value(record, "description")),
 		ImageURLs:    imageURLs,
+		Status:       status,
+		ReservedAt:   reservedAt,
+		SoldAt:       soldAt,
+
+		LeasingRateCHF:     leasing.RateCHF,
+		LeasingDownPayment: leasing.DownPayment,
+		WarrantyMonths:     warrantyTerms.Months,
+		WarrantyKM:         warrantyTerms.KM,
+		WarrantyStart:      warrantyTerms.Start,
+
+		EquipmentCodes: equipmentCodes,
+
+		CarTypeCode:      carTypeCode,
+		TransmissionCode: transmissionCode,
+		FuelCode:         fuelCode,
+		DriveCode:        driveCode,
 	}, nil
 }
 
+// getSearchOptions returns the options of the current catalogue, computed when it was loaded
 func getSearchOptions() SearchOptions {
+	return catalogue.Snapshot().Options
+}
+
+// buildSearchOptions collects the filter values of the available cars
+func buildSearchOptions(cars []Car) SearchOptions {
 	brands := make(map[string]bool)
+	models := make(map[string]map[string]bool)
 	carTypes := make(map[string]bool)
 	transmissions := make(map[string]bool)
 	fuels := make(map[string]bool)
@@ -446,10 +710,24 @@ func getSearchOptions() SearchOptions {
 	minPrice, maxPrice := 999999, 0
 	minMileage, maxMileage := 999999, 0
 	minPower, maxPower := 999, 0
+	minYear, maxYear := 9999, 0
+	hasMFK, hasWarranty := false, false
+	equipmentCounts := make(map[string]int)
 
 	for _, car := range cars {
+		// Options describe what can be bought right now
+		if car.Status != StatusAvailable {
+			continue
+		}
+
 		if car.Brand != "" {
 			brands[car.Brand] = true
+			if car.Model != "" {
+				if models[car.Brand] == nil {
+					models[car.Brand] = make(map[string]bool)
+				}
+				models[car.Brand][car.Model] = true
+			}
 		}
 		carTypes[car.CarType] = true
 		transmissions[car.Transmission] = true
@@ -476,10 +754,30 @@ func getSearchOptions() SearchOptions {
 		if car.PowerHP > maxPower {
 			maxPower = car.PowerHP
 		}
+
+		if car.FirstRegYear != 0 && car.FirstRegYear < minYear {
+			minYear = car.FirstRegYear
+		}
+		if car.FirstRegYear > maxYear {
+			maxYear = car.FirstRegYear
+		}
+
+		hasMFK = hasMFK || car.MFK
+		hasWarranty = hasWarranty || car.Warranty
+
+		for _, code := range car.EquipmentCodes {
+			equipmentCounts[code]++
+		}
+	}
+
+	// No car with a known year
+	if maxYear == 0 {
+		minYear = 0
 	}
 
 	options := SearchOptions{
 		Brands:        mapKeysToSlice(brands),
+		Models:        brandModels(models),
 		CarTypes:      mapKeysToSlice(carTypes),
 		Transmissions: mapKeysToSlice(transmissions),
 		Fuels:         mapKeysToSlice(fuels),
@@ -490,28 +788,46 @@ func getSearchOptions() SearchOptions {
 		MaxMileage:    maxMileage,
 		MinPower:      minPower,
 		MaxPower:      maxPower,
+		MinYear:       minYear,
+		MaxYear:       maxYear,
+		MFK:           hasMFK,
+		Warranty:      hasWarranty,
+		Equipment:     equipmentOptions(equipmentCounts),
 	}
+	options.CarTypeOptions = enumOptions(carTypeVocabulary, options.CarTypes)
+	options.TransmissionOptions = enumOptions(transmissionVocabulary, options.Transmissions)
+	options.FuelOptions = enumOptions(fuelVocabulary, options.Fuels)
+	options.DriveOptions = enumOptions(driveVocabulary, options.Drives)
 
 	return options
 }
 
+// mapKeysToSlice returns the keys in sorted order, so options are stable between calls
 func mapKeysToSlice(m map[string]bool) []string {
 	keys := make([]string, 0, len(m))
 	for k := range m {
 		keys = append(keys, k)
 	}
+	sort.Strings(keys)
 	return keys
 }
 
 func searchCars(req SearchRequest) SearchResponse {
+	sortName := searchSortName(req)
+	order, ok := searchSorts[sortName]
+	if !ok {
+		sortName, order = DefaultSort, searchSorts[DefaultSort]
+	}
+
 	filtered := make([]Car, 0)
 
-	for _, car := range cars {
+	for _, car := range currentCars() {
 		if matchesCriteria(car, req) {
 			filtered = append(filtered, car)
 		}
 	}
 
+	order.sortCars(filtered)
 	total := len(filtered)
 
 	// Apply pagination with validated limits
@@ -522,26 +838,49 @@ func searchCars(req SearchRequest) SearchResponse {
 		req.Offset = 0
 	}
 
+	// A cursor continues after the last car of the previous page, wherever it is now
 	start := req.Offset
+	if req.Cursor != "" {
+		req.Offset = 0
+		start = len(filtered)
+		if cursor, err := decodeCursor(req.Cursor); err == nil {
+			start = sort.Search(len(filtered), func(i int) bool { return order.after(filtered[i], cursor) })
+		}
+	}
 	end := start + req.Limit
 
+	var nextCursor string
 	if start >= len(filtered) {
 		filtered = []Car{}
-	} else if end > len(filtered) {
+	} else if end >= len(filtered) {
 		filtered = filtered[start:]
 	} else {
 		filtered = filtered[start:end]
+		last := filtered[len(filtered)-1]
+		nextCursor = encodeCursor(searchCursor{
+			Sort:    sortName,
+			Key:     order.key(last),
+			ID:      last.ID,
+			Filters: searchFilterHash(req),
+		})
 	}
 
 	return SearchResponse{
-		Cars:   filtered,
-		Total:  total,
-		Limit:  req.Limit,
-		Offset: req.Offset,
+		Cars:       filtered,
+		Total:      total,
+		Limit:      req.Limit,
+		Offset:     req.Offset,
+		NextCursor: nextCursor,
+		projection: newCarProjection(req.Fields),
 	}
 }
 
 func matchesCriteria(car Car, req SearchRequest) bool {
+	// Sold cars are hidden unless explicitly requested
+	if car.Status == StatusSold && !req.IncludeSold {
+		return false
+	}
+
 	// Text search in title and description
 	if req.Query != "" {
 		query := normalizeString(req.Query)
@@ -558,23 +897,28 @@ func matchesCriteria(car Car, req SearchRequest) bool {
 		return false
 	}
 
+	// Filter by model (case-insensitive, whole model name)
+	if req.Model != "" && !modelMatches(car.Model, req.Model) {
+		return false
+	}
+
 	// Filter by car type
-	if req.CarType != "" && car.CarType != req.CarType {
+	if req.CarType != "" && !carTypeVocabulary.Matches(car.CarTypeCode, car.CarType, req.CarType) {
 		return false
 	}
 
 	// Filter by transmission
-	if req.Transmission != "" && car.Transmission != req.Transmission {
+	if req.Transmission != "" && !transmissionVocabulary.Matches(car.TransmissionCode, car.Transmission, req.Transmission) {
 		return false
 	}
 
 	// Filter by fuel
-	if req.Fuel != "" && car.Fuel != req.Fuel {
+	if req.Fuel != "" && !fuelVocabulary.Matches(car.FuelCode, car.Fuel, req.Fuel) {
 		return false
 	}
 
 	// Filter by drive
-	if req.Drive != "" && car.Drive != req.Drive {
+	if req.Drive != "" && !driveVocabulary.Matches(car.DriveCode, car.Drive, req.Drive) {
 		return false
 	}
 
@@ -602,14 +946,48 @@ func matchesCriteria(car Car, req SearchRequest) bool {
 		return false
 	}
 
+	// Filter by first registration year; cars without a known year never match
+	if req.MinYear != nil && (car.FirstRegYear == 0 || car.FirstRegYear < *req.MinYear) {
+		return false
+	}
+	if req.MaxYear != nil && (car.FirstRegYear == 0 || car.FirstRegYear > *req.MaxYear) {
+		return false
+	}
+
+	// Filter by MFK and warranty
+	if req.MFK != nil && car.MFK != *req.MFK {
+		return false
+	}
+	if req.Warranty != nil && car.Warranty != *req.Warranty {
+		return false
+	}
+
+	// Filter by equipment; all features are required
+	if len(req.Equipment) > 0 && !hasEquipment(car, req.Equipment) {
+		return false
+	}
+
+	// Filter by leasing rate; cars without a known rate never match
+	if req.MaxLeasingRate != nil && (car.LeasingRateCHF == 0 || car.LeasingRateCHF > *req.MaxLeasingRate) {
+		return false
+	}
+	if req.LeasingWithoutDownPayment && (car.LeasingDownPayment == nil || *car.LeasingDownPayment) {
+		return false
+	}
+
+	// Filter by warranty duration
+	if req.MinWarrantyMonths != nil && (!car.Warranty || car.WarrantyMonths < *req.MinWarrantyMonths) {
+		return false
+	}
+
 	return true
 }
 
 func corsHeaders() map[string]string {
 	return map[string]string{
 		"Access-Control-Allow-Origin":  "*", // In production, restrict this to specific domains
-		"Access-Control-Allow-Methods": "GET, POST, OPTIONS",
-		"Access-Control-Allow-Headers": "Content-Type, Authorization",
+		"Access-Control-Allow-Methods": "GET, POST, PUT, PATCH, DELETE, OPTIONS",
+		"Access-Control-Allow-Headers": "Content-Type, Authorization, X-Api-Key",
 		"Content-Type":                 "application/json",
 		"X-Content-Type-Options":       "nosniff",
 		"X-Frame-Options":              "DENY",
@@ -617,116 +995,245 @@ func corsHeaders() map[string]string {
 	}
 }
 
+// handleEvent is the Lambda entry point. The scheduled saved search job sends
+// alerts, S3 notifications and other scheduled events reload the catalogue,
+// everything else is an API Gateway request.
+func handleEvent(ctx context.Context, raw json.RawMessage) (interface{}, error) {
+	if isSavedSearchAlertsEvent(raw) {
+		result, err := runSavedSearchAlerts(ctx, time.Now().UTC())
+		if err != nil {
+			log.Printf("Error running saved search alerts: %v", err)
+			return nil, err
+		}
+		return result, nil
+	}
+
+	if isCatalogueReloadEvent(raw) {
+		changed, err := catalogue.Reload(ctx)
+		if err != nil {
+			log.Printf("Error reloading catalogue: %v", err)
+			return nil, err
+		}
+		return map[string]bool{"reloaded": changed}, nil
+	}
+
+	var request events.APIGatewayProxyRequest
+	if err := json.Unmarshal(raw, &request); err != nil {
+		return nil, fmt.Errorf("unsupported event: %w", err)
+	}
+	return handleRequest(ctx, request)
+}
+
+// handleRequest decodes binary request bodies, routes the request and
+// compresses the response for clients that accept it
 func handleRequest(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	lang := requestLanguage(request)
+
+	// API Gateway passes bodies base64 encoded once binary media types are enabled
+	if request.IsBase64Encoded {
+		body, err := base64.StdEncoding.DecodeString(request.Body)
+		if err != nil {
+			return errorResponse(http.StatusBadRequest, languageHeaders(lang), lang, msgInvalidBody), nil
+		}
+		request.Body, request.IsBase64Encoded = string(body), false
+	}
+
+	response, err := routeRequest(ctx, request, lang)
+	if err != nil {
+		return response, err
+	}
+	return compressResponse(request, response), nil
+}
+
+// routeRequest dispatches an API Gateway request to its endpoint. lang is the
+// requested language, "" if the client asked for none.
+func routeRequest(ctx context.Context, request events.APIGatewayProxyRequest, lang string) (events.APIGatewayProxyResponse, error) {
+	catalogue.RefreshIfStale(ctx)
+
 	// Basic request validation
 	if len(request.Body) > 10000 { // 10KB limit for request body
-		return events.APIGatewayProxyResponse{
-			StatusCode: http.StatusRequestEntityTooLarge,
-			Headers:    corsHeaders(),
-			Body:       `{"error": "Request body too large"}`,
-		}, nil
+		return errorResponse(http.StatusRequestEntityTooLarge, languageHeaders(lang), lang, msgBodyTooLarge), nil
 	}
 
 	// Handle CORS preflight
 	if request.HTTPMethod == "OPTIONS" {
 		return events.APIGatewayProxyResponse{
 			StatusCode: http.StatusOK,
-			Headers:    corsHeaders(),
+			Headers:    languageHeaders(lang),
 		}, nil
 	}
 
-	headers := corsHeaders()
+	headers := languageHeaders(lang)
 
 	switch request.Resource {
 	case "/search/options":
 		if request.HTTPMethod != "GET" {
-			return events.APIGatewayProxyResponse{
-				StatusCode: http.StatusMethodNotAllowed,
-				Headers:    headers,
-				Body:       `{"error": "Method not allowed"}`,
-			}, nil
+			return errorResponse(http.StatusMethodNotAllowed, headers, lang, msgMethodNotAllowed), nil
 		}
 
-		options := getSearchOptions()
-		body, err := json.Marshal(options)
-		if err != nil {
-			log.Printf("Error marshaling search options: %v", err)
-			return events.APIGatewayProxyResponse{
-				StatusCode: http.StatusInternalServerError,
-				Headers:    headers,
-				Body:       `{"error": "Internal server error"}`,
-			}, nil
-		}
+		snapshot := catalogue.Snapshot()
+		return cachedResponse(request, headers, snapshot, "options:"+lang, nil, func() interface{} {
+			return localizeSearchOptions(getSearchOptions(), lang)
+		}), nil
 
-		return events.APIGatewayProxyResponse{
-			StatusCode: http.StatusOK,
-			Headers:    headers,
-			Body:       string(body),
-		}, nil
+	case "/search/suggest":
+		if request.HTTPMethod != "GET" {
+			return errorResponse(http.StatusMethodNotAllowed, headers, lang, msgMethodNotAllowed), nil
+		}
 
-	case "/search":
-		if request.HTTPMethod != "POST" {
-			return events.APIGatewayProxyResponse{
-				StatusCode: http.StatusMethodNotAllowed,
-				Headers:    headers,
-				Body:       `{"error": "Method not allowed"}`,
-			}, nil
+		query := strings.TrimSpace(request.QueryStringParameters["q"])
+		if len(query) > MaxSuggestQueryLength {
+			return errorResponse(http.StatusBadRequest, headers, lang, msgValidationFailed, newValidationError("q", msgQueryTooLong, MaxSuggestQueryLength)), nil
 		}
+		limit := parseSuggestLimit(request.QueryStringParameters["limit"])
+
+		snapshot := catalogue.Snapshot()
+		normalized := struct {
+			Query string
+			Limit int
+		}{query, limit}
+		return cachedResponse(request, headers, snapshot, "suggest:"+lang, normalized, func() interface{} {
+			return SuggestResponse{
+				Query:       query,
+				Suggestions: localizeSuggestions(snapshot.suggestions.Suggest(query, limit), lang),
+			}
+		}), nil
 
+	case "/search":
 		var searchReq SearchRequest
-		if err := json.Unmarshal([]byte(request.Body), &searchReq); err != nil {
-			log.Printf("Error unmarshaling search request: %v", err)
-			return events.APIGatewayProxyResponse{
-				StatusCode: http.StatusBadRequest,
-				Headers:    headers,
-				Body:       `{"error": "Invalid JSON body"}`,
-			}, nil
+		switch request.HTTPMethod {
+		case "GET":
+			// Same filters as the POST body, as query parameters for cacheable URLs
+			var queryErrors []ValidationError
+			searchReq, queryErrors = searchRequestFromQuery(request.QueryStringParameters, request.MultiValueQueryStringParameters)
+			if len(queryErrors) > 0 {
+				return errorResponse(http.StatusBadRequest, headers, lang, msgValidationFailed, queryErrors...), nil
+			}
+		case "POST":
+			if err := json.Unmarshal([]byte(request.Body), &searchReq); err != nil {
+				log.Printf("Error unmarshaling search request: %v", err)
+				return errorResponse(http.StatusBadRequest, headers, lang, msgInvalidJSON), nil
+			}
+		default:
+			return errorResponse(http.StatusMethodNotAllowed, headers, lang, msgMethodNotAllowed), nil
 		}
 
 		// Validate request
 		if validationErrors := validateSearchRequest(&searchReq); len(validationErrors) > 0 {
-			errorResponse := ErrorResponse{
-				Error:       "Validation failed",
-				Validations: validationErrors,
+			return errorResponse(http.StatusBadRequest, headers, lang, msgValidationFailed, validationErrors...), nil
+		}
+
+		// The validated request is normalized, so GET and POST share the ETag
+		snapshot := catalogue.Snapshot()
+		return cachedResponse(request, headers, snapshot, "search:"+lang, searchReq, func() interface{} {
+			response := searchCars(searchReq)
+			response.lang = lang
+			return response
+		}), nil
+
+	case "/cars/compare":
+		if request.HTTPMethod != "POST" {
+			return errorResponse(http.StatusMethodNotAllowed, headers, lang, msgMethodNotAllowed), nil
+		}
+
+		var compareReq CompareRequest
+		if err := json.Unmarshal([]byte(request.Body), &compareReq); err != nil {
+			log.Printf("Error unmarshaling compare request: %v", err)
+			return errorResponse(http.StatusBadRequest, headers, lang, msgInvalidJSON), nil
+		}
+		if validationErrors := validateCompareRequest(compareReq); len(validationErrors) > 0 {
+			return errorResponse(http.StatusBadRequest, headers, lang, msgValidationFailed, validationErrors...), nil
+		}
+
+		cars, missing := findCars(compareReq.CarIDs)
+		if len(missing) > 0 {
+			validations := make([]ValidationError, 0, len(missing))
+			for _, id := range missing {
+				validations = append(validations, newValidationError("car_ids", msgCarIDUnknown, id))
 			}
-			body, _ := json.Marshal(errorResponse)
-			return events.APIGatewayProxyResponse{
-				StatusCode: http.StatusBadRequest,
-				Headers:    headers,
-				Body:       string(body),
-			}, nil
+			return errorResponse(http.StatusNotFound, headers, lang, msgCarNotFound, validations...), nil
 		}
 
-		response := searchCars(searchReq)
-		body, err := json.Marshal(response)
-		if err != nil {
-			log.Printf("Error marshaling search response: %v", err)
-			return events.APIGatewayProxyResponse{
-				StatusCode: http.StatusInternalServerError,
-				Headers:    headers,
-				Body:       `{"error": "Internal server error"}`,
-			}, nil
+		return jsonResponse(http.StatusOK, headers, localizeComparison(compareCars(cars), lang)), nil
+
+	case "/financing/quote":
+		if request.HTTPMethod != "POST" {
+			return errorResponse(http.StatusMethodNotAllowed, headers, lang, msgMethodNotAllowed), nil
 		}
 
-		return events.APIGatewayProxyResponse{
-			StatusCode: http.StatusOK,
-			Headers:    headers,
-			Body:       string(body),
-		}, nil
+		var financingReq FinancingRequest
+		if err := json.Unmarshal([]byte(request.Body), &financingReq); err != nil {
+			log.Printf("Error unmarshaling financing request: %v", err)
+			return errorResponse(http.StatusBadRequest, headers, lang, msgInvalidJSON), nil
+		}
+
+		price := financingReq.PriceCHF
+		if financingReq.CarID != 0 {
+			car, ok := findCar(financingReq.CarID)
+			if !ok {
+				return errorResponse(http.StatusNotFound, headers, lang, msgCarNotFound), nil
+			}
+			price = car.PriceCHF
+		}
+
+		if validationErrors := validateFinancingRequest(financingReq, price); len(validationErrors) > 0 {
+			return errorResponse(http.StatusBadRequest, headers, lang, msgValidationFailed, validationErrors...), nil
+		}
+
+		return jsonResponse(http.StatusOK, headers, quoteFinancing(financingReq, price, financing)), nil
+
+	case "/cars/{id}/similar":
+		if request.HTTPMethod != "GET" {
+			return errorResponse(http.StatusMethodNotAllowed, headers, lang, msgMethodNotAllowed), nil
+		}
+
+		id, err := strconv.Atoi(request.PathParameters["id"])
+		if err != nil || id <= 0 {
+			return errorResponse(http.StatusBadRequest, headers, lang, msgInvalidCarID), nil
+		}
+
+		car, ok := findCar(id)
+		if !ok {
+			return errorResponse(http.StatusNotFound, headers, lang, msgCarNotFound), nil
+		}
+
+		return jsonResponse(http.StatusOK, headers, SimilarCarsResponse{
+			CarID: id,
+			Cars:  localizeSimilarCars(similarCars(car, parseSimilarLimit(request.QueryStringParameters["limit"])), lang),
+		}), nil
+
+	case "/saved-searches", "/saved-searches/confirm", "/saved-searches/unsubscribe":
+		return handleSavedSearches(ctx, request, lang, headers), nil
+
+	case "/admin/cars", "/admin/cars/{id}":
+		return requireRole(RoleAdmin, handleAdminCars)(ctx, request)
 
 	default:
-		return events.APIGatewayProxyResponse{
-			StatusCode: http.StatusNotFound,
-			Headers:    headers,
-			Body:       `{"error": "Not found"}`,
-		}, nil
+		return errorResponse(http.StatusNotFound, headers, lang, msgNotFound), nil
 	}
 }
 
 func main() {
-	if err := loadCarsFromCSV(); err != nil {
+	authenticator, err := authenticatorFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to configure authentication: %v", err)
+	}
+	auth = authenticator
+	financingConfig, err := financingConfigFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to configure financing: %v", err)
+	}
+	financing = financingConfig
+	httpCache = cacheConfigFromEnv()
+	carStore = carStoreFromEnv()
+	savedSearchConfig, err := savedSearchConfigFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to configure saved searches: %v", err)
+	}
+	savedSearches = savedSearchConfig
+	if err := loadCatalogue(context.Background()); err != nil {
 		log.Fatalf("Failed to load cars: %v", err)
 	}
 
-	lambda.Start(handleRequest)
+	lambda.Start(handleEvent)
 }
@@ -219,6 +219,15 @@ func TestValidateSearchRequest(t *testing.T) {
 			expectErrors:   true,
 			expectedFields: []string{"min_power", "max_power"},
 		},
+		{
+			name: "Year out of range",
+			request: SearchRequest{
+				MinYear: intPtr(MinYear - 1),
+				MaxYear: intPtr(maxRegistrationYear() + 1),
+			},
+			expectErrors:   true,
+			expectedFields: []string{"min_year", "max_year"},
+		},
 		{
 			name: "Invalid limit and offset - should be corrected",
 			request: SearchRequest{
@@ -300,7 +309,7 @@ func TestParseCarRecord(t *testing.T) {
 				MFK:          true,
 				Warranty:     true,
 				WarrantyText: "Ab 1. Inverkehrsetzung, 19.08.2021, 24 Monate oder 100&#39;000 km",
-				Equipment:    []string{"Ambientes Licht", "Mild-Hybrid", "Rückfahrkamera", "Sportsitze"},
+				Equipment:    []string{"Ambientebeleuchtung", "Mild-Hybrid", "Rückfahrkamera", "Sportsitze"},
 				Description:  "Top gepflegt, M Sport Paket",
 				ImageURLs:    []string{"https://img.example.com/bmw1.jpg", "https://img.example.com/bmw2.jpg"},
 			},
@@ -368,6 +377,46 @@ func TestParseCarRecord(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "Record with Swiss number format",
+			record: []string{
+				"4", "Audi A4 Avant", "CHF 38'900.-", "Ab 530.- mtl.",
+				"07.2020", "Kombi", "62 000", "Automatik", "Diesel", "Front", "204 PS", "150",
+				"True", "True", "warranty text",
+				"equipment", "description", "https://img.example.com/audi.jpg",
+			},
+			expected: Car{
+				ID:           4,
+				Title:        "Audi A4 Avant",
+				Brand:        "Audi",
+				PriceCHF:     38900,
+				LeasingText:  "Ab 530.- mtl.",
+				FirstReg:     "07.2020",
+				CarType:      "Kombi",
+				MileageKM:    62000,
+				Transmission: "Automatik",
+				Fuel:         "Diesel",
+				Drive:        "Front",
+				PowerHP:      204,
+				PowerKW:      150,
+				MFK:          true,
+				Warranty:     true,
+				WarrantyText: "warranty text",
+				Equipment:    []string{"equipment"},
+				Description:  "description",
+				ImageURLs:    []string{"https://img.example.com/audi.jpg"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Ambiguous price",
+			record: []string{
+				"5", "BMW 520d", "42.890", "Ab 580.-",
+				"08.2021", "Limousine", "55000", "Automatik", "Diesel", "Allrad", "190", "140",
+				"True", "True", "warranty", "equipment", "description", "images",
+			},
+			wantErr: true,
+		},
 		{
 			name: "Invalid ID",
 			record: []string{
@@ -390,7 +439,7 @@ func TestParseCarRecord(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result, err := parseCarRecord(tt.record)
+			result, err := parseCarRecord(defaultCSVHeader, tt.record)
 
 			if tt.wantErr && err == nil {
 				t.Errorf("Expected error but got none")
@@ -409,6 +458,90 @@ func TestParseCarRecord(t *testing.T) {
 	}
 }
 
+func TestParseCSVHeader(t *testing.T) {
+	tests := []struct {
+		name        string
+		header      []string
+		wantErr     string
+		wantColumns map[string]int
+	}{
+		{
+			name:        "Canonical order",
+			header:      strings.Split("id,title,price_chf,leasing_text,first_registration,car_type,mileage_km,transmission,fuel,drive,power_hp,power_kw,mfk,warranty,warranty_text,equipment,description,image_urls", ","),
+			wantColumns: map[string]int{"id": 0, "price_chf": 2, "image_urls": 17},
+		},
+		{
+			name:        "Reordered with extra column and BOM",
+			header:      []string{"\ufeffTitle", " ID ", "stock_location", "price_chf", "first_registration", "car_type", "mileage_km", "transmission", "fuel", "drive", "power_hp", "power_kw"},
+			wantColumns: map[string]int{"title": 0, "id": 1, "stock_location": 2, "price_chf": 3},
+		},
+		{
+			name:    "Missing required columns",
+			header:  []string{"id", "title", "car_type", "mileage_km", "transmission", "fuel", "drive", "power_hp"},
+			wantErr: "missing required CSV columns: price_chf, first_registration, power_kw",
+		},
+		{
+			name:    "Duplicate column",
+			header:  []string{"id", "title", "id"},
+			wantErr: `duplicate CSV column "id"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header, err := parseCSVHeader(tt.header)
+
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("Expected error %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			for column, index := range tt.wantColumns {
+				if header[column] != index {
+					t.Errorf("Expected column %s at index %d, got %d", column, index, header[column])
+				}
+			}
+		})
+	}
+}
+
+func TestParseCarsCSV(t *testing.T) {
+	data := "title,id,price_chf,stock_location,first_registration,car_type,mileage_km,transmission,fuel,drive,power_hp,power_kw,equipment\n" +
+		"BMW 520d,1,42890,Volketswil,08.2021,Limousine,55000,Automatik,Diesel,Allrad,190,140,Sportsitze;Rückfahrkamera\n" +
+		"Short row,2,38900,Volketswil,07.2020,Kombi,62000,Automatik,Diesel,Front,204,150\n" +
+		"Broken row,3,not-a-price,Volketswil,07.2020,Kombi,62000,Automatik,Diesel,Front,204,150,\n" +
+		"Skoda Octavia,4,29900,Volketswil,01.2022,Kombi,30000,Manuell,Benzin,Front,150,110,\n"
+
+	loaded, err := parseCarsCSV(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(loaded) != 2 {
+		t.Fatalf("Expected 2 cars, got %d", len(loaded))
+	}
+
+	if loaded[0].ID != 1 || loaded[0].Title != "BMW 520d" || loaded[0].PriceCHF != 42890 {
+		t.Errorf("Unexpected first car: %+v", loaded[0])
+	}
+	if !slicesEqual(loaded[0].Equipment, []string{"Sportsitze", "Rückfahrkamera"}) {
+		t.Errorf("Expected equipment to be parsed, got %v", loaded[0].Equipment)
+	}
+	if loaded[1].ID != 4 || len(loaded[1].Equipment) != 0 || loaded[1].Description != "" {
+		t.Errorf("Unexpected second car: %+v", loaded[1])
+	}
+
+	if _, err := parseCarsCSV(strings.NewReader("id,title\n1,BMW\n")); err == nil {
+		t.Error("Expected error for missing required columns")
+	}
+}
+
 func TestMatchesCriteria(t *testing.T) {
 	testCar := Car{
 		ID:           1,
@@ -421,6 +554,10 @@ func TestMatchesCriteria(t *testing.T) {
 		MileageKM:    55000,
 		PowerHP:      190,
 		Description:  "Top gepflegt, M Sport Paket",
+		FirstReg:     "08.2021",
+		FirstRegYear: 2021,
+		MFK:          true,
+		Warranty:     false,
 	}
 
 	tests := []struct {
@@ -518,6 +655,55 @@ func TestMatchesCriteria(t *testing.T) {
 			},
 			expected: true,
 		},
+		{
+			name: "Year range - within range",
+			car:  testCar,
+			req: SearchRequest{
+				MinYear: intPtr(2020),
+				MaxYear: intPtr(2021),
+			},
+			expected: true,
+		},
+		{
+			name: "Year range - too old",
+			car:  testCar,
+			req: SearchRequest{
+				MinYear: intPtr(2022),
+			},
+			expected: false,
+		},
+		{
+			name: "Year range - unknown year",
+			car:  Car{FirstReg: "unbekannt"},
+			req: SearchRequest{
+				MaxYear: intPtr(2030),
+			},
+			expected: false,
+		},
+		{
+			name: "MFK filter matches",
+			car:  testCar,
+			req: SearchRequest{
+				MFK: boolPtr(true),
+			},
+			expected: true,
+		},
+		{
+			name: "Warranty filter doesn't match",
+			car:  testCar,
+			req: SearchRequest{
+				Warranty: boolPtr(true),
+			},
+			expected: false,
+		},
+		{
+			name: "Without warranty filter matches",
+			car:  testCar,
+			req: SearchRequest{
+				Warranty: boolPtr(false),
+			},
+			expected: true,
+		},
 		{
 			name: "Multiple filters - all match",
 			car:  testCar,
@@ -555,10 +741,7 @@ func TestMatchesCriteria(t *testing.T) {
 
 func TestSearchCars(t *testing.T) {
 	// Setup test data
-	originalCars := cars
-	defer func() { cars = originalCars }()
-
-	cars = []Car{
+	setTestCars(t, []Car{
 		{
 			ID:           1,
 			Title:        "BMW 520d",
@@ -595,7 +778,7 @@ func TestSearchCars(t *testing.T) {
 			PowerHP:      204,
 			Description:  "Mercedes description",
 		},
-	}
+	})
 
 	tests := []struct {
 		name          string
@@ -698,14 +881,14 @@ func TestSearchCars(t *testing.T) {
 
 func TestGetSearchOptions(t *testing.T) {
 	// Setup test data
-	originalCars := cars
-	defer func() { cars = originalCars }()
-
-	cars = []Car{
-		{CarType: "Limousine", Transmission: "Automatik", Fuel: "Diesel", Drive: "Allrad", PriceCHF: 42890, MileageKM: 55000, PowerHP: 190},
-		{CarType: "Kombi", Transmission: "Automatik", Fuel: "Diesel", Drive: "Front", PriceCHF: 38900, MileageKM: 62000, PowerHP: 204},
-		{CarType: "SUV", Transmission: "Manuell", Fuel: "Benzin", Drive: "Hinterrad", PriceCHF: 39900, MileageKM: 48000, PowerHP: 204},
-	}
+	setTestCars(t, []Car{
+		{CarType: "Limousine", Transmission: "Automatik", Fuel: "Diesel", Drive: "Allrad", PriceCHF: 42890, MileageKM: 55000, PowerHP: 190, Status: StatusAvailable},
+		{CarType: "Kombi", Transmission: "Automatik", Fuel: "Diesel", Drive: "Front", PriceCHF: 38900, MileageKM: 62000, PowerHP: 204, Status: StatusAvailable},
+		{CarType: "SUV", Transmission: "Manuell", Fuel: "Benzin", Drive: "Hinterrad", PriceCHF: 39900, MileageKM: 48000, PowerHP: 204, Status: StatusAvailable},
+		// Reserved and sold cars are not counted
+		{CarType: "Cabriolet", Transmission: "Manuell", Fuel: "Elektro", Drive: "Front", PriceCHF: 12000, MileageKM: 90000, PowerHP: 110, Status: StatusReserved},
+		{CarType: "Van", Transmission: "Automatik", Fuel: "Hybrid", Drive: "Front", PriceCHF: 99000, MileageKM: 1000, PowerHP: 300, Status: StatusSold},
+	})
 
 	options := getSearchOptions()
 
@@ -733,6 +916,32 @@ func TestGetSearchOptions(t *testing.T) {
 	}
 }
 
+func TestGetSearchOptionsYearsAndFlags(t *testing.T) {
+	setTestCars(t, []Car{
+		{FirstRegYear: 2019, MFK: true, Status: StatusAvailable},
+		{FirstRegYear: 2022, Status: StatusAvailable},
+		{Status: StatusAvailable}, // Unknown year
+		{FirstRegYear: 2012, Warranty: true, Status: StatusSold},
+	})
+
+	options := getSearchOptions()
+	if options.MinYear != 2019 || options.MaxYear != 2022 {
+		t.Errorf("Expected years 2019-2022, got %d-%d", options.MinYear, options.MaxYear)
+	}
+	if !options.MFK || options.Warranty {
+		t.Errorf("Expected mfk true and warranty false, got %v and %v", options.MFK, options.Warranty)
+	}
+}
+
+func TestParseFirstRegistrationYear(t *testing.T) {
+	tests := map[string]int{"08.2021": 2021, "8.2021": 2021, " 01.1999 ": 1999, "2021": 0, "13.2021": 0, "0.2021": 0, "": 0}
+	for value, expected := range tests {
+		if result := parseFirstRegistrationYear(value); result != expected {
+			t.Errorf("parseFirstRegistrationYear(%q) = %d; want %d", value, result, expected)
+		}
+	}
+}
+
 func TestHandleRequest(t *testing.T) {
 	// Initialize cars data
 	if err := loadCarsFromCSV(); err != nil {
@@ -783,7 +992,7 @@ func TestHandleRequest(t *testing.T) {
 				Body:       `{invalid json}`,
 			},
 			expectedStatus: 400,
-			expectedBody:   `{"error": "Invalid JSON body"}`,
+			expectedBody:   `{"error":"Invalid JSON body","code":"invalid_json"}`,
 		},
 		{
 			name: "Method not allowed for search options",
@@ -792,16 +1001,16 @@ func TestHandleRequest(t *testing.T) {
 				Resource:   "/search/options",
 			},
 			expectedStatus: 405,
-			expectedBody:   `{"error": "Method not allowed"}`,
+			expectedBody:   `{"error":"Method not allowed","code":"method_not_allowed"}`,
 		},
 		{
 			name: "Method not allowed for search",
 			request: events.APIGatewayProxyRequest{
-				HTTPMethod: "GET",
+				HTTPMethod: "DELETE",
 				Resource:   "/search",
 			},
 			expectedStatus: 405,
-			expectedBody:   `{"error": "Method not allowed"}`,
+			expectedBody:   `{"error":"Method not allowed","code":"method_not_allowed"}`,
 		},
 		{
 			name: "Not found",
@@ -810,7 +1019,7 @@ func TestHandleRequest(t *testing.T) {
 				Resource:   "/unknown",
 			},
 			expectedStatus: 404,
-			expectedBody:   `{"error": "Not found"}`,
+			expectedBody:   `{"error":"Not found","code":"not_found"}`,
 		},
 	}
 
@@ -1036,10 +1245,24 @@ func TestHandleRequestSecurity(t *testing.T) {
 }
 
 // Helper functions
+func boolPtr(b bool) *bool {
+	return &b
+}
+
 func intPtr(i int) *int {
 	return &i
 }
 
+// setTestCars replaces the served catalogue for the duration of a test
+func setTestCars(t *testing.T, testCars []Car) {
+	t.Helper()
+
+	original := catalogue
+	catalogue = newCatalogueHolder(embeddedCatalogueSource{}, 0)
+	catalogue.Store(testCars, t.Name())
+	t.Cleanup(func() { catalogue = original })
+}
+
 func carEquals(a, b Car) bool {
 	if a.ID != b.ID || a.Title != b.Title || a.Brand != b.Brand || a.PriceCHF != b.PriceCHF {
 		return false
@@ -1089,6 +1312,7 @@ func TestImageURLsFromRealCSV(t *testing.T) {
 	}
 
 	// Check that we have cars loaded
+	cars := currentCars()
 	if len(cars) == 0 {
 		t.Fatal("No cars loaded from CSV")
 	}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Default location of listing images that are exported as plain file keys
+const defaultMarketplaceImageBaseURL = "https://astro-backend-data-bucket.s3.eu-central-1.amazonaws.com/images"
+
+// HP per kW, used when a listing only carries one of the two power values
+const hpPerKW = 1.35962
+
+// marketplaceExport is a marketplace listing export in XML or JSON form
+type marketplaceExport struct {
+	XMLName  xml.Name             `xml:"listings" json:"-"`
+	Listings []marketplaceListing `xml:"listing" json:"listings"`
+}
+
+// marketplaceListing is a single vehicle as exported by a marketplace
+type marketplaceListing struct {
+	ID                string   `xml:"id" json:"id"`
+	Make              string   `xml:"make" json:"make"`
+	Model             string   `xml:"model" json:"model"`
+	Version           string   `xml:"version" json:"version"`
+	Price             int      `xml:"price" json:"price"`
+	Currency          string   `xml:"currency" json:"currency"`
+	LeasingRate       int      `xml:"leasingRate" json:"leasing_rate"`
+	FirstRegistration string   `xml:"firstRegistration" json:"first_registration"`
+	Mileage           int      `xml:"mileage" json:"mileage"`
+	BodyType          string   `xml:"bodyType" json:"body_type"`
+	FuelType          string   `xml:"fuelType" json:"fuel_type"`
+	Transmission      string   `xml:"transmission" json:"transmission"`
+	DriveType         string   `xml:"driveType" json:"drive_type"`
+	PowerKW           int      `xml:"powerKw" json:"power_kw"`
+	PowerHP           int      `xml:"powerHp" json:"power_hp"`
+	Inspected         bool     `xml:"inspected" json:"inspected"`
+	WarrantyMonths    int      `xml:"warrantyMonths" json:"warranty_months"`
+	Equipment         []string `xml:"equipment>item" json:"equipment"`
+	Description       string   `xml:"description" json:"description"`
+	Images            []string `xml:"images>image" json:"images"`
+}
+
+// parseMarketplaceExport converts a marketplace listing export (XML or JSON)
+// into cars. Listings are converted into records and parsed by parseCarRecord,
+// so they are validated and sanitized like rows from autos.csv. Listings that
+// cannot be converted are logged and skipped.
+func parseMarketplaceExport(data []byte) ([]Car, error) {
+	var export marketplaceExport
+
+	trimmed := bytes.TrimSpace(data)
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("<")):
+		if err := xml.Unmarshal(trimmed, &export); err != nil {
+			return nil, fmt.Errorf("error reading marketplace XML: %w", err)
+		}
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		if err := json.Unmarshal(trimmed, &export); err != nil {
+			return nil, fmt.Errorf("error reading marketplace JSON: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unknown marketplace export format")
+	}
+
+	imageBaseURL := os.Getenv("MARKETPLACE_IMAGE_BASE_URL")
+	if imageBaseURL == "" {
+		imageBaseURL = defaultMarketplaceImageBaseURL
+	}
+
+	loaded := make([]Car, 0, len(export.Listings))
+	for i, listing := range export.Listings {
+		record, err := marketplaceListingToRecord(listing, imageBaseURL)
+		if err != nil {
+			log.Printf("Skipping listing %d (%s): %v", i, listing.ID, err)
+			continue
+		}
+
+		car, err := parseCarRecord(defaultCSVHeader, record)
+		if err != nil {
+			log.Printf("Error parsing listing %d (%s): %v", i, listing.ID, err)
+			continue
+		}
+		loaded = append(loaded, car)
+	}
+
+	return loaded, nil
+}
+
+// marketplaceListingToRecord maps a listing onto a record in the canonical CSV column order
+func marketplaceListingToRecord(listing marketplaceListing, imageBaseURL string) ([]string, error) {
+	if currency := strings.ToUpper(strings.TrimSpace(listing.Currency)); currency != "" && currency != "CHF" {
+		return nil, fmt.Errorf("unsupported currency %q", listing.Currency)
+	}
+
+	firstReg, err := marketplaceFirstRegistration(listing.FirstRegistration)
+	if err != nil {
+		return nil, err
+	}
+
+	powerKW, powerHP := listing.PowerKW, listing.PowerHP
+	if powerHP == 0 {
+		powerHP = int(math.Round(float64(powerKW) * hpPerKW))
+	}
+	if powerKW == 0 {
+		powerKW = int(math.Round(float64(powerHP) / hpPerKW))
+	}
+
+	title := strings.Join(strings.Fields(strings.Join([]string{listing.Make, listing.Model, listing.Version}, " ")), " ")
+
+	leasingText := ""
+	if listing.LeasingRate > 0 {
+		leasingText = fmt.Sprintf("Ab %d.- mtl.", listing.LeasingRate)
+	}
+
+	warrantyText := ""
+	if listing.WarrantyMonths > 0 {
+		warrantyText = fmt.Sprintf("%d Monate Garantie", listing.WarrantyMonths)
+	}
+
+	// Marketplace equipment codes are part of the equipment vocabulary and
+	// get their labels in parseCarRecord
+	equipment := make([]string, 0, len(listing.Equipment))
+	for _, item := range listing.Equipment {
+		equipment = append(equipment, strings.TrimSpace(item))
+	}
+
+	imageURLs := make([]string, 0, len(listing.Images))
+	for _, image := range listing.Images {
+		image = strings.TrimSpace(image)
+		if image == "" {
+			continue
+		}
+		// Other schemes such as javascript: are dropped, plain keys are resolved against the base URL
+		if strings.Contains(image, ":") && !strings.HasPrefix(image, "http://") && !strings.HasPrefix(image, "https://") {
+			continue
+		}
+		if !strings.HasPrefix(image, "http://") && !strings.HasPrefix(image, "https://") {
+			image = strings.TrimSuffix(imageBaseURL, "/") + "/" + strings.TrimPrefix(image, "/")
+		}
+		imageURLs = append(imageURLs, image)
+	}
+
+	// Body type, transmission, fuel and drive codes are synonyms in the enum
+	// vocabularies and get their labels in parseCarRecord as well
+	record := carToRecord(Car{
+		PriceCHF:     listing.Price,
+		Title:        title,
+		LeasingText:  leasingText,
+		FirstReg:     firstReg,
+		CarType:      listing.BodyType,
+		MileageKM:    listing.Mileage,
+		Transmission: listing.Transmission,
+		Fuel:         listing.FuelType,
+		Drive:        listing.DriveType,
+		PowerHP:      powerHP,
+		PowerKW:      powerKW,
+		MFK:          listing.Inspected,
+		Warranty:     listing.WarrantyMonths > 0,
+		WarrantyText: warrantyText,
+		Equipment:    equipment,
+		Description:  listing.Description,
+		ImageURLs:    imageURLs,
+	})
+	record[defaultCSVHeader["id"]] = strings.TrimSpace(listing.ID)
+	return record, nil
+}
+
+// marketplaceFirstRegistration converts "2021-08" or "2021-08-19" into "08.2021"
+func marketplaceFirstRegistration(value string) (string, error) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 2 || len(parts) > 3 || len(parts[0]) != 4 || len(parts[1]) != 2 {
+		return "", fmt.Errorf("invalid first registration %q", value)
+	}
+	year, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return "", fmt.Errorf("invalid first registration %q", value)
+	}
+	month, err := strconv.Atoi(parts[1])
+	if err != nil || month < 1 || month > 12 {
+		return "", fmt.Errorf("invalid first registration %q", value)
+	}
+	return fmt.Sprintf("%02d.%04d", month, year), nil
+}
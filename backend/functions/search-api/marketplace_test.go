@@ -0,0 +1,207 @@
+package main
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestParseMarketplaceExportXML(t *testing.T) {
+	t.Setenv("MARKETPLACE_IMAGE_BASE_URL", "https://img.example.com/images/")
+
+	data, err := os.ReadFile("testdata/marketplace_export.xml")
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+
+	loaded, err := parseMarketplaceExport(data)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// The EUR listing and the listing with an unparseable registration date are skipped
+	if len(loaded) != 2 {
+		t.Fatalf("Expected 2 cars, got %d", len(loaded))
+	}
+
+	expected := []Car{
+		{
+			ID:           1042,
+			Title:        "BMW 520d xDrive 48V M Sport Steptronic",
+			Brand:        "BMW",
+			Model:        "520d",
+			PriceCHF:     42890,
+			LeasingText:  "Ab 580.- mtl.",
+			FirstReg:     "08.2021",
+			FirstRegYear: 2021,
+			CarType:      "Limousine",
+			MileageKM:    55000,
+			Transmission: "Automatik",
+			Fuel:         "Diesel",
+			Drive:        "Allrad",
+			PowerHP:      190,
+			PowerKW:      140,
+			MFK:          true,
+			Warranty:     true,
+			WarrantyText: "24 Monate Garantie",
+			Equipment:    []string{"Ambientebeleuchtung", "Rückfahrkamera", "Sportsitze", "M Sport Paket"},
+			Description:  "Top gepflegt &lt;b&gt;M Sport&lt;/b&gt;",
+			ImageURLs: []string{
+				"https://img.example.com/images/bmw1.jpg",
+				"https://cdn.marketplace.example/listings/1042/2.jpg",
+			},
+			Status:           StatusAvailable,
+			LeasingRateCHF:   580,
+			WarrantyMonths:   24,
+			EquipmentCodes:   []string{"ambient-lighting", "rear-camera", "sport-seats"},
+			CarTypeCode:      "sedan",
+			TransmissionCode: "automatic",
+			FuelCode:         "diesel",
+			DriveCode:        "all-wheel",
+		},
+		{
+			ID:           1043,
+			Title:        "Volkswagen Golf 8 GTI",
+			Brand:        "Volkswagen",
+			Model:        "Golf",
+			PriceCHF:     35900,
+			FirstReg:     "06.2022",
+			FirstRegYear: 2022,
+			CarType:      "Kleinwagen",
+			MileageKM:    23000,
+			Transmission: "Automatik",
+			Fuel:         "Benzin",
+			Drive:        "Front",
+			PowerHP:      245,
+			PowerKW:      180,
+			Equipment:    []string{"Digital Cockpit"},
+			Description:  "Neuwertig, unfallfrei",
+			ImageURLs:    []string{},
+			Status:       StatusAvailable,
+
+			EquipmentCodes:   []string{"digital-cockpit"},
+			CarTypeCode:      "small-car",
+			TransmissionCode: "automatic",
+			FuelCode:         "petrol",
+			DriveCode:        "front",
+		},
+	}
+
+	for i := range expected {
+		if !reflect.DeepEqual(loaded[i], expected[i]) {
+			t.Errorf("Car %d:\nexpected %+v\ngot      %+v", i, expected[i], loaded[i])
+		}
+	}
+}
+
+func TestParseMarketplaceExportJSON(t *testing.T) {
+	t.Setenv("MARKETPLACE_IMAGE_BASE_URL", "https://img.example.com/images")
+
+	data, err := os.ReadFile("testdata/marketplace_export.json")
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+
+	// JSON objects are routed to the marketplace importer by parseCatalogue
+	loaded, err := parseCatalogue("marketplace_export.json", "", data)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// The listing with a non-numeric ID is skipped
+	if len(loaded) != 1 {
+		t.Fatalf("Expected 1 car, got %d", len(loaded))
+	}
+
+	car := loaded[0]
+	if car.ID != 2001 || car.Title != "Tesla Model 3 Long Range AWD" || car.Brand != "Tesla" {
+		t.Errorf("Unexpected identity: %+v", car)
+	}
+	if car.CarType != "Limousine" || car.Fuel != "Elektro" || car.Transmission != "Automatik" || car.Drive != "Allrad" {
+		t.Errorf("Unexpected label mapping: %s, %s, %s, %s", car.CarType, car.Fuel, car.Transmission, car.Drive)
+	}
+	if car.PowerHP != 498 || car.PowerKW != 366 {
+		t.Errorf("Expected exported power values to be kept, got %d HP / %d kW", car.PowerHP, car.PowerKW)
+	}
+	if car.LeasingText != "Ab 499.- mtl." || car.WarrantyText != "12 Monate Garantie" || !car.Warranty || !car.MFK {
+		t.Errorf("Unexpected leasing or warranty data: %+v", car)
+	}
+	if !reflect.DeepEqual(car.Equipment, []string{"Sitzheizung", "Panoramadach", "Autopilot"}) {
+		t.Errorf("Unexpected equipment: %v", car.Equipment)
+	}
+	expectedImages := []string{"https://img.example.com/images/tesla/1.jpg", "https://img.example.com/images/tesla/2.jpg"}
+	if !reflect.DeepEqual(car.ImageURLs, expectedImages) {
+		t.Errorf("Expected images %v, got %v", expectedImages, car.ImageURLs)
+	}
+}
+
+func TestParseMarketplaceExportInvalid(t *testing.T) {
+	for _, input := range []string{"", "id,title", "<listings><listing>", `{"listings": 3}`} {
+		if _, err := parseMarketplaceExport([]byte(input)); err == nil {
+			t.Errorf("Expected error for %q", input)
+		}
+	}
+}
+
+func TestMarketplaceFirstRegistration(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		wantErr  bool
+	}{
+		{input: "2021-08", expected: "08.2021"},
+		{input: "2021-08-19", expected: "08.2021"},
+		{input: " 2019-12 ", expected: "12.2019"},
+		{input: "2021-13", wantErr: true},
+		{input: "08.2021", wantErr: true},
+		{input: "21-08", wantErr: true},
+		{input: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := marketplaceFirstRegistration(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error, got %q", result)
+				}
+				return
+			}
+			if err != nil || result != tt.expected {
+				t.Errorf("Expected %q, got %q (%v)", tt.expected, result, err)
+			}
+		})
+	}
+}
+
+func TestMarketplaceCodesAreInVocabularies(t *testing.T) {
+	tests := []struct {
+		vocabulary *enumVocabulary
+		codes      []string
+		label      string
+	}{
+		{carTypeVocabulary, []string{"saloon", "sedan"}, "Limousine"},
+		{carTypeVocabulary, []string{"estate", "station-wagon"}, "Kombi"},
+		{carTypeVocabulary, []string{"suv", "offroad"}, "SUV"},
+		{carTypeVocabulary, []string{"convertible", "cabriolet"}, "Cabriolet"},
+		{carTypeVocabulary, []string{"compact", "small-car"}, "Kleinwagen"},
+		{carTypeVocabulary, []string{"van", "minivan"}, "Van"},
+		{fuelVocabulary, []string{"petrol", "gasoline"}, "Benzin"},
+		{fuelVocabulary, []string{"hybrid", "hybrid-petrol", "hybrid-diesel", "plugin-hybrid", "mild-hybrid"}, "Hybrid"},
+		{fuelVocabulary, []string{"cng"}, "Erdgas"},
+		{fuelVocabulary, []string{"lpg"}, "Autogas"},
+		{transmissionVocabulary, []string{"automatic", "semi-automatic"}, "Automatik"},
+		{transmissionVocabulary, []string{"manual"}, "Manuell"},
+		{driveVocabulary, []string{"all-wheel", "awd", "4x4"}, "Allrad"},
+		{driveVocabulary, []string{"front"}, "Front"},
+		{driveVocabulary, []string{"rear"}, "Hinterrad"},
+	}
+
+	for _, tt := range tests {
+		for _, code := range tt.codes {
+			if label, _ := tt.vocabulary.Canonical(code); label != tt.label {
+				t.Errorf("%s: expected %s, got %s", code, tt.label, label)
+			}
+		}
+	}
+}
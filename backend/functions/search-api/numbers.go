@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Currency and unit decorations that may surround a number in the sheet
+var numberDecorations = []string{"sfr.", "sfr", "chf", "fr.", "km", "ps", "hp", "kw"}
+
+// Trailing markers used in Swiss prices for "no cents", e.g. "38'900.-"
+var wholeAmountSuffixes = []string{".--", ".-", ".–", ",-", ",–", ".00", ",00"}
+
+// isThousandsSeparator reports whether r is used to group thousands in Swiss notation
+func isThousandsSeparator(r rune) bool {
+	switch r {
+	case '\'', '\u2019', ' ', '\u00a0', '\u202f', '\u2009':
+		return true
+	}
+	return false
+}
+
+// stripNumberDecorations removes currency and unit markers from both ends of s
+func stripNumberDecorations(s string) string {
+	for {
+		trimmed := strings.TrimSpace(s)
+		lower := strings.ToLower(trimmed)
+		for _, decoration := range numberDecorations {
+			if strings.HasPrefix(lower, decoration) {
+				trimmed = trimmed[len(decoration):]
+				break
+			}
+			if strings.HasSuffix(lower, decoration) {
+				trimmed = trimmed[:len(trimmed)-len(decoration)]
+				break
+			}
+		}
+		trimmed = strings.TrimSpace(trimmed)
+		if trimmed == s {
+			return s
+		}
+		s = trimmed
+	}
+}
+
+// parseSwissInt parses a non-negative whole number written the way it is
+// usually typed in Swiss sheets: "42'890", "55 000", "CHF 38'900.-" or
+// "190 PS". Values that could be read in more than one way, such as
+// "42.890" or "42,5", are rejected instead of guessed.
+func parseSwissInt(s string) (int, error) {
+	value := stripNumberDecorations(s)
+	for _, suffix := range wholeAmountSuffixes {
+		if strings.HasSuffix(value, suffix) {
+			value = strings.TrimSpace(strings.TrimSuffix(value, suffix))
+			break
+		}
+	}
+
+	if value == "" {
+		return 0, fmt.Errorf("empty number %q", s)
+	}
+
+	var groups []string
+	var separator rune
+	current := strings.Builder{}
+	for _, r := range value {
+		switch {
+		case r >= '0' && r <= '9':
+			current.WriteRune(r)
+		case isThousandsSeparator(r):
+			if separator != 0 && separator != r {
+				return 0, fmt.Errorf("mixed thousands separators in %q", s)
+			}
+			separator = r
+			groups = append(groups, current.String())
+			current.Reset()
+		default:
+			return 0, fmt.Errorf("ambiguous or invalid number %q", s)
+		}
+	}
+	groups = append(groups, current.String())
+
+	// With thousands separators every group after the first must have exactly three digits
+	if len(groups) > 1 {
+		if len(groups[0]) == 0 || len(groups[0]) > 3 {
+			return 0, fmt.Errorf("invalid digit grouping in %q", s)
+		}
+		for _, group := range groups[1:] {
+			if len(group) != 3 {
+				return 0, fmt.Errorf("invalid digit grouping in %q", s)
+			}
+		}
+	}
+
+	n, err := strconv.Atoi(strings.Join(groups, ""))
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q: %w", s, err)
+	}
+	return n, nil
+}
+
+// formatSwissInt writes a whole number with apostrophes between the
+// thousands, e.g. "38'900"
+func formatSwissInt(n int) string {
+	digits := strconv.Itoa(n)
+	sign := ""
+	if n < 0 {
+		sign, digits = "-", digits[1:]
+	}
+
+	var b strings.Builder
+	for i, r := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteRune('\'')
+		}
+		b.WriteRune(r)
+	}
+	return sign + b.String()
+}
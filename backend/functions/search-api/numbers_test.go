@@ -0,0 +1,74 @@
+package main
+
+import "testing"
+
+func TestParseSwissInt(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected int
+		wantErr  bool
+	}{
+		{name: "Plain number", input: "42890", expected: 42890},
+		{name: "Apostrophe separator", input: "42'890", expected: 42890},
+		{name: "Typographic apostrophe", input: "1\u2019042\u2019890", expected: 1042890},
+		{name: "Space separator", input: "55 000", expected: 55000},
+		{name: "Non-breaking space separator", input: "55\u00a0000", expected: 55000},
+		{name: "Currency and no-cents marker", input: "CHF 38'900.-", expected: 38900},
+		{name: "Currency suffix", input: "38'900.– CHF", expected: 38900},
+		{name: "Fr. prefix", input: "Fr. 38'900.--", expected: 38900},
+		{name: "Zero cents", input: "38900.00", expected: 38900},
+		{name: "Mileage unit", input: "55'000 km", expected: 55000},
+		{name: "Power unit", input: "190 PS", expected: 190},
+		{name: "Power unit kW", input: "140kW", expected: 140},
+		{name: "Surrounding whitespace", input: "  42890 ", expected: 42890},
+		{name: "Dot as thousands separator is ambiguous", input: "42.890", wantErr: true},
+		{name: "Comma is ambiguous", input: "42,890", wantErr: true},
+		{name: "Cents are rejected", input: "38'900.50", wantErr: true},
+		{name: "Wrong grouping", input: "4'28'90", wantErr: true},
+		{name: "Leading group too long", input: "4289'000", wantErr: true},
+		{name: "Mixed separators", input: "1'042 890", wantErr: true},
+		{name: "Negative", input: "-500", wantErr: true},
+		{name: "Empty", input: "CHF", wantErr: true},
+		{name: "Text", input: "auf Anfrage", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := parseSwissInt(tt.input)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error for %q, got %d", tt.input, result)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error for %q: %v", tt.input, err)
+			}
+			if result != tt.expected {
+				t.Errorf("Expected %d, got %d", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestFormatSwissInt(t *testing.T) {
+	tests := []struct {
+		input    int
+		expected string
+	}{
+		{0, "0"},
+		{950, "950"},
+		{38900, "38'900"},
+		{1042890, "1'042'890"},
+		{-12500, "-12'500"},
+	}
+
+	for _, tt := range tests {
+		if result := formatSwissInt(tt.input); result != tt.expected {
+			t.Errorf("formatSwissInt(%d) = %q, expected %q", tt.input, result, tt.expected)
+		}
+	}
+}
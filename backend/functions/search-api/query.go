@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// searchQueryFields maps the JSON names of the SearchRequest fields to their
+// types, so GET /search accepts exactly the filters of the POST body
+var searchQueryFields = func() map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	requestType := reflect.TypeOf(SearchRequest{})
+	for i := 0; i < requestType.NumField(); i++ {
+		field := requestType.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		fields[name] = fieldType
+	}
+	return fields
+}()
+
+// searchRequestFromQuery builds a SearchRequest from the query string of
+// GET /search. Parameters are named like the JSON fields of the POST body;
+// lists such as equipment are given repeatedly or comma separated. Empty and
+// unknown parameters are ignored, like unknown fields in the POST body.
+func searchRequestFromQuery(single map[string]string, multi map[string][]string) (SearchRequest, []ValidationError) {
+	var req SearchRequest
+	var errors []ValidationError
+
+	values := make(map[string][]string, len(multi))
+	for name, value := range single {
+		values[name] = []string{value}
+	}
+	for name, value := range multi {
+		values[name] = value
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	body := make(map[string]interface{})
+	for _, name := range names {
+		fieldType, ok := searchQueryFields[name]
+		if !ok {
+			continue
+		}
+
+		var params []string
+		for _, value := range values[name] {
+			if value = strings.TrimSpace(value); value != "" {
+				params = append(params, value)
+			}
+		}
+		if len(params) == 0 {
+			continue
+		}
+
+		if fieldType.Kind() == reflect.Slice {
+			var items []string
+			for _, param := range params {
+				for _, item := range strings.Split(param, ",") {
+					if item = strings.TrimSpace(item); item != "" {
+						items = append(items, item)
+					}
+				}
+			}
+			body[name] = items
+			continue
+		}
+
+		if len(params) > 1 {
+			errors = append(errors, newValidationError(name, msgSingleValue))
+			continue
+		}
+
+		switch fieldType.Kind() {
+		case reflect.Int:
+			number, err := strconv.Atoi(params[0])
+			if err != nil {
+				errors = append(errors, newValidationError(name, msgNotInteger))
+				continue
+			}
+			body[name] = number
+		case reflect.Bool:
+			flag, err := strconv.ParseBool(params[0])
+			if err != nil {
+				errors = append(errors, newValidationError(name, msgNotBoolean))
+				continue
+			}
+			body[name] = flag
+		default:
+			body[name] = params[0]
+		}
+	}
+
+	if len(errors) > 0 {
+		return req, errors
+	}
+
+	// Decode like the POST body so both forms yield the same request
+	encoded, _ := json.Marshal(body)
+	if err := json.Unmarshal(encoded, &req); err != nil {
+		return req, []ValidationError{newValidationError("query", msgInvalidQuery)}
+	}
+	return req, nil
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func TestSearchRequestFromQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		single   map[string]string
+		multi    map[string][]string
+		expected SearchRequest
+		field    string
+	}{
+		{
+			name:     "Strings, numbers and flags",
+			single:   map[string]string{"brand": "BMW", "min_price": "30000", "mfk": "true", "include_sold": "1", "limit": "5"},
+			expected: SearchRequest{Brand: "BMW", MinPrice: intPtr(30000), MFK: boolPtr(true), IncludeSold: true, Limit: 5},
+		},
+		{
+			name:     "Repeated list parameter",
+			single:   map[string]string{"equipment": "navigation"},
+			multi:    map[string][]string{"equipment": {"rear-camera", "navigation"}},
+			expected: SearchRequest{Equipment: []string{"rear-camera", "navigation"}},
+		},
+		{
+			name:     "Comma separated list",
+			single:   map[string]string{"equipment": "rear-camera, Sitzheizung,"},
+			expected: SearchRequest{Equipment: []string{"rear-camera", "Sitzheizung"}},
+		},
+		{
+			name:     "Empty and unknown parameters are ignored",
+			single:   map[string]string{"brand": "", "max_price": " ", "utm_source": "newsletter"},
+			expected: SearchRequest{},
+		},
+		{
+			name:   "Not a number",
+			single: map[string]string{"max_price": "viel"},
+			field:  "max_price",
+		},
+		{
+			name:   "Not a flag",
+			single: map[string]string{"warranty": "ja"},
+			field:  "warranty",
+		},
+		{
+			name:  "Repeated single value",
+			multi: map[string][]string{"brand": {"BMW", "Audi"}},
+			field: "brand",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, errors := searchRequestFromQuery(tt.single, tt.multi)
+			if tt.field != "" {
+				if len(errors) != 1 || errors[0].Field != tt.field {
+					t.Errorf("Expected error on %s, got %v", tt.field, errors)
+				}
+				return
+			}
+			if len(errors) > 0 {
+				t.Fatalf("Unexpected errors: %v", errors)
+			}
+			if !reflect.DeepEqual(req, tt.expected) {
+				t.Errorf("Expected %+v, got %+v", tt.expected, req)
+			}
+		})
+	}
+}
+
+func TestHandleSearchGetMatchesPost(t *testing.T) {
+	setTestCars(t, cursorTestCars())
+
+	post, _ := handleRequest(context.Background(), events.APIGatewayProxyRequest{
+		Resource:   "/search",
+		HTTPMethod: "POST",
+		Body:       `{"brand": "BMW", "min_price": 40000, "sort": "price_desc", "limit": 1}`,
+	})
+	get, _ := handleRequest(context.Background(), events.APIGatewayProxyRequest{
+		Resource:   "/search",
+		HTTPMethod: "GET",
+		QueryStringParameters: map[string]string{
+			"brand": "BMW", "min_price": "40000", "sort": "price_desc", "limit": "1",
+		},
+	})
+
+	if post.StatusCode != 200 || get.StatusCode != post.StatusCode || get.Body != post.Body {
+		t.Errorf("Expected identical responses:\nPOST %d %s\nGET  %d %s", post.StatusCode, post.Body, get.StatusCode, get.Body)
+	}
+
+	invalid, _ := handleRequest(context.Background(), events.APIGatewayProxyRequest{
+		Resource:              "/search",
+		HTTPMethod:            "GET",
+		QueryStringParameters: map[string]string{"min_price": "-1"},
+	})
+	if invalid.StatusCode != 400 {
+		t.Errorf("Expected 400 for invalid filter, got %d", invalid.StatusCode)
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"container/list"
+	"sync"
+)
+
+// Limits of the response cache of a catalogue snapshot. Large pages are not
+// cached, so the cache stays well below the Lambda memory.
+const (
+	MaxCachedResponses    = 128
+	MaxCachedResponseSize = 256 << 10
+)
+
+// responseCache is an LRU cache of serialized responses keyed by endpoint and
+// normalized request. Every catalogue snapshot has its own cache, so a reload
+// starts with an empty one.
+type responseCache struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List // most recently used first
+	entries  map[string]*list.Element
+}
+
+type cachedBody struct {
+	key  string
+	body string
+}
+
+// newResponseCache creates a cache holding up to capacity responses
+func newResponseCache(capacity int) *responseCache {
+	return &responseCache{
+		capacity: capacity,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+// Get returns a cached body and marks it as recently used
+func (c *responseCache) Get(key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return "", false
+	}
+	c.order.MoveToFront(element)
+	return element.Value.(cachedBody).body, true
+}
+
+// Add caches a body, evicting the least recently used one when full
+func (c *responseCache) Add(key, body string) {
+	if len(body) > MaxCachedResponseSize {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		element.Value = cachedBody{key: key, body: body}
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(cachedBody{key: key, body: body})
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(cachedBody).key)
+	}
+}
+
+// Len returns the number of cached responses
+func (c *responseCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func TestResponseCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	cache := newResponseCache(2)
+	cache.Add("a", "1")
+	cache.Add("b", "2")
+	cache.Get("a")
+	cache.Add("c", "3")
+
+	if _, ok := cache.Get("b"); ok {
+		t.Error("Expected b to be evicted")
+	}
+	for key, expected := range map[string]string{"a": "1", "c": "3"} {
+		if body, ok := cache.Get(key); !ok || body != expected {
+			t.Errorf("Expected %s=%s, got %q (%v)", key, expected, body, ok)
+		}
+	}
+
+	cache.Add("a", "4")
+	if body, _ := cache.Get("a"); body != "4" || cache.Len() != 2 {
+		t.Errorf("Expected updated entry, got %q with %d entries", body, cache.Len())
+	}
+
+	cache.Add("large", strings.Repeat("x", MaxCachedResponseSize+1))
+	if _, ok := cache.Get("large"); ok {
+		t.Error("Expected large body not to be cached")
+	}
+}
+
+func TestSearchOptionsAreSortedAndPrecomputed(t *testing.T) {
+	setTestCars(t, []Car{
+		{Brand: "Skoda", CarType: "SUV", Fuel: "Diesel", Status: StatusAvailable},
+		{Brand: "Audi", CarType: "Kombi", Fuel: "Benzin", Status: StatusAvailable},
+		{Brand: "BMW", CarType: "Limousine", Fuel: "Elektro", Status: StatusAvailable},
+	})
+
+	options := getSearchOptions()
+	if expected := []string{"Audi", "BMW", "Skoda"}; !reflect.DeepEqual(options.Brands, expected) {
+		t.Errorf("Expected brands %v, got %v", expected, options.Brands)
+	}
+	if expected := []string{"Kombi", "Limousine", "SUV"}; !reflect.DeepEqual(options.CarTypes, expected) {
+		t.Errorf("Expected car types %v, got %v", expected, options.CarTypes)
+	}
+
+	// The options belong to the snapshot and change with it
+	catalogue.Store([]Car{{Brand: "Fiat", Status: StatusAvailable}}, "reloaded")
+	if brands := getSearchOptions().Brands; !reflect.DeepEqual(brands, []string{"Fiat"}) {
+		t.Errorf("Expected options of the new catalogue, got %v", brands)
+	}
+}
+
+func TestSearchResponsesAreCachedPerSnapshot(t *testing.T) {
+	setTestCars(t, cursorTestCars())
+
+	search := func(body string) string {
+		response, _ := handleRequest(context.Background(), events.APIGatewayProxyRequest{
+			Resource:   "/search",
+			HTTPMethod: "POST",
+			Body:       body,
+		})
+		return response.Body
+	}
+
+	first := search(`{"brand": "BMW"}`)
+	responses := catalogue.Snapshot().responses
+	if responses.Len() != 1 {
+		t.Fatalf("Expected one cached response, got %d", responses.Len())
+	}
+
+	// Equal searches after normalization share the entry
+	if second := search(`{"brand": "BMW", "limit": 10}`); second != first || responses.Len() != 1 {
+		t.Errorf("Expected the cached response, got %d entries", responses.Len())
+	}
+
+	// A reload starts with an empty cache
+	catalogue.Store(cursorTestCars()[:1], "reloaded")
+	if reloaded := search(`{"brand": "BMW"}`); reloaded == first || !strings.Contains(reloaded, `"total":1`) {
+		t.Errorf("Expected results of the new catalogue, got %s", reloaded)
+	}
+	if catalogue.Snapshot().responses.Len() != 1 {
+		t.Errorf("Expected a new cache for the new catalogue")
+	}
+}
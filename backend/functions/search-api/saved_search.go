@@ -0,0 +1,847 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"html/template"
+	"log"
+	"mime"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// Saved search limits
+const (
+	MaxEmailLength           = 254
+	MaxSavedSearchesPerEmail = 10
+	MaxAlertCars             = 20 // Cars listed in one alert email
+
+	// Unconfirmed searches are deleted by the alert job after this time
+	SavedSearchConfirmationTTL = 7 * 24 * time.Hour
+)
+
+// savedSearchAlertsJob is the job name in the input of the scheduled alert rule
+const savedSearchAlertsJob = "saved-search-alerts"
+
+// savedSearchLanguage is the language of the emails when the request has none
+const savedSearchLanguage = "de"
+
+// defaultSiteURL is linked from the alert emails when SITE_URL is not set
+const defaultSiteURL = "https://autosalonvolketswil.ch"
+
+// savedSearchTokenRegex matches the IDs created by newSavedSearchID
+var savedSearchTokenRegex = regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+// SavedSearchConfig holds what saved searches need besides the catalogue
+type SavedSearchConfig struct {
+	Store  SavedSearchStore
+	Mailer Mailer
+
+	// APIURL is the public base URL of this API for the confirmation and
+	// unsubscribe links, SiteURL the catalogue linked from alerts
+	APIURL  string
+	SiteURL string
+}
+
+// savedSearches is nil unless saved searches are configured
+var savedSearches *SavedSearchConfig
+
+// savedSearchConfigFromEnv configures saved searches from SAVED_SEARCHES_TABLE,
+// SENDER_EMAIL, PUBLIC_API_URL and SITE_URL. Without a table saved searches
+// are disabled and nil is returned.
+func savedSearchConfigFromEnv() (*SavedSearchConfig, error) {
+	table := os.Getenv("SAVED_SEARCHES_TABLE")
+	if table == "" {
+		return nil, nil
+	}
+
+	sender := os.Getenv("SENDER_EMAIL")
+	if sender == "" {
+		return nil, errors.New("SENDER_EMAIL is required for saved searches")
+	}
+	apiURL, err := baseURLFromEnv("PUBLIC_API_URL", "")
+	if err != nil {
+		return nil, err
+	}
+	siteURL, err := baseURLFromEnv("SITE_URL", defaultSiteURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SavedSearchConfig{
+		Store:   newDynamoSavedSearchStore(table),
+		Mailer:  newSESMailer(sender),
+		APIURL:  apiURL,
+		SiteURL: siteURL,
+	}, nil
+}
+
+// baseURLFromEnv reads an absolute http(s) URL without trailing slash
+func baseURLFromEnv(name, fallback string) (string, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		value = fallback
+	}
+	parsed, err := url.Parse(value)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return "", fmt.Errorf("invalid %s %q", name, value)
+	}
+	return strings.TrimRight(value, "/"), nil
+}
+
+// SavedSearchRequest is the body of POST /saved-searches
+type SavedSearchRequest struct {
+	Email  string        `json:"email"`
+	Search SearchRequest `json:"search"`
+}
+
+// MessageResponse is the body of successful saved search requests
+type MessageResponse struct {
+	Message string `json:"message"`
+}
+
+// validateSavedSearchRequest checks the email and normalizes the search like
+// POST /search. Pagination, sorting and fields do not apply to alerts and are dropped.
+func validateSavedSearchRequest(req *SavedSearchRequest) []ValidationError {
+	var errors []ValidationError
+
+	req.Email = strings.TrimSpace(req.Email)
+	if !validEmail(req.Email) {
+		errors = append(errors, newValidationError("email", msgInvalidEmail))
+	}
+
+	for _, validation := range validateSearchRequest(&req.Search) {
+		validation.Field = "search." + validation.Field
+		errors = append(errors, validation)
+	}
+	req.Search.Fields, req.Search.Sort, req.Search.Cursor = nil, "", ""
+	req.Search.Limit, req.Search.Offset = 0, 0
+	req.Search.IncludeSold = false
+
+	return errors
+}
+
+// validEmail accepts a bare address such as "kunde@example.ch"
+func validEmail(email string) bool {
+	if email == "" || len(email) > MaxEmailLength {
+		return false
+	}
+	address, err := mail.ParseAddress(email)
+	return err == nil && address.Address == email && strings.Contains(email, ".")
+}
+
+// newSavedSearchID returns a random token that identifies a saved search
+func newSavedSearchID() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(id), nil
+}
+
+// handleSavedSearches serves POST /saved-searches and the confirmation and
+// unsubscribe links of the emails
+func handleSavedSearches(ctx context.Context, request events.APIGatewayProxyRequest, lang string, headers map[string]string) events.APIGatewayProxyResponse {
+	if savedSearches == nil {
+		return errorResponse(http.StatusServiceUnavailable, headers, lang, msgSavedSearchesNotConfigured)
+	}
+
+	if request.Resource != "/saved-searches" {
+		return handleSavedSearchLink(ctx, request, lang, headers)
+	}
+	if request.HTTPMethod != "POST" {
+		return errorResponse(http.StatusMethodNotAllowed, headers, lang, msgMethodNotAllowed)
+	}
+	return createSavedSearch(ctx, request, lang, headers)
+}
+
+// handleSavedSearchLink serves the confirmation and unsubscribe links. Mail
+// scanners open links before the customer does, so GET only shows a page
+// whose form posts back to the link; the POST changes the search. A POST
+// from that form gets a page, other clients get JSON.
+func handleSavedSearchLink(ctx context.Context, request events.APIGatewayProxyRequest, lang string, headers map[string]string) events.APIGatewayProxyResponse {
+	action := strings.TrimPrefix(request.Resource, "/saved-searches/")
+	token := request.QueryStringParameters["token"]
+
+	switch request.HTTPMethod {
+	case "GET":
+		return savedSearchLinkPage(action, token, lang, headers)
+	case "POST":
+	default:
+		return errorResponse(http.StatusMethodNotAllowed, headers, lang, msgMethodNotAllowed)
+	}
+
+	var err error
+	status, key := http.StatusOK, msgSavedSearchConfirmed
+	if action == "confirm" {
+		err = confirmSavedSearch(ctx, token)
+	} else {
+		key = msgSavedSearchDeleted
+		err = unsubscribeSavedSearch(ctx, token)
+	}
+	if err != nil {
+		status, key = savedSearchError(err)
+	}
+
+	if isFormRequest(request) {
+		pageLang := savedSearchPageLanguage(lang)
+		return savedSearchPage(status, headers, savedSearchPageData{
+			Lang:  pageLang,
+			Title: savedSearchPageTitle(action, pageLang),
+			Text:  messageText(pageLang, key),
+		})
+	}
+	if err != nil {
+		return errorResponse(status, headers, lang, key)
+	}
+	return jsonResponse(status, headers, MessageResponse{Message: messageText(lang, key)})
+}
+
+// isFormRequest reports whether the body of a request is an HTML form
+func isFormRequest(request events.APIGatewayProxyRequest) bool {
+	mediaType, _, err := mime.ParseMediaType(headerValue(request.Headers, "Content-Type"))
+	return err == nil && mediaType == "application/x-www-form-urlencoded"
+}
+
+// createSavedSearch stores an unconfirmed search and sends the confirmation email.
+// The token is only sent by email, so only the owner of the address can confirm.
+func createSavedSearch(ctx context.Context, request events.APIGatewayProxyRequest, lang string, headers map[string]string) events.APIGatewayProxyResponse {
+	var req SavedSearchRequest
+	if err := json.Unmarshal([]byte(request.Body), &req); err != nil {
+		return errorResponse(http.StatusBadRequest, headers, lang, msgInvalidJSON)
+	}
+	if validationErrors := validateSavedSearchRequest(&req); len(validationErrors) > 0 {
+		return errorResponse(http.StatusBadRequest, headers, lang, msgValidationFailed, validationErrors...)
+	}
+
+	// Concurrent requests can pass the check together, so an address may end
+	// up with a few more searches; the limit only has to stop unbounded growth
+	count, err := savedSearches.Store.CountByEmail(ctx, req.Email)
+	if err != nil {
+		return savedSearchErrorResponse(err, lang, headers)
+	}
+	if count >= MaxSavedSearchesPerEmail {
+		return errorResponse(http.StatusTooManyRequests, headers, lang, msgTooManySavedSearches)
+	}
+
+	id, err := newSavedSearchID()
+	if err != nil {
+		return savedSearchErrorResponse(err, lang, headers)
+	}
+	if lang == "" {
+		lang = savedSearchLanguage
+	}
+	search := SavedSearch{
+		ID:        id,
+		Email:     req.Email,
+		EmailKey:  savedSearchEmailKey(req.Email),
+		Search:    req.Search,
+		Lang:      lang,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := savedSearches.Store.Put(ctx, search); err != nil {
+		return savedSearchErrorResponse(err, lang, headers)
+	}
+
+	message, err := confirmationEmail(search, savedSearches)
+	if err == nil {
+		err = savedSearches.Mailer.Send(ctx, message)
+	}
+	if err != nil {
+		log.Printf("Error sending saved search confirmation: %v", err)
+		if err := savedSearches.Store.Delete(ctx, id); err != nil {
+			log.Printf("Error removing unconfirmed saved search: %v", err)
+		}
+		return errorResponse(http.StatusInternalServerError, headers, lang, msgEmailFailed)
+	}
+
+	return jsonResponse(http.StatusAccepted, headers, MessageResponse{Message: messageText(lang, msgConfirmationSent)})
+}
+
+// confirmSavedSearch activates a saved search. Cars matching at confirmation
+// count as seen, so the first alert only lists cars added afterwards.
+func confirmSavedSearch(ctx context.Context, token string) error {
+	if !savedSearchTokenRegex.MatchString(token) {
+		return ErrSavedSearchNotFound
+	}
+	search, err := savedSearches.Store.Get(ctx, token)
+	if err != nil {
+		return err
+	}
+	if search.Confirmed {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	search.Confirmed = true
+	search.ConfirmedAt = &now
+	search.SeenCarIDs = carIDs(matchingCars(search.Search, currentCars()))
+	return savedSearches.Store.Update(ctx, search)
+}
+
+// unsubscribeSavedSearch deletes a saved search, confirmed or not
+func unsubscribeSavedSearch(ctx context.Context, token string) error {
+	if !savedSearchTokenRegex.MatchString(token) {
+		return ErrSavedSearchNotFound
+	}
+	return savedSearches.Store.Delete(ctx, token)
+}
+
+// savedSearchError returns the status and message key of a store error
+func savedSearchError(err error) (int, string) {
+	if errors.Is(err, ErrSavedSearchNotFound) {
+		return http.StatusNotFound, msgSavedSearchNotFound
+	}
+	log.Printf("Error accessing saved searches: %v", err)
+	return http.StatusInternalServerError, msgInternalError
+}
+
+func savedSearchErrorResponse(err error, lang string, headers map[string]string) events.APIGatewayProxyResponse {
+	status, key := savedSearchError(err)
+	return errorResponse(status, headers, lang, key)
+}
+
+// matchingCars returns the cars of the catalogue that match a saved search
+func matchingCars(search SearchRequest, cars []Car) []Car {
+	matches := make([]Car, 0)
+	for _, car := range cars {
+		if matchesCriteria(car, search) {
+			matches = append(matches, car)
+		}
+	}
+	return matches
+}
+
+func carIDs(cars []Car) []int {
+	ids := make([]int, len(cars))
+	for i, car := range cars {
+		ids[i] = car.ID
+	}
+	return ids
+}
+
+// SavedSearchAlertsResult summarizes a run of the alert job
+type SavedSearchAlertsResult struct {
+	Checked int `json:"checked"`
+	Alerted int `json:"alerted"`
+	Expired int `json:"expired"`
+	Failed  int `json:"failed"`
+
+	// Alerts sent whose seen cars could not be stored; the next run sends them again
+	Unrecorded int `json:"unrecorded"`
+}
+
+// isSavedSearchAlertsEvent reports whether a raw Lambda event is the
+// constant input {"job": "saved-search-alerts"} of the scheduled rule
+func isSavedSearchAlertsEvent(raw json.RawMessage) bool {
+	var event struct {
+		Job string `json:"job"`
+	}
+	return json.Unmarshal(raw, &event) == nil && event.Job == savedSearchAlertsJob
+}
+
+// runSavedSearchAlerts checks every confirmed search against the current
+// catalogue and emails the cars that did not match at the previous run.
+// Unconfirmed searches older than SavedSearchConfirmationTTL are deleted.
+func runSavedSearchAlerts(ctx context.Context, now time.Time) (SavedSearchAlertsResult, error) {
+	var result SavedSearchAlertsResult
+	if savedSearches == nil {
+		return result, errors.New("saved searches not configured")
+	}
+
+	// Alerts should not wait for CATALOGUE_TTL; the loaded catalogue is used if this fails
+	if _, err := catalogue.Reload(ctx); err != nil {
+		log.Printf("Error reloading catalogue for saved search alerts: %v", err)
+	}
+	cars := currentCars()
+
+	list, err := savedSearches.Store.List(ctx)
+	if err != nil {
+		return result, err
+	}
+
+	for _, search := range list {
+		if !search.Confirmed {
+			if now.Sub(search.CreatedAt) > SavedSearchConfirmationTTL {
+				if err := savedSearches.Store.Delete(ctx, search.ID); err != nil && !errors.Is(err, ErrSavedSearchNotFound) {
+					log.Printf("Error deleting expired saved search: %v", err)
+					result.Failed++
+					continue
+				}
+				result.Expired++
+			}
+			continue
+		}
+
+		result.Checked++
+		alerted, err := alertSavedSearch(ctx, search, cars, now)
+		if alerted {
+			result.Alerted++
+		}
+		switch {
+		case err == nil:
+		case errors.Is(err, ErrSavedSearchNotFound):
+			// Unsubscribed while the job was running
+		case alerted:
+			log.Printf("Error recording sent saved search alert, the next run repeats it: %v", err)
+			result.Unrecorded++
+		default:
+			// The search keeps its seen cars, so the next run sends the alert again
+			log.Printf("Error running saved search alert: %v", err)
+			result.Failed++
+		}
+	}
+
+	return result, nil
+}
+
+// alertSavedSearch emails the new matches of one search and records the
+// current matches as seen. It reports whether an email was sent, also when
+// recording fails afterwards. Delivery is at-least-once: the email goes out
+// before the seen cars are stored, so a failed write repeats it next run.
+func alertSavedSearch(ctx context.Context, search SavedSearch, cars []Car, now time.Time) (bool, error) {
+	matches := matchingCars(search.Search, cars)
+
+	seen := make(map[int]bool, len(search.SeenCarIDs))
+	for _, id := range search.SeenCarIDs {
+		seen[id] = true
+	}
+	var newCars []Car
+	for _, car := range matches {
+		if !seen[car.ID] {
+			newCars = append(newCars, car)
+		}
+	}
+
+	if len(newCars) > 0 {
+		message, err := alertEmail(search, newCars, savedSearches)
+		if err != nil {
+			return false, err
+		}
+		if err := savedSearches.Mailer.Send(ctx, message); err != nil {
+			return false, err
+		}
+	}
+
+	search.SeenCarIDs = carIDs(matches)
+	search.LastRunAt = &now
+	if err := savedSearches.Store.Update(ctx, search); err != nil {
+		return len(newCars) > 0, err
+	}
+	return len(newCars) > 0, nil
+}
+
+// savedSearchTexts are the texts of the saved search emails and link pages
+// in one language
+type savedSearchTexts struct {
+	ConfirmSubject    string
+	ConfirmIntro      string
+	ConfirmButton     string
+	ConfirmIgnore     string // %d is the number of days until unconfirmed searches are deleted
+	ConfirmPrompt     string
+	AlertSubject      string
+	AlertIntro        string
+	AlertMore         string // %d is the number of cars not listed
+	AlertLink         string
+	Unsubscribe       string
+	UnsubscribePrompt string
+	UnsubscribeButton string
+	AllCars           string
+}
+
+// savedSearchEmailTexts translates the saved search emails and link pages
+var savedSearchEmailTexts = map[string]savedSearchTexts{
+	"de": {
+		ConfirmSubject:    "Bitte bestätigen Sie Ihren Suchauftrag",
+		ConfirmIntro:      "Sie möchten per E-Mail benachrichtigt werden, sobald neue Fahrzeuge zu Ihrer Suche passen:",
+		ConfirmButton:     "Suchauftrag bestätigen",
+		ConfirmIgnore:     "Falls Sie keinen Suchauftrag angelegt haben, können Sie diese E-Mail ignorieren. Ohne Bestätigung wird er nach %d Tagen gelöscht.",
+		ConfirmPrompt:     "Bestätigen Sie Ihren Suchauftrag, um per E-Mail über neue passende Fahrzeuge informiert zu werden.",
+		AlertSubject:      "Neue Fahrzeuge zu Ihrem Suchauftrag",
+		AlertIntro:        "Diese Fahrzeuge passen neu zu Ihrer Suche:",
+		AlertMore:         "und %d weitere",
+		AlertLink:         "Alle Fahrzeuge ansehen",
+		Unsubscribe:       "Suchauftrag abbestellen",
+		UnsubscribePrompt: "Möchten Sie diesen Suchauftrag abbestellen? Sie erhalten danach keine E-Mails mehr dazu.",
+		UnsubscribeButton: "Abbestellen",
+		AllCars:           "Alle Fahrzeuge",
+	},
+	"fr": {
+		ConfirmSubject:    "Veuillez confirmer votre recherche enregistrée",
+		ConfirmIntro:      "Vous souhaitez être averti par e-mail dès que de nouveaux véhicules correspondent à votre recherche :",
+		ConfirmButton:     "Confirmer la recherche",
+		ConfirmIgnore:     "Si vous n'avez pas créé cette recherche, vous pouvez ignorer cet e-mail. Sans confirmation, elle sera supprimée après %d jours.",
+		ConfirmPrompt:     "Confirmez votre recherche pour être averti par e-mail des nouveaux véhicules correspondants.",
+		AlertSubject:      "Nouveaux véhicules pour votre recherche",
+		AlertIntro:        "Ces véhicules correspondent désormais à votre recherche :",
+		AlertMore:         "et %d autres",
+		AlertLink:         "Voir tous les véhicules",
+		Unsubscribe:       "Se désabonner de cette recherche",
+		UnsubscribePrompt: "Voulez-vous vous désabonner de cette recherche ? Vous ne recevrez plus d'e-mails à son sujet.",
+		UnsubscribeButton: "Se désabonner",
+		AllCars:           "Tous les véhicules",
+	},
+	"it": {
+		ConfirmSubject:    "Confermi la Sua ricerca salvata",
+		ConfirmIntro:      "Desidera ricevere un'e-mail non appena nuovi veicoli corrispondono alla Sua ricerca:",
+		ConfirmButton:     "Conferma la ricerca",
+		ConfirmIgnore:     "Se non ha creato questa ricerca, può ignorare questa e-mail. Senza conferma verrà eliminata dopo %d giorni.",
+		ConfirmPrompt:     "Confermi la Sua ricerca per essere avvisato via e-mail dei nuovi veicoli corrispondenti.",
+		AlertSubject:      "Nuovi veicoli per la Sua ricerca",
+		AlertIntro:        "Questi veicoli corrispondono ora alla Sua ricerca:",
+		AlertMore:         "e altri %d",
+		AlertLink:         "Vedi tutti i veicoli",
+		Unsubscribe:       "Annulla questa ricerca",
+		UnsubscribePrompt: "Desidera annullare questa ricerca? Non riceverà più e-mail al riguardo.",
+		UnsubscribeButton: "Annulla la ricerca",
+		AllCars:           "Tutti i veicoli",
+	},
+	"en": {
+		ConfirmSubject:    "Please confirm your saved search",
+		ConfirmIntro:      "You asked to be notified by email as soon as new cars match your search:",
+		ConfirmButton:     "Confirm saved search",
+		ConfirmIgnore:     "If you did not create this saved search, you can ignore this email. Without confirmation it is deleted after %d days.",
+		ConfirmPrompt:     "Confirm your saved search to be notified by email about new matching cars.",
+		AlertSubject:      "New cars for your saved search",
+		AlertIntro:        "These cars now match your search:",
+		AlertMore:         "and %d more",
+		AlertLink:         "View all cars",
+		Unsubscribe:       "Unsubscribe from this saved search",
+		UnsubscribePrompt: "Do you want to unsubscribe from this saved search? You will no longer receive emails about it.",
+		UnsubscribeButton: "Unsubscribe",
+		AllCars:           "All cars",
+	},
+}
+
+// emailTexts returns the email texts in lang, falling back to German
+func emailTexts(lang string) savedSearchTexts {
+	if texts, ok := savedSearchEmailTexts[lang]; ok {
+		return texts
+	}
+	return savedSearchEmailTexts[savedSearchLanguage]
+}
+
+// searchSummaryLabels translates the parts of a search summary
+var searchSummaryLabels = map[string]map[string]string{
+	"price":    {"de": "Preis", "fr": "Prix", "it": "Prezzo", "en": "Price"},
+	"mileage":  {"de": "Kilometerstand", "fr": "Kilométrage", "it": "Chilometraggio", "en": "Mileage"},
+	"power":    {"de": "Leistung", "fr": "Puissance", "it": "Potenza", "en": "Power"},
+	"year":     {"de": "Jahrgang", "fr": "Année", "it": "Anno", "en": "Year"},
+	"leasing":  {"de": "Leasingrate", "fr": "Mensualité de leasing", "it": "Rata di leasing", "en": "Leasing rate"},
+	"from":     {"de": "ab", "fr": "dès", "it": "da", "en": "from"},
+	"to":       {"de": "bis", "fr": "jusqu'à", "it": "fino a", "en": "up to"},
+	"hp":       {"de": "PS", "fr": "ch", "it": "CV", "en": "hp"},
+	"mfk":      {"de": "MFK", "fr": "Expertisé", "it": "Collaudato", "en": "MFK inspected"},
+	"warranty": {"de": "Mit Garantie", "fr": "Avec garantie", "it": "Con garanzia", "en": "With warranty"},
+}
+
+// searchSummary describes the filters of a saved search for the emails
+func searchSummary(search SearchRequest, lang string) []string {
+	label := func(key string) string { return searchSummaryLabels[key][lang] }
+	vocabularyLabel := func(vocabulary *enumVocabulary, filter string) string {
+		if value, ok := vocabulary.Lookup(filter); ok {
+			return enumLabel(lang, value.Label)
+		}
+		return filter
+	}
+	rangeText := func(key string, min, max *int, format func(int) string) string {
+		switch {
+		case min != nil && max != nil:
+			return fmt.Sprintf("%s: %s – %s", label(key), format(*min), format(*max))
+		case min != nil:
+			return fmt.Sprintf("%s: %s %s", label(key), label("from"), format(*min))
+		case max != nil:
+			return fmt.Sprintf("%s: %s %s", label(key), label("to"), format(*max))
+		}
+		return ""
+	}
+	chf := func(n int) string { return "CHF " + formatSwissInt(n) }
+	km := func(n int) string { return formatSwissInt(n) + " km" }
+	hp := func(n int) string { return fmt.Sprintf("%d %s", n, label("hp")) }
+	year := func(n int) string { return fmt.Sprint(n) }
+
+	parts := []string{
+		strings.TrimSpace(search.Brand + " " + search.Model),
+	}
+	if search.Query != "" {
+		parts = append(parts, fmt.Sprintf("«%s»", search.Query))
+	}
+	if search.CarType != "" {
+		parts = append(parts, vocabularyLabel(carTypeVocabulary, search.CarType))
+	}
+	if search.Fuel != "" {
+		parts = append(parts, vocabularyLabel(fuelVocabulary, search.Fuel))
+	}
+	if search.Transmission != "" {
+		parts = append(parts, vocabularyLabel(transmissionVocabulary, search.Transmission))
+	}
+	if search.Drive != "" {
+		parts = append(parts, vocabularyLabel(driveVocabulary, search.Drive))
+	}
+	parts = append(parts,
+		rangeText("price", search.MinPrice, search.MaxPrice, chf),
+		rangeText("mileage", search.MinMileage, search.MaxMileage, km),
+		rangeText("power", search.MinPower, search.MaxPower, hp),
+		rangeText("year", search.MinYear, search.MaxYear, year),
+		rangeText("leasing", nil, search.MaxLeasingRate, chf),
+	)
+	if search.MFK != nil && *search.MFK {
+		parts = append(parts, label("mfk"))
+	}
+	if search.Warranty != nil && *search.Warranty {
+		parts = append(parts, label("warranty"))
+	}
+	parts = append(parts, localizeEquipment(lang, search.Equipment)...)
+
+	summary := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part != "" {
+			// Brand, model and query were HTML-escaped by validateSearchRequest;
+			// the template escapes them again
+			summary = append(summary, html.UnescapeString(part))
+		}
+	}
+	if len(summary) == 0 {
+		summary = append(summary, emailTexts(lang).AllCars)
+	}
+	return summary
+}
+
+// savedSearchLink returns the URL of a confirmation or unsubscribe link
+func savedSearchLink(config *SavedSearchConfig, action string, search SavedSearch) string {
+	query := url.Values{"token": {search.ID}, "lang": {search.Lang}}
+	return config.APIURL + "/saved-searches/" + action + "?" + query.Encode()
+}
+
+// savedSearchPageLanguage returns the language of the link pages. The links
+// carry the language of the search, so lang is only empty for edited links.
+func savedSearchPageLanguage(lang string) string {
+	if lang == "" {
+		return savedSearchLanguage
+	}
+	return lang
+}
+
+// savedSearchPageTitle returns the heading of the page of a link action
+func savedSearchPageTitle(action, lang string) string {
+	if action == "confirm" {
+		return emailTexts(lang).ConfirmButton
+	}
+	return emailTexts(lang).Unsubscribe
+}
+
+// savedSearchLinkPage shows the form of a confirmation or unsubscribe link.
+// Only the token format is checked, so prefetching a link reads nothing.
+func savedSearchLinkPage(action, token, lang string, headers map[string]string) events.APIGatewayProxyResponse {
+	pageLang := savedSearchPageLanguage(lang)
+	data := savedSearchPageData{Lang: pageLang, Title: savedSearchPageTitle(action, pageLang)}
+	if !savedSearchTokenRegex.MatchString(token) {
+		data.Text = messageText(pageLang, msgSavedSearchNotFound)
+		return savedSearchPage(http.StatusNotFound, headers, data)
+	}
+
+	texts := emailTexts(pageLang)
+	data.FormURL = savedSearchLink(savedSearches, action, SavedSearch{ID: token, Lang: pageLang})
+	if action == "confirm" {
+		data.Text, data.ButtonText = texts.ConfirmPrompt, texts.ConfirmButton
+	} else {
+		data.Text, data.ButtonText = texts.UnsubscribePrompt, texts.UnsubscribeButton
+	}
+	return savedSearchPage(http.StatusOK, headers, data)
+}
+
+// savedSearchPageData is the data of savedSearchPageTemplate. The form is
+// left out without FormURL.
+type savedSearchPageData struct {
+	Lang       string
+	Title      string
+	Text       string
+	FormURL    string
+	ButtonText string
+}
+
+// savedSearchPageTemplate renders the pages of the confirmation and unsubscribe links
+var savedSearchPageTemplate = template.Must(template.New("saved-search-page").Parse(`<!DOCTYPE html>
+<html lang="{{.Lang}}">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1">
+    <meta name="robots" content="noindex">
+    <title>{{.Title}}</title>
+    <style>
+        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
+        .button { background-color: #2c3e50; color: white; padding: 10px 20px; border: none; font-size: 16px; cursor: pointer; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <h1>{{.Title}}</h1>
+        <p>{{.Text}}</p>
+        {{if .FormURL}}
+        <form method="post" action="{{.FormURL}}">
+            <button class="button" type="submit">{{.ButtonText}}</button>
+        </form>
+        {{end}}
+    </div>
+</body>
+</html>
+`))
+
+// savedSearchPage renders a link page. The token is in the URL, so the page
+// is neither cached nor sent as referrer.
+func savedSearchPage(status int, headers map[string]string, data savedSearchPageData) events.APIGatewayProxyResponse {
+	var body bytes.Buffer
+	if err := savedSearchPageTemplate.Execute(&body, data); err != nil {
+		log.Printf("Error rendering saved search page: %v", err)
+		return errorResponse(http.StatusInternalServerError, headers, data.Lang, msgInternalError)
+	}
+
+	pageHeaders := make(map[string]string, len(headers)+3)
+	for key, value := range headers {
+		pageHeaders[key] = value
+	}
+	pageHeaders["Content-Type"] = "text/html; charset=utf-8"
+	pageHeaders["Cache-Control"] = "no-store"
+	pageHeaders["Referrer-Policy"] = "no-referrer"
+	return events.APIGatewayProxyResponse{StatusCode: status, Headers: pageHeaders, Body: body.String()}
+}
+
+// alertCar is a car line of an alert email
+type alertCar struct {
+	Title    string
+	Price    string
+	Details  string
+	ImageURL string
+}
+
+// savedSearchEmailData is the data of savedSearchEmailTemplate
+type savedSearchEmailData struct {
+	Lang            string
+	Intro           string
+	Summary         []string
+	Cars            []alertCar
+	More            string
+	ButtonURL       string
+	ButtonText      string
+	Footer          string
+	UnsubscribeURL  string
+	UnsubscribeText string
+}
+
+// savedSearchEmailTemplate renders both the confirmation and the alert emails
+var savedSearchEmailTemplate = template.Must(template.New("saved-search").Parse(`<!DOCTYPE html>
+<html lang="{{.Lang}}">
+<head>
+    <meta charset="UTF-8">
+    <style>
+        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
+        .summary { background-color: #f9f9f9; padding: 10px 15px; margin-bottom: 20px; }
+        .car { border-bottom: 1px solid #eee; padding: 10px 0; }
+        .car img { width: 120px; float: left; margin-right: 15px; }
+        .car .price { font-weight: bold; color: #2c3e50; }
+        .button { display: inline-block; background-color: #2c3e50; color: white; padding: 10px 20px; text-decoration: none; }
+        .footer { margin-top: 30px; font-size: 12px; color: #666; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <p>{{.Intro}}</p>
+        <div class="summary">{{range $i, $part := .Summary}}{{if $i}} · {{end}}{{$part}}{{end}}</div>
+        {{range .Cars}}
+        <div class="car">
+            {{if .ImageURL}}<img src="{{.ImageURL}}" alt="">{{end}}
+            <div><strong>{{.Title}}</strong></div>
+            <div class="price">{{.Price}}</div>
+            <div>{{.Details}}</div>
+            <div style="clear: both;"></div>
+        </div>
+        {{end}}
+        {{if .More}}<p>{{.More}}</p>{{end}}
+        <p><a class="button" href="{{.ButtonURL}}">{{.ButtonText}}</a></p>
+        <div class="footer">
+            {{if .Footer}}<p>{{.Footer}}</p>{{end}}
+            {{if .UnsubscribeURL}}<p><a href="{{.UnsubscribeURL}}">{{.UnsubscribeText}}</a></p>{{end}}
+        </div>
+    </div>
+</body>
+</html>
+`))
+
+func renderSavedSearchEmail(to, subject string, data savedSearchEmailData) (MailMessage, error) {
+	var body bytes.Buffer
+	if err := savedSearchEmailTemplate.Execute(&body, data); err != nil {
+		return MailMessage{}, fmt.Errorf("error rendering saved search email: %w", err)
+	}
+	return MailMessage{To: to, Subject: subject, HTML: body.String()}, nil
+}
+
+// confirmationEmail asks the owner of the address to confirm a saved search
+func confirmationEmail(search SavedSearch, config *SavedSearchConfig) (MailMessage, error) {
+	texts := emailTexts(search.Lang)
+	return renderSavedSearchEmail(search.Email, texts.ConfirmSubject, savedSearchEmailData{
+		Lang:       search.Lang,
+		Intro:      texts.ConfirmIntro,
+		Summary:    searchSummary(search.Search, search.Lang),
+		ButtonURL:  savedSearchLink(config, "confirm", search),
+		ButtonText: texts.ConfirmButton,
+		Footer:     fmt.Sprintf(texts.ConfirmIgnore, int(SavedSearchConfirmationTTL/(24*time.Hour))),
+	})
+}
+
+// alertEmail lists new cars of a saved search, at most MaxAlertCars of them
+func alertEmail(search SavedSearch, cars []Car, config *SavedSearchConfig) (MailMessage, error) {
+	texts := emailTexts(search.Lang)
+	data := savedSearchEmailData{
+		Lang:            search.Lang,
+		Intro:           texts.AlertIntro,
+		Summary:         searchSummary(search.Search, search.Lang),
+		ButtonURL:       config.SiteURL,
+		ButtonText:      texts.AlertLink,
+		UnsubscribeURL:  savedSearchLink(config, "unsubscribe", search),
+		UnsubscribeText: texts.Unsubscribe,
+	}
+	if len(cars) > MaxAlertCars {
+		data.More = fmt.Sprintf(texts.AlertMore, len(cars)-MaxAlertCars)
+		cars = cars[:MaxAlertCars]
+	}
+	for _, car := range cars {
+		// Catalogue texts are stored HTML-escaped; the template escapes them again
+		line := alertCar{
+			Title:   html.UnescapeString(car.Title),
+			Price:   "CHF " + formatSwissInt(car.PriceCHF),
+			Details: html.UnescapeString(alertCarDetails(car, search.Lang)),
+		}
+		if len(car.ImageURLs) > 0 {
+			line.ImageURL = car.ImageURLs[0]
+		}
+		data.Cars = append(data.Cars, line)
+	}
+	return renderSavedSearchEmail(search.Email, texts.AlertSubject, data)
+}
+
+// alertCarDetails joins the first registration, mileage, fuel and transmission of a car
+func alertCarDetails(car Car, lang string) string {
+	details := []string{car.FirstReg, formatSwissInt(car.MileageKM) + " km", enumLabel(lang, car.Fuel), enumLabel(lang, car.Transmission)}
+	parts := make([]string, 0, len(details))
+	for _, detail := range details {
+		if strings.TrimSpace(detail) != "" {
+			parts = append(parts, detail)
+		}
+	}
+	return strings.Join(parts, " · ")
+}
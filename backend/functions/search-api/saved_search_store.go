@@ -0,0 +1,285 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// ErrSavedSearchNotFound is returned by SavedSearchStore implementations
+var ErrSavedSearchNotFound = errors.New("saved search not found")
+
+// savedSearchEmailIndex is the global secondary index of the saved searches
+// table on "email_key"
+const savedSearchEmailIndex = "email-index"
+
+// SavedSearch is a search a customer gets email alerts for. The ID is the
+// token of the confirmation and unsubscribe links.
+type SavedSearch struct {
+	ID          string        `json:"id"`
+	Email       string        `json:"email"`
+	EmailKey    string        `json:"email_key"` // Lowercase email, the key of savedSearchEmailIndex
+	Search      SearchRequest `json:"search"`
+	Lang        string        `json:"lang"`
+	Confirmed   bool          `json:"confirmed"`
+	CreatedAt   time.Time     `json:"created_at"`
+	ConfirmedAt *time.Time    `json:"confirmed_at,omitempty"`
+	LastRunAt   *time.Time    `json:"last_run_at,omitempty"`
+
+	// Cars that matched at the last run; only other cars are alerted
+	SeenCarIDs []int `json:"seen_car_ids,omitempty"`
+}
+
+// SavedSearchStore persists saved searches
+type SavedSearchStore interface {
+	// List returns all saved searches ordered by creation time
+	List(ctx context.Context) ([]SavedSearch, error)
+	// CountByEmail returns the number of saved searches of an address,
+	// ignoring case
+	CountByEmail(ctx context.Context, email string) (int, error)
+	// Get returns the saved search with the given ID or ErrSavedSearchNotFound
+	Get(ctx context.Context, id string) (SavedSearch, error)
+	// Put creates or replaces a saved search
+	Put(ctx context.Context, search SavedSearch) error
+	// Update replaces an existing saved search or returns ErrSavedSearchNotFound,
+	// so searches deleted in the meantime are not written back
+	Update(ctx context.Context, search SavedSearch) error
+	// Delete removes a saved search or returns ErrSavedSearchNotFound
+	Delete(ctx context.Context, id string) error
+}
+
+// savedSearchEmailKey returns the EmailKey of an address
+func savedSearchEmailKey(email string) string {
+	return strings.ToLower(email)
+}
+
+// sortSavedSearches orders saved searches by creation time, then ID
+func sortSavedSearches(list []SavedSearch) {
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].CreatedAt.Equal(list[j].CreatedAt) {
+			return list[i].CreatedAt.Before(list[j].CreatedAt)
+		}
+		return list[i].ID < list[j].ID
+	})
+}
+
+// memorySavedSearchStore keeps saved searches in memory. It is used in tests
+// and for local development.
+type memorySavedSearchStore struct {
+	mu       sync.RWMutex
+	searches map[string]SavedSearch
+}
+
+func newMemorySavedSearchStore(initial ...SavedSearch) *memorySavedSearchStore {
+	s := &memorySavedSearchStore{searches: make(map[string]SavedSearch, len(initial))}
+	for _, search := range initial {
+		s.searches[search.ID] = search
+	}
+	return s
+}
+
+func (s *memorySavedSearchStore) List(ctx context.Context) ([]SavedSearch, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	list := make([]SavedSearch, 0, len(s.searches))
+	for _, search := range s.searches {
+		list = append(list, search)
+	}
+	sortSavedSearches(list)
+	return list, nil
+}
+
+func (s *memorySavedSearchStore) CountByEmail(ctx context.Context, email string) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	count := 0
+	for _, search := range s.searches {
+		if strings.EqualFold(search.Email, email) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (s *memorySavedSearchStore) Get(ctx context.Context, id string) (SavedSearch, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	search, ok := s.searches[id]
+	if !ok {
+		return SavedSearch{}, ErrSavedSearchNotFound
+	}
+	return search, nil
+}
+
+func (s *memorySavedSearchStore) Put(ctx context.Context, search SavedSearch) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.searches[search.ID] = search
+	return nil
+}
+
+func (s *memorySavedSearchStore) Update(ctx context.Context, search SavedSearch) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.searches[search.ID]; !ok {
+		return ErrSavedSearchNotFound
+	}
+	s.searches[search.ID] = search
+	return nil
+}
+
+func (s *memorySavedSearchStore) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.searches[id]; !ok {
+		return ErrSavedSearchNotFound
+	}
+	delete(s.searches, id)
+	return nil
+}
+
+// dynamoSavedSearchStore stores saved searches in a DynamoDB table with the
+// string partition key "id" and savedSearchEmailIndex
+type dynamoSavedSearchStore struct {
+	client dynamodbiface.DynamoDBAPI
+	table  string
+}
+
+func newDynamoSavedSearchStore(table string) *dynamoSavedSearchStore {
+	sess := session.Must(session.NewSession())
+	return &dynamoSavedSearchStore{client: dynamodb.New(sess), table: table}
+}
+
+func (s *dynamoSavedSearchStore) key(id string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		"id": {S: aws.String(id)},
+	}
+}
+
+func (s *dynamoSavedSearchStore) List(ctx context.Context) ([]SavedSearch, error) {
+	var list []SavedSearch
+	var unmarshalErr error
+
+	err := s.client.ScanPagesWithContext(ctx, &dynamodb.ScanInput{
+		TableName: aws.String(s.table),
+	}, func(page *dynamodb.ScanOutput, lastPage bool) bool {
+		var searches []SavedSearch
+		if err := dynamodbattribute.UnmarshalListOfMaps(page.Items, &searches); err != nil {
+			unmarshalErr = err
+			return false
+		}
+		list = append(list, searches...)
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error scanning %s: %w", s.table, err)
+	}
+	if unmarshalErr != nil {
+		return nil, fmt.Errorf("error decoding saved searches: %w", unmarshalErr)
+	}
+
+	sortSavedSearches(list)
+	return list, nil
+}
+
+// CountByEmail queries savedSearchEmailIndex, so it does not read the whole
+// table like List. The index is eventually consistent.
+func (s *dynamoSavedSearchStore) CountByEmail(ctx context.Context, email string) (int, error) {
+	count := 0
+	err := s.client.QueryPagesWithContext(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(s.table),
+		IndexName:              aws.String(savedSearchEmailIndex),
+		KeyConditionExpression: aws.String("email_key = :email"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":email": {S: aws.String(savedSearchEmailKey(email))},
+		},
+		Select: aws.String(dynamodb.SelectCount),
+	}, func(page *dynamodb.QueryOutput, lastPage bool) bool {
+		count += int(aws.Int64Value(page.Count))
+		return true
+	})
+	if err != nil {
+		return 0, fmt.Errorf("error querying %s: %w", savedSearchEmailIndex, err)
+	}
+	return count, nil
+}
+
+func (s *dynamoSavedSearchStore) Get(ctx context.Context, id string) (SavedSearch, error) {
+	output, err := s.client.GetItemWithContext(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(s.table),
+		Key:            s.key(id),
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return SavedSearch{}, fmt.Errorf("error reading saved search: %w", err)
+	}
+	if len(output.Item) == 0 {
+		return SavedSearch{}, ErrSavedSearchNotFound
+	}
+
+	var search SavedSearch
+	if err := dynamodbattribute.UnmarshalMap(output.Item, &search); err != nil {
+		return SavedSearch{}, fmt.Errorf("error decoding saved search: %w", err)
+	}
+	return search, nil
+}
+
+func (s *dynamoSavedSearchStore) Put(ctx context.Context, search SavedSearch) error {
+	return s.put(ctx, search, nil)
+}
+
+func (s *dynamoSavedSearchStore) Update(ctx context.Context, search SavedSearch) error {
+	return s.put(ctx, search, aws.String("attribute_exists(id)"))
+}
+
+// put writes a saved search if condition holds, returning ErrSavedSearchNotFound otherwise
+func (s *dynamoSavedSearchStore) put(ctx context.Context, search SavedSearch, condition *string) error {
+	item, err := dynamodbattribute.MarshalMap(search)
+	if err != nil {
+		return fmt.Errorf("error encoding saved search: %w", err)
+	}
+
+	_, err = s.client.PutItemWithContext(ctx, &dynamodb.PutItemInput{
+		TableName:           aws.String(s.table),
+		Item:                item,
+		ConditionExpression: condition,
+	})
+	if isConditionalCheckFailed(err) {
+		return ErrSavedSearchNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("error writing saved search: %w", err)
+	}
+	return nil
+}
+
+func (s *dynamoSavedSearchStore) Delete(ctx context.Context, id string) error {
+	_, err := s.client.DeleteItemWithContext(ctx, &dynamodb.DeleteItemInput{
+		TableName:           aws.String(s.table),
+		Key:                 s.key(id),
+		ConditionExpression: aws.String("attribute_exists(id)"),
+	})
+	if isConditionalCheckFailed(err) {
+		return ErrSavedSearchNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("error deleting saved search: %w", err)
+	}
+	return nil
+}
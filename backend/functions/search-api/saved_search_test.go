@@ -0,0 +1,578 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// setTestSavedSearches enables saved searches with in-memory storage and mail
+// for the duration of a test
+func setTestSavedSearches(t *testing.T, initial ...SavedSearch) (*memorySavedSearchStore, *memoryMailer) {
+	t.Helper()
+
+	store, mailer := newMemorySavedSearchStore(initial...), &memoryMailer{}
+	original := savedSearches
+	savedSearches = &SavedSearchConfig{
+		Store:   store,
+		Mailer:  mailer,
+		APIURL:  "https://api.example.ch",
+		SiteURL: "https://www.example.ch",
+	}
+	t.Cleanup(func() { savedSearches = original })
+	return store, mailer
+}
+
+func savedSearchRequest(method, resource, body string, query map[string]string) events.APIGatewayProxyRequest {
+	return events.APIGatewayProxyRequest{
+		Resource:              resource,
+		HTTPMethod:            method,
+		Body:                  body,
+		QueryStringParameters: query,
+	}
+}
+
+var savedSearchTokenPattern = regexp.MustCompile(`token=([0-9a-f]{32})`)
+
+// savedSearchToken returns the token of the first link in an email
+func savedSearchToken(t *testing.T, message MailMessage) string {
+	t.Helper()
+
+	match := savedSearchTokenPattern.FindStringSubmatch(message.HTML)
+	if match == nil {
+		t.Fatalf("No token link in email:\n%s", message.HTML)
+	}
+	return match[1]
+}
+
+func decodeMessage(t *testing.T, response events.APIGatewayProxyResponse) string {
+	t.Helper()
+
+	var body MessageResponse
+	if err := json.Unmarshal([]byte(response.Body), &body); err != nil {
+		t.Fatalf("Failed to decode response %q: %v", response.Body, err)
+	}
+	return body.Message
+}
+
+func TestSavedSearchLifecycle(t *testing.T) {
+	ctx := context.Background()
+	cars := setTestCarStore(t, testStoredCar(1, "BMW 520d xDrive"), testStoredCar(2, "Audi A6 Avant"))
+	store, mailer := setTestSavedSearches(t)
+
+	// Save: pending until confirmed, the confirmation is in the request language
+	request := savedSearchRequest("POST", "/saved-searches", `{"email": " kunde@example.ch ", "search": {"brand": "BMW", "sort": "price_asc", "limit": 50}}`, nil)
+	request.Headers = map[string]string{"Accept-Language": "fr-CH"}
+	response, err := handleRequest(ctx, request)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if response.StatusCode != http.StatusAccepted {
+		t.Fatalf("Expected status 202, got %d: %s", response.StatusCode, response.Body)
+	}
+	if message := decodeMessage(t, response); message != "E-mail de confirmation envoyé" {
+		t.Errorf("Expected French message, got %q", message)
+	}
+	if strings.Contains(response.Body, "token") || strings.Contains(response.Body, "id") {
+		t.Errorf("Response must not reveal the token: %s", response.Body)
+	}
+
+	sent := mailer.Sent()
+	if len(sent) != 1 {
+		t.Fatalf("Expected 1 confirmation email, got %d", len(sent))
+	}
+	if sent[0].To != "kunde@example.ch" || sent[0].Subject != savedSearchEmailTexts["fr"].ConfirmSubject {
+		t.Errorf("Unexpected confirmation email to %q with subject %q", sent[0].To, sent[0].Subject)
+	}
+	if !strings.Contains(sent[0].HTML, "https://api.example.ch/saved-searches/confirm?") {
+		t.Errorf("Confirmation email has no confirmation link:\n%s", sent[0].HTML)
+	}
+	token := savedSearchToken(t, sent[0])
+
+	saved, err := store.Get(ctx, token)
+	if err != nil {
+		t.Fatalf("Saved search not stored: %v", err)
+	}
+	if saved.Confirmed || saved.Lang != "fr" || saved.Search.Brand != "BMW" || saved.EmailKey != "kunde@example.ch" {
+		t.Errorf("Unexpected saved search: %+v", saved)
+	}
+	if saved.Search.Sort != "" || saved.Search.Limit != 0 {
+		t.Errorf("Expected sorting and pagination to be dropped, got %+v", saved.Search)
+	}
+
+	// Unconfirmed searches get no alerts
+	if err := cars.Create(ctx, testStoredCar(3, "BMW X3 xDrive30d")); err != nil {
+		t.Fatalf("Failed to add car: %v", err)
+	}
+	result, err := runSavedSearchAlerts(ctx, time.Now())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Checked != 0 || len(mailer.Sent()) != 1 {
+		t.Errorf("Expected no alerts before confirmation, got %+v", result)
+	}
+
+	// Opening the link only shows a form, so link scanners confirm nothing
+	response = handleSavedSearches(ctx, savedSearchRequest("GET", "/saved-searches/confirm", "", map[string]string{"token": token, "lang": "fr"}), "fr", corsHeaders())
+	if response.StatusCode != http.StatusOK || !strings.HasPrefix(response.Headers["Content-Type"], "text/html") {
+		t.Fatalf("Expected a page, got %d %q: %s", response.StatusCode, response.Headers["Content-Type"], response.Body)
+	}
+	for _, expected := range []string{`<html lang="fr">`, `method="post"`, "token=" + token, savedSearchEmailTexts["fr"].ConfirmButton} {
+		if !strings.Contains(response.Body, expected) {
+			t.Errorf("Expected confirmation page to contain %q:\n%s", expected, response.Body)
+		}
+	}
+	if saved, _ = store.Get(ctx, token); saved.Confirmed {
+		t.Error("Expected GET to leave the search unconfirmed")
+	}
+
+	// Confirm: the form posts back, the current matches count as seen
+	request = savedSearchRequest("POST", "/saved-searches/confirm", "", map[string]string{"token": token, "lang": "fr"})
+	request.Headers = map[string]string{"content-type": "application/x-www-form-urlencoded"}
+	response = handleSavedSearches(ctx, request, "fr", corsHeaders())
+	if response.StatusCode != http.StatusOK || !strings.Contains(response.Body, "Recherche enregistrée confirmée") {
+		t.Fatalf("Unexpected confirmation page %d: %s", response.StatusCode, response.Body)
+	}
+	saved, _ = store.Get(ctx, token)
+	if !saved.Confirmed || saved.ConfirmedAt == nil || !reflect.DeepEqual(saved.SeenCarIDs, []int{1, 3}) {
+		t.Errorf("Unexpected confirmed search: %+v", saved)
+	}
+
+	// No new cars, no alert
+	result, _ = runSavedSearchAlerts(ctx, time.Now())
+	if result.Checked != 1 || result.Alerted != 0 || len(mailer.Sent()) != 1 {
+		t.Errorf("Expected no alert without new cars, got %+v", result)
+	}
+
+	// A new matching car is alerted once; other brands are not
+	if err := cars.Create(ctx, testStoredCar(4, "BMW 330e Touring")); err != nil {
+		t.Fatalf("Failed to add car: %v", err)
+	}
+	if err := cars.Create(ctx, testStoredCar(5, "Audi Q5 quattro")); err != nil {
+		t.Fatalf("Failed to add car: %v", err)
+	}
+	result, _ = runSavedSearchAlerts(ctx, time.Now())
+	if result.Alerted != 1 {
+		t.Fatalf("Expected 1 alert, got %+v", result)
+	}
+	sent = mailer.Sent()
+	alert := sent[len(sent)-1]
+	if alert.Subject != savedSearchEmailTexts["fr"].AlertSubject {
+		t.Errorf("Unexpected alert subject %q", alert.Subject)
+	}
+	if !strings.Contains(alert.HTML, "BMW 330e Touring") || strings.Contains(alert.HTML, "Audi Q5") || strings.Contains(alert.HTML, "BMW X3") {
+		t.Errorf("Alert should list only the new matching car:\n%s", alert.HTML)
+	}
+	if !strings.Contains(alert.HTML, "https://api.example.ch/saved-searches/unsubscribe?") || savedSearchToken(t, alert) != token {
+		t.Errorf("Alert has no unsubscribe link:\n%s", alert.HTML)
+	}
+
+	result, _ = runSavedSearchAlerts(ctx, time.Now())
+	if result.Alerted != 0 {
+		t.Errorf("Expected the car to be alerted only once, got %+v", result)
+	}
+
+	// Unsubscribe deletes the search; clients posting without a form get JSON
+	response = handleSavedSearches(ctx, savedSearchRequest("GET", "/saved-searches/unsubscribe", "", map[string]string{"token": token}), "", corsHeaders())
+	if response.StatusCode != http.StatusOK || !strings.Contains(response.Body, savedSearchEmailTexts["de"].UnsubscribePrompt) {
+		t.Fatalf("Unexpected unsubscribe page %d: %s", response.StatusCode, response.Body)
+	}
+	if _, err := store.Get(ctx, token); err != nil {
+		t.Errorf("Expected GET to keep the search, got %v", err)
+	}
+	response = handleSavedSearches(ctx, savedSearchRequest("POST", "/saved-searches/unsubscribe", "", map[string]string{"token": token}), "", corsHeaders())
+	if response.StatusCode != http.StatusOK || decodeMessage(t, response) != "Saved search deleted" {
+		t.Fatalf("Unexpected unsubscribe response %d: %s", response.StatusCode, response.Body)
+	}
+	if _, err := store.Get(ctx, token); !errors.Is(err, ErrSavedSearchNotFound) {
+		t.Errorf("Expected search to be deleted, got %v", err)
+	}
+	response = handleSavedSearches(ctx, savedSearchRequest("POST", "/saved-searches/unsubscribe", "", map[string]string{"token": token}), "", corsHeaders())
+	if response.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected status 404 for a second unsubscribe, got %d", response.StatusCode)
+	}
+}
+
+func TestCreateSavedSearchErrors(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		body           string
+		expectedStatus int
+		expectedField  string
+	}{
+		{name: "Wrong method", method: "GET", expectedStatus: http.StatusMethodNotAllowed},
+		{name: "Invalid JSON", method: "POST", body: `{`, expectedStatus: http.StatusBadRequest},
+		{name: "Missing email", method: "POST", body: `{"search": {}}`, expectedStatus: http.StatusBadRequest, expectedField: "email"},
+		{name: "Invalid email", method: "POST", body: `{"email": "kunde@", "search": {}}`, expectedStatus: http.StatusBadRequest, expectedField: "email"},
+		{name: "Invalid search", method: "POST", body: `{"email": "kunde@example.ch", "search": {"min_price": -1}}`, expectedStatus: http.StatusBadRequest, expectedField: "search.min_price"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setTestCars(t, []Car{testStoredCar(1, "BMW 520d xDrive")})
+			store, mailer := setTestSavedSearches(t)
+
+			response := handleSavedSearches(context.Background(), savedSearchRequest(tt.method, "/saved-searches", tt.body, nil), "", corsHeaders())
+			if response.StatusCode != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedStatus, response.StatusCode, response.Body)
+			}
+			if tt.expectedField != "" {
+				var body ErrorResponse
+				if err := json.Unmarshal([]byte(response.Body), &body); err != nil {
+					t.Fatalf("Failed to decode response: %v", err)
+				}
+				if len(body.Validations) == 0 || body.Validations[0].Field != tt.expectedField {
+					t.Errorf("Expected validation of %s, got %+v", tt.expectedField, body.Validations)
+				}
+			}
+			if list, _ := store.List(context.Background()); len(list) != 0 || len(mailer.Sent()) != 0 {
+				t.Errorf("Expected nothing to be stored or sent")
+			}
+		})
+	}
+}
+
+func TestCreateSavedSearchLimitPerEmail(t *testing.T) {
+	setTestCars(t, []Car{testStoredCar(1, "BMW 520d xDrive")})
+	var initial []SavedSearch
+	for i := 0; i < MaxSavedSearchesPerEmail; i++ {
+		initial = append(initial, SavedSearch{ID: fmt.Sprintf("%032x", i), Email: "Kunde@Example.ch", CreatedAt: time.Now()})
+	}
+	setTestSavedSearches(t, initial...)
+
+	response := handleSavedSearches(context.Background(), savedSearchRequest("POST", "/saved-searches", `{"email": "kunde@example.ch", "search": {}}`, nil), "", corsHeaders())
+	if response.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("Expected status 429, got %d: %s", response.StatusCode, response.Body)
+	}
+}
+
+func TestCreateSavedSearchMailFailure(t *testing.T) {
+	setTestCars(t, []Car{testStoredCar(1, "BMW 520d xDrive")})
+	store, mailer := setTestSavedSearches(t)
+	mailer.err = errors.New("SES unavailable")
+
+	response := handleSavedSearches(context.Background(), savedSearchRequest("POST", "/saved-searches", `{"email": "kunde@example.ch", "search": {}}`, nil), "", corsHeaders())
+	if response.StatusCode != http.StatusInternalServerError {
+		t.Errorf("Expected status 500, got %d: %s", response.StatusCode, response.Body)
+	}
+	if list, _ := store.List(context.Background()); len(list) != 0 {
+		t.Errorf("Expected the unconfirmed search to be removed, got %+v", list)
+	}
+}
+
+func TestSavedSearchesNotConfigured(t *testing.T) {
+	original := savedSearches
+	savedSearches = nil
+	t.Cleanup(func() { savedSearches = original })
+
+	response := handleSavedSearches(context.Background(), savedSearchRequest("POST", "/saved-searches", `{}`, nil), "", corsHeaders())
+	if response.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503, got %d", response.StatusCode)
+	}
+	if _, err := runSavedSearchAlerts(context.Background(), time.Now()); err == nil {
+		t.Error("Expected the alert job to fail without configuration")
+	}
+}
+
+func TestConfirmSavedSearchUnknownToken(t *testing.T) {
+	setTestSavedSearches(t)
+
+	for _, token := range []string{"", "../cars", strings.Repeat("a", 32)} {
+		response := handleSavedSearches(context.Background(), savedSearchRequest("POST", "/saved-searches/confirm", "", map[string]string{"token": token}), "", corsHeaders())
+		if response.StatusCode != http.StatusNotFound {
+			t.Errorf("Token %q: expected status 404, got %d", token, response.StatusCode)
+		}
+	}
+
+	// Malformed tokens get no form
+	response := handleSavedSearches(context.Background(), savedSearchRequest("GET", "/saved-searches/confirm", "", map[string]string{"token": "../cars"}), "", corsHeaders())
+	if response.StatusCode != http.StatusNotFound || strings.Contains(response.Body, "<form") {
+		t.Errorf("Expected a 404 page without form, got %d: %s", response.StatusCode, response.Body)
+	}
+
+	response = handleSavedSearches(context.Background(), savedSearchRequest("DELETE", "/saved-searches/unsubscribe", "", map[string]string{"token": strings.Repeat("a", 32)}), "", corsHeaders())
+	if response.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status 405, got %d", response.StatusCode)
+	}
+}
+
+func TestSavedSearchAlertsExpireUnconfirmed(t *testing.T) {
+	setTestCarStore(t, testStoredCar(1, "BMW 520d xDrive"))
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	store, _ := setTestSavedSearches(t,
+		SavedSearch{ID: "expired", Email: "a@example.ch", CreatedAt: now.Add(-SavedSearchConfirmationTTL - time.Hour)},
+		SavedSearch{ID: "pending", Email: "b@example.ch", CreatedAt: now.Add(-time.Hour)},
+		SavedSearch{ID: "confirmed", Email: "c@example.ch", CreatedAt: now.Add(-30 * 24 * time.Hour), Confirmed: true, SeenCarIDs: []int{1}},
+	)
+
+	result, err := runSavedSearchAlerts(context.Background(), now)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Expired != 1 || result.Checked != 1 {
+		t.Errorf("Unexpected result %+v", result)
+	}
+	list, _ := store.List(context.Background())
+	var ids []string
+	for _, search := range list {
+		ids = append(ids, search.ID)
+	}
+	if !reflect.DeepEqual(ids, []string{"confirmed", "pending"}) {
+		t.Errorf("Expected only the expired search to be deleted, got %v", ids)
+	}
+	if list[0].LastRunAt == nil || !list[0].LastRunAt.Equal(now) {
+		t.Errorf("Expected the last run to be recorded, got %v", list[0].LastRunAt)
+	}
+}
+
+func TestSavedSearchAlertsRetryAfterMailFailure(t *testing.T) {
+	ctx := context.Background()
+	setTestCarStore(t, testStoredCar(1, "BMW 520d xDrive"), testStoredCar(2, "BMW X3 xDrive30d"))
+	store, mailer := setTestSavedSearches(t, SavedSearch{ID: "confirmed", Email: "kunde@example.ch", Confirmed: true, SeenCarIDs: []int{1}})
+
+	mailer.err = errors.New("SES unavailable")
+	result, _ := runSavedSearchAlerts(ctx, time.Now())
+	if result.Failed != 1 {
+		t.Fatalf("Expected a failed alert, got %+v", result)
+	}
+	if saved, _ := store.Get(ctx, "confirmed"); !reflect.DeepEqual(saved.SeenCarIDs, []int{1}) {
+		t.Errorf("Expected seen cars to stay unchanged, got %v", saved.SeenCarIDs)
+	}
+
+	mailer.err = nil
+	result, _ = runSavedSearchAlerts(ctx, time.Now())
+	if result.Alerted != 1 || len(mailer.Sent()) != 1 {
+		t.Errorf("Expected the alert to be sent on the next run, got %+v", result)
+	}
+}
+
+// interruptedSavedSearchStore runs beforeUpdate between reading a search and
+// writing it back, e.g. to delete it like a concurrent unsubscribe
+type interruptedSavedSearchStore struct {
+	*memorySavedSearchStore
+	beforeUpdate func(ctx context.Context, search SavedSearch) error
+}
+
+func (s *interruptedSavedSearchStore) Update(ctx context.Context, search SavedSearch) error {
+	if err := s.beforeUpdate(ctx, search); err != nil {
+		return err
+	}
+	return s.memorySavedSearchStore.Update(ctx, search)
+}
+
+func TestSavedSearchDeletedBeforeWrite(t *testing.T) {
+	ctx := context.Background()
+	setTestCarStore(t, testStoredCar(1, "BMW 520d xDrive"), testStoredCar(2, "BMW X3 xDrive30d"))
+	token := strings.Repeat("c", 32)
+	store, mailer := setTestSavedSearches(t,
+		SavedSearch{ID: "confirmed", Email: "kunde@example.ch", Confirmed: true, SeenCarIDs: []int{1}},
+		SavedSearch{ID: token, Email: "kunde@example.ch"},
+	)
+	savedSearches.Store = &interruptedSavedSearchStore{
+		memorySavedSearchStore: store,
+		beforeUpdate: func(ctx context.Context, search SavedSearch) error {
+			return store.Delete(ctx, search.ID)
+		},
+	}
+
+	// The alert is sent, but the unsubscribed search is not written back
+	result, err := runSavedSearchAlerts(ctx, time.Now())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Alerted != 1 || result.Failed != 0 || result.Unrecorded != 0 || len(mailer.Sent()) != 1 {
+		t.Errorf("Unexpected result %+v", result)
+	}
+	if _, err := store.Get(ctx, "confirmed"); !errors.Is(err, ErrSavedSearchNotFound) {
+		t.Errorf("Expected the unsubscribed search to stay deleted, got %v", err)
+	}
+
+	// Confirming a search deleted in the meantime does not restore it
+	response := handleSavedSearches(ctx, savedSearchRequest("POST", "/saved-searches/confirm", "", map[string]string{"token": token}), "", corsHeaders())
+	if response.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d: %s", response.StatusCode, response.Body)
+	}
+	if _, err := store.Get(ctx, token); !errors.Is(err, ErrSavedSearchNotFound) {
+		t.Errorf("Expected the search to stay deleted, got %v", err)
+	}
+}
+
+func TestSavedSearchAlertsRepeatUnrecordedAlert(t *testing.T) {
+	ctx := context.Background()
+	setTestCarStore(t, testStoredCar(1, "BMW 520d xDrive"), testStoredCar(2, "BMW X3 xDrive30d"))
+	store, mailer := setTestSavedSearches(t, SavedSearch{ID: "confirmed", Email: "kunde@example.ch", Confirmed: true, SeenCarIDs: []int{1}})
+	writeErr := errors.New("DynamoDB unavailable")
+	savedSearches.Store = &interruptedSavedSearchStore{
+		memorySavedSearchStore: store,
+		beforeUpdate:           func(ctx context.Context, search SavedSearch) error { return writeErr },
+	}
+
+	result, _ := runSavedSearchAlerts(ctx, time.Now())
+	if result.Alerted != 1 || result.Unrecorded != 1 || result.Failed != 0 {
+		t.Fatalf("Expected an unrecorded alert, got %+v", result)
+	}
+
+	// Delivery is at-least-once: the next run sends the same cars again
+	writeErr = nil
+	result, _ = runSavedSearchAlerts(ctx, time.Now())
+	if result.Alerted != 1 || len(mailer.Sent()) != 2 {
+		t.Errorf("Expected the alert to be repeated, got %+v", result)
+	}
+	if saved, _ := store.Get(ctx, "confirmed"); !reflect.DeepEqual(saved.SeenCarIDs, []int{1, 2}) {
+		t.Errorf("Expected seen cars to be recorded, got %v", saved.SeenCarIDs)
+	}
+}
+
+func TestIsSavedSearchAlertsEvent(t *testing.T) {
+	tests := []struct {
+		raw      string
+		expected bool
+	}{
+		{`{"job": "saved-search-alerts"}`, true},
+		{`{"job": "other"}`, false},
+		{`{"source": "aws.events", "detail-type": "Scheduled Event"}`, false},
+		{`{"httpMethod": "GET", "resource": "/search"}`, false},
+		{`[]`, false},
+	}
+
+	for _, tt := range tests {
+		if result := isSavedSearchAlertsEvent(json.RawMessage(tt.raw)); result != tt.expected {
+			t.Errorf("isSavedSearchAlertsEvent(%s) = %v, expected %v", tt.raw, result, tt.expected)
+		}
+	}
+}
+
+func TestValidEmail(t *testing.T) {
+	tests := []struct {
+		email    string
+		expected bool
+	}{
+		{"kunde@example.ch", true},
+		{"vorname.nachname+auto@example.co.uk", true},
+		{"", false},
+		{"kunde", false},
+		{"kunde@localhost", false},
+		{"Kunde <kunde@example.ch>", false},
+		{"kunde@example.ch, other@example.ch", false},
+		{strings.Repeat("a", MaxEmailLength) + "@example.ch", false},
+	}
+
+	for _, tt := range tests {
+		if result := validEmail(tt.email); result != tt.expected {
+			t.Errorf("validEmail(%q) = %v, expected %v", tt.email, result, tt.expected)
+		}
+	}
+}
+
+func TestSearchSummary(t *testing.T) {
+	maxPrice, minYear, minMileage, maxMileage := 30000, 2019, 10000, 80000
+	mfk := true
+
+	tests := []struct {
+		name     string
+		search   SearchRequest
+		lang     string
+		expected []string
+	}{
+		{
+			name:     "Empty search",
+			lang:     "de",
+			expected: []string{"Alle Fahrzeuge"},
+		},
+		{
+			name:     "German",
+			search:   SearchRequest{Brand: "BMW", Model: "520d", Fuel: "diesel", MaxPrice: &maxPrice, MinYear: &minYear, MFK: &mfk},
+			lang:     "de",
+			expected: []string{"BMW 520d", "Diesel", "Preis: bis CHF 30'000", "Jahrgang: ab 2019", "MFK"},
+		},
+		{
+			name:     "Escaped query",
+			search:   SearchRequest{Brand: "Mercedes-Benz", Query: "AMG &amp; Sport"},
+			lang:     "de",
+			expected: []string{"Mercedes-Benz", "«AMG & Sport»"},
+		},
+		{
+			name:     "French",
+			search:   SearchRequest{CarType: "estate", Transmission: "automatic", MinMileage: &minMileage, MaxMileage: &maxMileage, Equipment: []string{"navigation"}},
+			lang:     "fr",
+			expected: []string{enumLabel("fr", "Kombi"), enumLabel("fr", "Automatik"), "Kilométrage: 10'000 km – 80'000 km", equipmentLabel("fr", mustLookupEquipment(t, "navigation"))},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := searchSummary(tt.search, tt.lang); !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func mustLookupEquipment(t *testing.T, code string) equipmentFeature {
+	t.Helper()
+
+	feature, ok := lookupEquipment(code)
+	if !ok {
+		t.Fatalf("Unknown equipment %q", code)
+	}
+	return feature
+}
+
+func TestAlertEmailListsAtMostMaxAlertCars(t *testing.T) {
+	var cars []Car
+	for i := 1; i <= MaxAlertCars+5; i++ {
+		cars = append(cars, Car{ID: i, Title: fmt.Sprintf("Testauto %d &amp; Co", i), PriceCHF: 12500, MileageKM: 42000, FirstReg: "03.2020"})
+	}
+	search := SavedSearch{ID: strings.Repeat("b", 32), Email: "kunde@example.ch", Lang: "de"}
+	config := &SavedSearchConfig{APIURL: "https://api.example.ch", SiteURL: "https://www.example.ch"}
+
+	message, err := alertEmail(search, cars, config)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if strings.Count(message.HTML, `class="car"`) != MaxAlertCars {
+		t.Errorf("Expected %d cars in the email", MaxAlertCars)
+	}
+	for _, expected := range []string{"Testauto 20 &amp; Co", "CHF 12&#39;500", "03.2020 · 42&#39;000 km", "und 5 weitere", `href="https://www.example.ch"`} {
+		if !strings.Contains(message.HTML, expected) {
+			t.Errorf("Expected email to contain %q:\n%s", expected, message.HTML)
+		}
+	}
+	if strings.Contains(message.HTML, "Testauto 21") {
+		t.Error("Expected cars beyond MaxAlertCars to be left out")
+	}
+	if strings.Contains(message.HTML, "&amp;amp;") {
+		t.Error("Expected catalogue texts to be escaped once")
+	}
+}
+
+func TestSavedSearchEmailTextsAreComplete(t *testing.T) {
+	for _, lang := range supportedLanguages {
+		texts, ok := savedSearchEmailTexts[lang]
+		if !ok {
+			t.Errorf("No email texts for %s", lang)
+			continue
+		}
+		value := reflect.ValueOf(texts)
+		for i := 0; i < value.NumField(); i++ {
+			if value.Field(i).String() == "" {
+				t.Errorf("%s email text %s is empty", lang, value.Type().Field(i).Name)
+			}
+		}
+		for key, labels := range searchSummaryLabels {
+			if labels[lang] == "" {
+				t.Errorf("Summary label %q has no %s translation", key, lang)
+			}
+		}
+	}
+}
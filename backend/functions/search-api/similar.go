@@ -0,0 +1,121 @@
+package main
+
+import (
+	"math"
+	"sort"
+	"strconv"
+)
+
+// Limits for GET /cars/{id}/similar
+const (
+	DefaultSimilarLimit = 4
+	MaxSimilarLimit     = 20
+)
+
+// Weights of the similarity criteria; they add up to 1 so scores range from 0 to 1
+const (
+	similarityWeightBrand   = 0.25
+	similarityWeightCarType = 0.20
+	similarityWeightPrice   = 0.20
+	similarityWeightFuel    = 0.15
+	similarityWeightPower   = 0.10
+	similarityWeightMileage = 0.10
+)
+
+// Differences at which a numeric criterion no longer contributes to the score
+const (
+	similarPriceBand   = 0.30   // ±30% of the reference price
+	similarPowerBand   = 0.40   // ±40% of the reference power
+	similarMileageBand = 60_000 // km
+)
+
+// SimilarCar is a recommended car with its similarity to the reference car
+type SimilarCar struct {
+	LocalizedCar
+	Score float64 `json:"score"`
+}
+
+// SimilarCarsResponse lists the cars most similar to CarID
+type SimilarCarsResponse struct {
+	CarID int          `json:"car_id"`
+	Cars  []SimilarCar `json:"cars"`
+}
+
+// parseSimilarLimit reads the limit query parameter, falling back to the default for invalid values
+func parseSimilarLimit(value string) int {
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit <= 0 {
+		return DefaultSimilarLimit
+	}
+	if limit > MaxSimilarLimit {
+		return MaxSimilarLimit
+	}
+	return limit
+}
+
+// findCar returns the car with the given ID from the current catalogue
+func findCar(id int) (Car, bool) {
+	for _, car := range currentCars() {
+		if car.ID == id {
+			return car, true
+		}
+	}
+	return Car{}, false
+}
+
+// similarCars ranks the other unsold cars by similarity to reference. Ties are
+// broken by ID, so the result only depends on the catalogue.
+func similarCars(reference Car, limit int) []SimilarCar {
+	candidates := make([]SimilarCar, 0)
+	for _, car := range currentCars() {
+		if car.ID == reference.ID || car.Status == StatusSold {
+			continue
+		}
+		candidates = append(candidates, SimilarCar{LocalizedCar: LocalizedCar{Car: car}, Score: similarityScore(reference, car)})
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].Score != candidates[j].Score {
+			return candidates[i].Score > candidates[j].Score
+		}
+		return candidates[i].ID < candidates[j].ID
+	})
+
+	if len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+	return candidates
+}
+
+// similarityScore rates how close car is to reference, from 0 (nothing in common) to 1
+func similarityScore(reference, car Car) float64 {
+	score := 0.0
+
+	if reference.Brand != "" && normalizeString(reference.Brand) == normalizeString(car.Brand) {
+		score += similarityWeightBrand
+	}
+	if reference.CarType != "" && reference.CarType == car.CarType {
+		score += similarityWeightCarType
+	}
+	if reference.Fuel != "" && reference.Fuel == car.Fuel {
+		score += similarityWeightFuel
+	}
+
+	score += similarityWeightPrice * closeness(float64(car.PriceCHF-reference.PriceCHF), similarPriceBand*float64(reference.PriceCHF))
+	score += similarityWeightPower * closeness(float64(car.PowerHP-reference.PowerHP), similarPowerBand*float64(reference.PowerHP))
+	score += similarityWeightMileage * closeness(float64(car.MileageKM-reference.MileageKM), similarMileageBand)
+
+	// Rounded so that equal cars get equal scores regardless of float noise
+	return math.Round(score*1000) / 1000
+}
+
+// closeness falls linearly from 1 for no difference to 0 at band
+func closeness(diff, band float64) float64 {
+	if band <= 0 {
+		if diff == 0 {
+			return 1
+		}
+		return 0
+	}
+	return math.Max(0, 1-math.Abs(diff)/band)
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func similarTestCars() []Car {
+	return []Car{
+		{ID: 1, Title: "BMW 520d", Brand: "BMW", CarType: "Limousine", Fuel: "Diesel", PriceCHF: 42000, PowerHP: 190, MileageKM: 55000, Status: StatusAvailable},
+		{ID: 2, Title: "BMW 530d", Brand: "BMW", CarType: "Limousine", Fuel: "Diesel", PriceCHF: 46000, PowerHP: 286, MileageKM: 40000, Status: StatusAvailable},
+		{ID: 3, Title: "Audi A6", Brand: "Audi", CarType: "Limousine", Fuel: "Diesel", PriceCHF: 41000, PowerHP: 204, MileageKM: 60000, Status: StatusAvailable},
+		{ID: 4, Title: "BMW X5", Brand: "BMW", CarType: "SUV", Fuel: "Benzin", PriceCHF: 89000, PowerHP: 340, MileageKM: 12000, Status: StatusAvailable},
+		{ID: 5, Title: "Fiat Panda", Brand: "Fiat", CarType: "Kleinwagen", Fuel: "Benzin", PriceCHF: 9000, PowerHP: 69, MileageKM: 150000, Status: StatusAvailable},
+		{ID: 6, Title: "BMW 520d", Brand: "BMW", CarType: "Limousine", Fuel: "Diesel", PriceCHF: 42000, PowerHP: 190, MileageKM: 55000, Status: StatusSold},
+		// Same score as car 3, ranked after it by ID
+		{ID: 7, Title: "Audi A6", Brand: "Audi", CarType: "Limousine", Fuel: "Diesel", PriceCHF: 41000, PowerHP: 204, MileageKM: 60000, Status: StatusReserved},
+	}
+}
+
+func TestSimilarityScore(t *testing.T) {
+	reference := similarTestCars()[0]
+
+	tests := []struct {
+		name     string
+		car      Car
+		expected float64
+	}{
+		{name: "Identical car", car: reference, expected: 1},
+		{name: "Nothing in common", car: Car{Brand: "Fiat", CarType: "Kleinwagen", Fuel: "Benzin", PriceCHF: 9000, PowerHP: 69, MileageKM: 150000}, expected: 0},
+		// Type and fuel match; price 1000 of 12600 off, power 14 of 76 off, mileage 5000 of 60000 off
+		{name: "Close competitor", car: Car{Brand: "Audi", CarType: "Limousine", Fuel: "Diesel", PriceCHF: 41000, PowerHP: 204, MileageKM: 60000}, expected: 0.707},
+		{name: "Brand is case-insensitive", car: Car{Brand: "bmw", MileageKM: 200000}, expected: 0.25},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if score := similarityScore(reference, tt.car); score != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, score)
+			}
+		})
+	}
+}
+
+func TestSimilarCars(t *testing.T) {
+	cars := similarTestCars()
+	setTestCars(t, cars)
+
+	result := similarCars(cars[0], 4)
+
+	// The reference car and sold cars are never recommended
+	expectedIDs := []int{2, 3, 7, 4}
+	if len(result) != len(expectedIDs) {
+		t.Fatalf("Expected %d cars, got %d", len(expectedIDs), len(result))
+	}
+	for i, id := range expectedIDs {
+		if result[i].ID != id {
+			t.Errorf("Position %d: expected car %d, got %d (score %v)", i, id, result[i].ID, result[i].Score)
+		}
+	}
+	for i := 1; i < len(result); i++ {
+		if result[i].Score > result[i-1].Score {
+			t.Errorf("Expected scores in descending order, got %v before %v", result[i-1].Score, result[i].Score)
+		}
+	}
+
+	// The ranking is the same on every call
+	for i := 0; i < 10; i++ {
+		again := similarCars(cars[0], 4)
+		for j := range again {
+			if again[j].ID != result[j].ID || again[j].Score != result[j].Score {
+				t.Fatalf("Expected deterministic ranking, got %+v and %+v", result, again)
+			}
+		}
+	}
+}
+
+func TestParseSimilarLimit(t *testing.T) {
+	tests := map[string]int{"": DefaultSimilarLimit, "2": 2, "0": DefaultSimilarLimit, "-1": DefaultSimilarLimit, "abc": DefaultSimilarLimit, "500": MaxSimilarLimit}
+	for value, expected := range tests {
+		if result := parseSimilarLimit(value); result != expected {
+			t.Errorf("parseSimilarLimit(%q) = %d; want %d", value, result, expected)
+		}
+	}
+}
+
+func TestHandleSimilarCars(t *testing.T) {
+	setTestCars(t, similarTestCars())
+
+	tests := []struct {
+		name     string
+		method   string
+		id       string
+		limit    string
+		expected int
+		count    int
+	}{
+		{name: "Top 2", method: "GET", id: "1", limit: "2", expected: http.StatusOK, count: 2},
+		{name: "Default limit", method: "GET", id: "1", expected: http.StatusOK, count: DefaultSimilarLimit},
+		{name: "Unknown car", method: "GET", id: "99", expected: http.StatusNotFound},
+		{name: "Invalid ID", method: "GET", id: "abc", expected: http.StatusBadRequest},
+		{name: "Wrong method", method: "POST", id: "1", expected: http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := events.APIGatewayProxyRequest{
+				Resource:              "/cars/{id}/similar",
+				HTTPMethod:            tt.method,
+				PathParameters:        map[string]string{"id": tt.id},
+				QueryStringParameters: map[string]string{"limit": tt.limit},
+			}
+
+			response, err := handleRequest(context.Background(), request)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if response.StatusCode != tt.expected {
+				t.Fatalf("Expected status %d, got %d", tt.expected, response.StatusCode)
+			}
+			if tt.expected != http.StatusOK {
+				return
+			}
+
+			var body SimilarCarsResponse
+			if err := json.Unmarshal([]byte(response.Body), &body); err != nil {
+				t.Fatalf("Invalid response body: %v", err)
+			}
+			if body.CarID != 1 || len(body.Cars) != tt.count {
+				t.Errorf("Expected %d cars for car 1, got %d for car %d", tt.count, len(body.Cars), body.CarID)
+			}
+			if body.Cars[0].Title == "" || body.Cars[0].Score <= 0 {
+				t.Errorf("Expected car fields and score in response, got %+v", body.Cars[0])
+			}
+		})
+	}
+}
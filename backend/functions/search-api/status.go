@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	_ "time/tzdata" // The Lambda runtime image ships without zoneinfo
+)
+
+// Vehicle status lifecycle
+const (
+	StatusAvailable = "available"
+	StatusReserved  = "reserved"
+	StatusSold      = "sold"
+)
+
+// carStatuses maps accepted status spellings, including the German labels
+// used in the spreadsheet, to the status codes
+var carStatuses = map[string]string{
+	"":           StatusAvailable,
+	"available":  StatusAvailable,
+	"verfügbar":  StatusAvailable,
+	"verfuegbar": StatusAvailable,
+	"reserved":   StatusReserved,
+	"reserviert": StatusReserved,
+	"sold":       StatusSold,
+	"verkauft":   StatusSold,
+}
+
+// statusTimeLayouts are the accepted formats of reserved_at and sold_at
+var statusTimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"02.01.2006 15:04",
+	"02.01.2006",
+}
+
+// parseCarStatus returns the status code for a CSV value; an empty value means available
+func parseCarStatus(value string) (string, error) {
+	status, ok := carStatuses[strings.ToLower(strings.TrimSpace(value))]
+	if !ok {
+		return "", fmt.Errorf("unknown status %q", value)
+	}
+	return status, nil
+}
+
+// parseStatusTime parses an optional status timestamp. Values without a zone are Swiss local time.
+func parseStatusTime(value string) (*time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+
+	for _, layout := range statusTimeLayouts {
+		if t, err := time.ParseInLocation(layout, value, swissLocation); err == nil {
+			t = t.UTC()
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid timestamp %q", value)
+}
+
+// formatStatusTime formats a status timestamp for carToRecord
+func formatStatusTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// swissLocation is the zone of timestamps entered without offset
+var swissLocation = func() *time.Location {
+	location, err := time.LoadLocation("Europe/Zurich")
+	if err != nil {
+		panic(err)
+	}
+	return location
+}()
+
+// applyStatusChange stamps reserved_at or sold_at when a car enters that
+// status. New cars (previous is empty) keep a timestamp given by the caller;
+// leaving a status keeps its timestamp as history.
+func applyStatusChange(previous string, car *Car, now time.Time) {
+	if car.Status == "" {
+		car.Status = StatusAvailable
+	}
+	if car.Status == previous {
+		return
+	}
+
+	now = now.UTC()
+	switch car.Status {
+	case StatusReserved:
+		if car.ReservedAt == nil || previous != "" {
+			car.ReservedAt = &now
+		}
+	case StatusSold:
+		if car.SoldAt == nil || previous != "" {
+			car.SoldAt = &now
+		}
+	}
+}
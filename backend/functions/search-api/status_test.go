@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseCarStatus(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+		wantErr  bool
+	}{
+		{value: "", expected: StatusAvailable},
+		{value: "available", expected: StatusAvailable},
+		{value: " Reserviert ", expected: StatusReserved},
+		{value: "SOLD", expected: StatusSold},
+		{value: "verkauft", expected: StatusSold},
+		{value: "archived", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			status, err := parseCarStatus(tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error, got %q", status)
+				}
+				return
+			}
+			if err != nil || status != tt.expected {
+				t.Errorf("Expected %q, got %q (%v)", tt.expected, status, err)
+			}
+		})
+	}
+}
+
+func TestParseStatusTime(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+		wantErr  bool
+	}{
+		{value: "", expected: ""},
+		{value: "2024-05-03T10:15:00Z", expected: "2024-05-03T10:15:00Z"},
+		{value: "2024-05-03", expected: "2024-05-02T22:00:00Z"},
+		{value: "03.01.2024 14:30", expected: "2024-01-03T13:30:00Z"},
+		{value: "morgen", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			parsed, err := parseStatusTime(tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error, got %v", parsed)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result := formatStatusTime(parsed); result != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestApplyStatusChange(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	earlier := now.Add(-48 * time.Hour)
+
+	// A new car keeps a given timestamp
+	car := Car{Status: StatusSold, SoldAt: &earlier}
+	applyStatusChange("", &car, now)
+	if !car.SoldAt.Equal(earlier) {
+		t.Errorf("Expected given sold_at to be kept, got %v", car.SoldAt)
+	}
+
+	// Entering a status stamps it, leaving it keeps the timestamp
+	car = Car{Status: StatusReserved}
+	applyStatusChange(StatusAvailable, &car, now)
+	if car.ReservedAt == nil || !car.ReservedAt.Equal(now) {
+		t.Errorf("Expected reserved_at %v, got %v", now, car.ReservedAt)
+	}
+	car.Status = StatusSold
+	applyStatusChange(StatusReserved, &car, now.Add(time.Hour))
+	if car.SoldAt == nil || !car.SoldAt.Equal(now.Add(time.Hour)) || !car.ReservedAt.Equal(now) {
+		t.Errorf("Unexpected timestamps: reserved %v, sold %v", car.ReservedAt, car.SoldAt)
+	}
+
+	// Unchanged status keeps the timestamp
+	applyStatusChange(StatusSold, &car, now.Add(2*time.Hour))
+	if !car.SoldAt.Equal(now.Add(time.Hour)) {
+		t.Errorf("Expected sold_at to be unchanged, got %v", car.SoldAt)
+	}
+}
+
+func TestParseCarRecordStatus(t *testing.T) {
+	header, err := parseCSVHeader(strings.Split("id,title,price_chf,first_registration,car_type,mileage_km,transmission,fuel,drive,power_hp,power_kw,status,sold_at", ","))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	car, err := parseCarRecord(header, strings.Split("1,BMW 520d,42890,08.2021,Limousine,55000,Automatik,Diesel,Allrad,190,140,verkauft,2024-05-03T10:15:00Z", ","))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if car.Status != StatusSold || car.SoldAt == nil || car.ReservedAt != nil {
+		t.Errorf("Unexpected status fields: %q, %v, %v", car.Status, car.SoldAt, car.ReservedAt)
+	}
+
+	if _, err := parseCarRecord(header, strings.Split("1,BMW 520d,42890,08.2021,Limousine,55000,Automatik,Diesel,Allrad,190,140,weg,", ",")); err == nil {
+		t.Error("Expected error for unknown status")
+	}
+}
+
+func TestSearchCarsHidesSoldCars(t *testing.T) {
+	setTestCars(t, []Car{
+		{ID: 1, Title: "BMW 520d", Brand: "BMW", Status: StatusAvailable},
+		{ID: 2, Title: "BMW 320d", Brand: "BMW", Status: StatusReserved},
+		{ID: 3, Title: "BMW 118i", Brand: "BMW", Status: StatusSold},
+	})
+
+	if result := searchCars(SearchRequest{Brand: "BMW"}); result.Total != 2 {
+		t.Errorf("Expected sold car to be hidden, got %d cars", result.Total)
+	}
+	if result := searchCars(SearchRequest{Brand: "BMW", IncludeSold: true}); result.Total != 3 {
+		t.Errorf("Expected sold car with include_sold, got %d cars", result.Total)
+	}
+}
+
+func TestAdminCarsStatus(t *testing.T) {
+	store := setTestCarStore(t, testStoredCar(1, "BMW 520d"))
+	ctx := context.Background()
+
+	response, _ := handleRequest(ctx, adminRequest("PATCH", "/admin/cars/{id}", "1", `{"status": "reserviert"}`))
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, response.StatusCode, response.Body)
+	}
+	stored, _ := store.Get(ctx, 1)
+	if stored.Status != StatusReserved || stored.ReservedAt == nil {
+		t.Errorf("Expected reserved car with timestamp, got %q / %v", stored.Status, stored.ReservedAt)
+	}
+
+	response, _ = handleRequest(ctx, adminRequest("PATCH", "/admin/cars/{id}", "1", `{"status": "sold"}`))
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, response.StatusCode, response.Body)
+	}
+	stored, _ = store.Get(ctx, 1)
+	if stored.Status != StatusSold || stored.SoldAt == nil || stored.ReservedAt == nil {
+		t.Errorf("Expected sold car keeping its reservation time, got %+v", stored)
+	}
+	if result := searchCars(SearchRequest{}); result.Total != 0 {
+		t.Errorf("Expected sold car to disappear from search, got %d", result.Total)
+	}
+
+	response, _ = handleRequest(ctx, adminRequest("PATCH", "/admin/cars/{id}", "1", `{"status": "archived"}`))
+	if response.StatusCode != http.StatusBadRequest || !strings.Contains(response.Body, `"field":"status"`) {
+		t.Errorf("Expected status validation error, got %d: %s", response.StatusCode, response.Body)
+	}
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Suggestion limits
+const (
+	DefaultSuggestLimit   = 8
+	MaxSuggestLimit       = 20
+	MaxSuggestQueryLength = 50
+)
+
+// Suggestion types, in the order they are ranked
+const (
+	SuggestionBrand     = "brand"
+	SuggestionModel     = "model"
+	SuggestionCarType   = "car_type"
+	SuggestionEquipment = "equipment"
+)
+
+// suggestionTypeRank orders suggestions of equally good matches
+var suggestionTypeRank = map[string]int{
+	SuggestionBrand:     0,
+	SuggestionModel:     1,
+	SuggestionCarType:   2,
+	SuggestionEquipment: 3,
+}
+
+// Suggestion is a completion for the search box. Brand is set for models,
+// Code for car types and equipment; both can be used as search filters.
+type Suggestion struct {
+	Text  string `json:"text"`
+	Type  string `json:"type"`
+	Brand string `json:"brand,omitempty"`
+	Code  string `json:"code,omitempty"`
+	Count int    `json:"count"`
+}
+
+// SuggestResponse is the response of GET /search/suggest
+type SuggestResponse struct {
+	Query       string       `json:"query"`
+	Suggestions []Suggestion `json:"suggestions"`
+}
+
+// suggestionEntry indexes a suggestion under a normalized key. Keys start at
+// the beginning of the term or at one of its later words.
+type suggestionEntry struct {
+	key       string
+	termStart bool
+	index     int // Position in suggestionIndex.suggestions
+}
+
+// suggestionIndex holds the terms of a catalogue sorted by key, so a prefix
+// lookup is a binary search
+type suggestionIndex struct {
+	suggestions []Suggestion
+	entries     []suggestionEntry
+}
+
+// add indexes a suggestion under each of its terms and the later words of the terms
+func (x *suggestionIndex) add(suggestion Suggestion, terms ...string) {
+	index := len(x.suggestions)
+	x.suggestions = append(x.suggestions, suggestion)
+
+	seen := make(map[string]bool)
+	for _, term := range terms {
+		words := strings.Fields(normalizeString(term))
+		for i := range words {
+			key := strings.Join(words[i:], " ")
+			if key == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			x.entries = append(x.entries, suggestionEntry{key: key, termStart: i == 0, index: index})
+		}
+	}
+}
+
+// buildSuggestionIndex collects brands, models, car types and equipment of the available cars
+func buildSuggestionIndex(cars []Car) *suggestionIndex {
+	brands := make(map[string]int)
+	models := make(map[[2]string]int)
+	carTypes := make(map[string]int)
+	equipment := make(map[string]int)
+	for _, car := range cars {
+		if car.Status != StatusAvailable {
+			continue
+		}
+		if car.Brand != "" {
+			brands[car.Brand]++
+			if car.Model != "" {
+				models[[2]string{car.Brand, car.Model}]++
+			}
+		}
+		if car.CarType != "" {
+			carTypes[car.CarType]++
+		}
+		for _, code := range car.EquipmentCodes {
+			equipment[code]++
+		}
+	}
+
+	x := &suggestionIndex{}
+	for brand, count := range brands {
+		x.add(Suggestion{Text: brand, Type: SuggestionBrand, Count: count}, brand)
+	}
+	for key, count := range models {
+		brand, model := key[0], key[1]
+		x.add(Suggestion{Text: model, Type: SuggestionModel, Brand: brand, Count: count}, model, brand+" "+model)
+	}
+	for label, count := range carTypes {
+		suggestion := Suggestion{Text: label, Type: SuggestionCarType, Count: count}
+		terms := []string{label}
+		if value, ok := carTypeVocabulary.Lookup(label); ok {
+			suggestion.Code = value.Code
+		}
+		// Customers may type the label in their own language
+		for _, translated := range enumLabels[label] {
+			terms = append(terms, translated)
+		}
+		x.add(suggestion, terms...)
+	}
+	for _, feature := range equipmentVocabulary {
+		count := equipment[feature.Code]
+		if count == 0 {
+			continue
+		}
+		terms := []string{feature.Label}
+		for _, translated := range equipmentLabels[feature.Code] {
+			terms = append(terms, translated)
+		}
+		x.add(Suggestion{Text: feature.Label, Type: SuggestionEquipment, Code: feature.Code, Count: count}, terms...)
+	}
+
+	sort.Slice(x.entries, func(i, j int) bool {
+		return x.entries[i].key < x.entries[j].key
+	})
+	return x
+}
+
+// Suggest returns up to limit suggestions whose terms start with the query.
+// Matches at the start of a term rank before matches of later words, then
+// brands before models, car types and equipment, then more cars first.
+func (x *suggestionIndex) Suggest(query string, limit int) []Suggestion {
+	prefix := strings.Join(strings.Fields(normalizeString(query)), " ")
+	if prefix == "" {
+		return []Suggestion{}
+	}
+
+	// Best match per suggestion
+	termStart := make(map[int]bool)
+	first := sort.Search(len(x.entries), func(i int) bool { return x.entries[i].key >= prefix })
+	for _, entry := range x.entries[first:] {
+		if !strings.HasPrefix(entry.key, prefix) {
+			break
+		}
+		termStart[entry.index] = termStart[entry.index] || entry.termStart
+	}
+
+	matches := make([]int, 0, len(termStart))
+	for index := range termStart {
+		matches = append(matches, index)
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		a, b := x.suggestions[matches[i]], x.suggestions[matches[j]]
+		if termStart[matches[i]] != termStart[matches[j]] {
+			return termStart[matches[i]]
+		}
+		if suggestionTypeRank[a.Type] != suggestionTypeRank[b.Type] {
+			return suggestionTypeRank[a.Type] < suggestionTypeRank[b.Type]
+		}
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if a.Text != b.Text {
+			return a.Text < b.Text
+		}
+		return a.Brand < b.Brand
+	})
+
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	suggestions := make([]Suggestion, len(matches))
+	for i, index := range matches {
+		suggestions[i] = x.suggestions[index]
+	}
+	return suggestions
+}
+
+// localizeSuggestions returns the suggestions with car types and equipment labelled in lang
+func localizeSuggestions(suggestions []Suggestion, lang string) []Suggestion {
+	if lang == "" {
+		return suggestions
+	}
+	for i, suggestion := range suggestions {
+		switch suggestion.Type {
+		case SuggestionCarType:
+			suggestions[i].Text = enumLabel(lang, suggestion.Text)
+		case SuggestionEquipment:
+			if feature, ok := lookupEquipment(suggestion.Code); ok {
+				suggestions[i].Text = equipmentLabel(lang, feature)
+			}
+		}
+	}
+	return suggestions
+}
+
+// parseSuggestLimit parses the limit parameter, falling back to DefaultSuggestLimit
+func parseSuggestLimit(value string) int {
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit <= 0 {
+		return DefaultSuggestLimit
+	}
+	if limit > MaxSuggestLimit {
+		return MaxSuggestLimit
+	}
+	return limit
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func suggestTestCars() []Car {
+	return []Car{
+		{ID: 1, Brand: "Land Rover", Model: "Range Rover Sport", CarType: "SUV", Status: StatusAvailable},
+		{ID: 2, Brand: "Land Rover", Model: "Defender", CarType: "SUV", Status: StatusAvailable},
+		{ID: 3, Brand: "Škoda", Model: "Octavia", CarType: "Kombi", EquipmentCodes: []string{"sport-seats"}, Status: StatusAvailable},
+		{ID: 4, Brand: "Seat", Model: "Leon", CarType: "Kombi", EquipmentCodes: []string{"sport-seats", "heated-seats"}, Status: StatusAvailable},
+		{ID: 5, Brand: "Lamborghini", Model: "Urus", CarType: "SUV", Status: StatusSold},
+	}
+}
+
+// suggestionTexts lists the suggestions as "type:text"
+func suggestionTexts(suggestions []Suggestion) []string {
+	texts := make([]string, len(suggestions))
+	for i, suggestion := range suggestions {
+		texts[i] = suggestion.Type + ":" + suggestion.Text
+	}
+	return texts
+}
+
+func TestSuggest(t *testing.T) {
+	index := buildSuggestionIndex(suggestTestCars())
+
+	tests := []struct {
+		query    string
+		limit    int
+		expected []string
+	}{
+		// Models are also found by brand and model, e.g. "land rover def"
+		{query: "la", limit: 10, expected: []string{"brand:Land Rover", "model:Defender", "model:Range Rover Sport"}},
+		{query: "sko", limit: 10, expected: []string{"brand:Škoda", "model:Octavia"}},
+		{query: "  RANGE  ro", limit: 10, expected: []string{"model:Range Rover Sport"}},
+		{query: "rover", limit: 10, expected: []string{"brand:Land Rover", "model:Defender", "model:Range Rover Sport"}},
+		{query: "land rover d", limit: 10, expected: []string{"model:Defender"}},
+		// Later words rank last; Kombi matches its Italian label "Station wagon"
+		{query: "s", limit: 10, expected: []string{"brand:Seat", "brand:Škoda", "model:Leon", "model:Octavia", "car_type:Kombi", "car_type:SUV", "equipment:Sportsitze", "equipment:Sitzheizung", "model:Range Rover Sport"}},
+		{query: "s", limit: 2, expected: []string{"brand:Seat", "brand:Škoda"}},
+		{query: "break", limit: 10, expected: []string{"car_type:Kombi"}},
+		{query: "urus", limit: 10, expected: []string{}},
+		{query: " ", limit: 10, expected: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			if texts := suggestionTexts(index.Suggest(tt.query, tt.limit)); !reflect.DeepEqual(texts, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, texts)
+			}
+		})
+	}
+}
+
+func TestSuggestCarriesFilterValues(t *testing.T) {
+	index := buildSuggestionIndex(suggestTestCars())
+
+	suggestions := index.Suggest("def", 10)
+	if len(suggestions) != 1 || suggestions[0].Brand != "Land Rover" || suggestions[0].Count != 1 {
+		t.Errorf("Unexpected model suggestion %+v", suggestions)
+	}
+	suggestions = index.Suggest("sportsitze", 10)
+	if len(suggestions) != 1 || suggestions[0].Code != "sport-seats" || suggestions[0].Count != 2 {
+		t.Errorf("Unexpected equipment suggestion %+v", suggestions)
+	}
+}
+
+func TestParseSuggestLimit(t *testing.T) {
+	tests := map[string]int{"": DefaultSuggestLimit, "abc": DefaultSuggestLimit, "-1": DefaultSuggestLimit, "3": 3, "1000": MaxSuggestLimit}
+	for value, expected := range tests {
+		if limit := parseSuggestLimit(value); limit != expected {
+			t.Errorf("parseSuggestLimit(%q): expected %d, got %d", value, expected, limit)
+		}
+	}
+}
+
+func TestHandleSuggest(t *testing.T) {
+	setTestCars(t, suggestTestCars())
+
+	request := func(method string, query map[string]string, lang string) events.APIGatewayProxyResponse {
+		response, err := handleRequest(context.Background(), events.APIGatewayProxyRequest{
+			HTTPMethod:            method,
+			Resource:              "/search/suggest",
+			QueryStringParameters: query,
+			Headers:               map[string]string{"Accept-Language": lang},
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return response
+	}
+
+	response := request("GET", map[string]string{"q": "sit"}, "en")
+	if response.StatusCode != http.StatusOK || response.Headers["ETag"] == "" {
+		t.Fatalf("Unexpected response %d: %v", response.StatusCode, response.Headers)
+	}
+	var result SuggestResponse
+	if err := json.Unmarshal([]byte(response.Body), &result); err != nil {
+		t.Fatalf("Invalid response body: %v", err)
+	}
+	if result.Query != "sit" || len(result.Suggestions) != 1 || result.Suggestions[0].Text != "Heated seats" || result.Suggestions[0].Code != "heated-seats" {
+		t.Errorf("Unexpected suggestions %+v", result)
+	}
+
+	if tooLong := request("GET", map[string]string{"q": strings.Repeat("a", MaxSuggestQueryLength+1)}, ""); tooLong.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a long query, got %d", tooLong.StatusCode)
+	}
+	if post := request("POST", nil, ""); post.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 for POST, got %d", post.StatusCode)
+	}
+}
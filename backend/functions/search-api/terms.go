@@ -0,0 +1,100 @@
+package main
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxWarrantyMonths bounds the min_warranty_months filter
+const MaxWarrantyMonths = 240
+
+// Patterns for the leasing and warranty texts of the sheet, e.g.
+// "Ab 580.- pro Monat ohne Anzahlung" or
+// "Ab 1. Inverkehrsetzung, 19.08.2021, 24 Monate oder 100'000 km".
+// The leasing rate is the amount followed by a monthly unit, or else the
+// amount after "CHF", so a term like "48 Monate" is never read as the rate.
+var (
+	leasingMonthlyRegex = regexp.MustCompile(`(?i)` + leasingAmount + `\s*(?:CHF|Fr\.)?\s*(?:/\s*M(?:t|on)|pro\s+Monat|mtl|monatl)`)
+	leasingCHFRegex     = regexp.MustCompile(`(?i)(?:CHF|Fr\.)\s*` + leasingAmount)
+	downPaymentRegex    = regexp.MustCompile(`(?i)\b(ohne|keine)\s+anzahlung`)
+	warrantyPeriodRegex = regexp.MustCompile(`(?i)(\d+)\s*(monat|jahr)`)
+	warrantyKMRegex     = regexp.MustCompile(`(?i)(\d{1,3}(?:['’.]\d{3})+|\d+)\s*km\b`)
+	warrantyStartRegex  = regexp.MustCompile(`\b\d{1,2}\.\d{1,2}\.\d{4}\b`)
+)
+
+// leasingAmount matches "1'250", "399.-" or "499.50"; the groups are the
+// francs and the cents
+const leasingAmount = `(\d{1,3}(?:['’]\d{3})+|\d+)(?:[.,](?:(\d{1,2})|[-–—]))?`
+
+// LeasingTerms are the structured values of a leasing text
+type LeasingTerms struct {
+	RateCHF     int
+	DownPayment *bool
+}
+
+// WarrantyTerms are the structured values of a warranty text
+type WarrantyTerms struct {
+	Months int
+	KM     int
+	Start  string
+}
+
+// parseLeasingText extracts the monthly rate and whether a down payment is
+// required. Rates with cents are rounded up so max_leasing_rate never
+// includes a car above the limit. DownPayment is nil if the text doesn't say.
+func parseLeasingText(text string) LeasingTerms {
+	var terms LeasingTerms
+
+	match := leasingMonthlyRegex.FindStringSubmatch(text)
+	if match == nil {
+		match = leasingCHFRegex.FindStringSubmatch(text)
+	}
+	if match != nil {
+		if rate, err := parseSwissInt(match[1]); err == nil {
+			if cents, _ := strconv.Atoi(match[2]); cents > 0 {
+				rate++
+			}
+			terms.RateCHF = rate
+		}
+	}
+
+	if terms.RateCHF > 0 && strings.Contains(strings.ToLower(text), "anzahlung") {
+		downPayment := !downPaymentRegex.MatchString(text)
+		terms.DownPayment = &downPayment
+	}
+
+	return terms
+}
+
+// parseWarrantyText extracts the duration, the mileage limit and the start
+// date. Texts without a duration, such as "Werksgarantie bis 2026", leave
+// Months at 0.
+func parseWarrantyText(text string) WarrantyTerms {
+	var terms WarrantyTerms
+
+	if match := warrantyPeriodRegex.FindStringSubmatch(text); match != nil {
+		if n, err := strconv.Atoi(match[1]); err == nil {
+			if strings.ToLower(match[2]) == "jahr" {
+				n *= 12
+			}
+			terms.Months = n
+		}
+	}
+
+	if match := warrantyKMRegex.FindStringSubmatch(text); match != nil {
+		digits := strings.NewReplacer("'", "", "’", "", ".", "").Replace(match[1])
+		if km, err := strconv.Atoi(digits); err == nil {
+			terms.KM = km
+		}
+	}
+
+	if match := warrantyStartRegex.FindString(text); match != "" {
+		if start, err := time.Parse("2.1.2006", match); err == nil {
+			terms.Start = start.Format("2006-01-02")
+		}
+	}
+
+	return terms
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseLeasingText(t *testing.T) {
+	yes, no := true, false
+
+	tests := []struct {
+		text        string
+		rate        int
+		downPayment *bool
+	}{
+		{text: "Ab 580.- pro Monat ohne Anzahlung", rate: 580, downPayment: &no},
+		{text: "Ab 530.- mtl.", rate: 530},
+		{text: "CHF 1'250.- / Monat, 10% Anzahlung", rate: 1250, downPayment: &yes},
+		{text: "Leasing ab 499.50 mtl., keine Anzahlung", rate: 500, downPayment: &no},
+		{text: "48 Monate, CHF 399.–/Mt.", rate: 399},
+		{text: "Laufzeit 36 Monate, 10'000 km/Jahr, 459.- pro Monat", rate: 459},
+		{text: "60 Monate à CHF 289", rate: 289},
+		{text: "Anzahlung CHF 5'000, 48 Monate, CHF 612.40/Monat", rate: 613, downPayment: &yes},
+		{text: "48 Monate Laufzeit", rate: 0},
+		{text: "Leasing auf Anfrage", rate: 0},
+		{text: "", rate: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			terms := parseLeasingText(tt.text)
+			if terms.RateCHF != tt.rate {
+				t.Errorf("Expected rate %d, got %d", tt.rate, terms.RateCHF)
+			}
+			if (terms.DownPayment == nil) != (tt.downPayment == nil) || (terms.DownPayment != nil && *terms.DownPayment != *tt.downPayment) {
+				t.Errorf("Expected down payment %v, got %v", tt.downPayment, terms.DownPayment)
+			}
+		})
+	}
+}
+
+func TestParseWarrantyText(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected WarrantyTerms
+	}{
+		{text: "Ab 1. Inverkehrsetzung, 19.08.2021, 24 Monate oder 100'000 km", expected: WarrantyTerms{Months: 24, KM: 100000, Start: "2021-08-19"}},
+		{text: "Audi Occasion Plus, 12 Monate Garantie", expected: WarrantyTerms{Months: 12}},
+		{text: "5 Jahre Garantie", expected: WarrantyTerms{Months: 60}},
+		{text: "1 Jahr oder 20.000 km ab 1.3.2024", expected: WarrantyTerms{Months: 12, KM: 20000, Start: "2024-03-01"}},
+		{text: "Werksgarantie bis 2026", expected: WarrantyTerms{}},
+		{text: "", expected: WarrantyTerms{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if terms := parseWarrantyText(tt.text); terms != tt.expected {
+				t.Errorf("Expected %+v, got %+v", tt.expected, terms)
+			}
+		})
+	}
+}
+
+func TestParseCarRecordTerms(t *testing.T) {
+	record := strings.Split("1|BMW 520d|42890|Ab 580.- pro Monat ohne Anzahlung|08.2021|Limousine|55000|Automatik|Diesel|Allrad|190|140|True|True|24 Monate oder 100'000 km|||", "|")
+	car, err := parseCarRecord(defaultCSVHeader, record)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// The original texts are kept next to the structured values
+	if car.LeasingText != "Ab 580.- pro Monat ohne Anzahlung" || car.WarrantyText != "24 Monate oder 100&#39;000 km" {
+		t.Errorf("Unexpected texts: %q, %q", car.LeasingText, car.WarrantyText)
+	}
+	if car.LeasingRateCHF != 580 || car.LeasingDownPayment == nil || *car.LeasingDownPayment {
+		t.Errorf("Unexpected leasing terms: %d, %v", car.LeasingRateCHF, car.LeasingDownPayment)
+	}
+	if car.WarrantyMonths != 24 || car.WarrantyKM != 100000 {
+		t.Errorf("Unexpected warranty terms: %d months, %d km", car.WarrantyMonths, car.WarrantyKM)
+	}
+}
+
+func TestSearchCarsLeasingAndWarrantyFilters(t *testing.T) {
+	no := false
+	setTestCars(t, []Car{
+		{ID: 1, Title: "BMW 520d", LeasingRateCHF: 580, LeasingDownPayment: &no, Warranty: true, WarrantyMonths: 24, Status: StatusAvailable},
+		{ID: 2, Title: "Audi A4", LeasingRateCHF: 490, Warranty: true, WarrantyMonths: 12, Status: StatusAvailable},
+		{ID: 3, Title: "Tesla Model 3", Warranty: true, Status: StatusAvailable},
+		{ID: 4, Title: "Fiat Panda", LeasingRateCHF: 190, Warranty: false, WarrantyMonths: 12, Status: StatusAvailable},
+	})
+
+	tests := []struct {
+		name     string
+		req      SearchRequest
+		expected int
+	}{
+		{name: "Leasing under 500", req: SearchRequest{MaxLeasingRate: intPtr(500)}, expected: 2},
+		{name: "Without down payment", req: SearchRequest{LeasingWithoutDownPayment: true}, expected: 1},
+		{name: "Warranty of at least 12 months", req: SearchRequest{MinWarrantyMonths: intPtr(12)}, expected: 2},
+		{name: "Warranty of at least 24 months", req: SearchRequest{MinWarrantyMonths: intPtr(24)}, expected: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := searchCars(tt.req); result.Total != tt.expected {
+				t.Errorf("Expected %d cars, got %d", tt.expected, result.Total)
+			}
+		})
+	}
+}
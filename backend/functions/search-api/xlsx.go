@@ -0,0 +1,262 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Maximum size of a single XML part read from a workbook
+const maxXLSXPartSize = 20 << 20
+
+// Number of columns of an Excel worksheet, "A" to "XFD"
+const maxXLSXColumns = 16384
+
+// xlsxDateEpoch is day 0 of Excel date serials in the default 1900 date
+// system; starting on 30 December skips Excel's nonexistent 29 February 1900
+var xlsxDateEpoch = time.Date(1899, time.December, 30, 0, 0, 0, 0, time.UTC)
+
+// xlsxWorkbook is the subset of xl/workbook.xml needed to find the first sheet
+type xlsxWorkbook struct {
+	Sheets []struct {
+		Name string `xml:"name,attr"`
+		RID  string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+// xlsxRelationships is the subset of xl/_rels/workbook.xml.rels mapping sheet IDs to files
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+// xlsxRichText is a string item that may be split into formatted runs
+type xlsxRichText struct {
+	Text string `xml:"t"`
+	Runs []struct {
+		Text string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxRichText) String() string {
+	if len(t.Runs) == 0 {
+		return t.Text
+	}
+	var b strings.Builder
+	for _, run := range t.Runs {
+		b.WriteString(run.Text)
+	}
+	return b.String()
+}
+
+// xlsxSharedStrings is xl/sharedStrings.xml
+type xlsxSharedStrings struct {
+	Items []xlsxRichText `xml:"si"`
+}
+
+// xlsxSheet is the subset of a worksheet needed to read cell values
+type xlsxSheet struct {
+	Rows []struct {
+		Cells []struct {
+			Ref    string       `xml:"r,attr"`
+			Type   string       `xml:"t,attr"`
+			Value  string       `xml:"v"`
+			Inline xlsxRichText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// readXLSXRecords reads the first worksheet of an .xlsx workbook into rows of
+// strings, the same shape csv.Reader produces. Rows are padded to the width of
+// the header row so that empty trailing cells do not shift columns. Dates in
+// the first_registration column are written as "MM.YYYY" like in autos.csv.
+func readXLSXRecords(data []byte) ([][]string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("not a valid workbook: %w", err)
+	}
+
+	var workbook xlsxWorkbook
+	if err := readXLSXPart(archive, "xl/workbook.xml", &workbook); err != nil {
+		return nil, err
+	}
+	if len(workbook.Sheets) == 0 {
+		return nil, fmt.Errorf("workbook contains no sheets")
+	}
+
+	var rels xlsxRelationships
+	if err := readXLSXPart(archive, "xl/_rels/workbook.xml.rels", &rels); err != nil {
+		return nil, err
+	}
+	sheetPath := ""
+	for _, rel := range rels.Relationships {
+		if rel.ID == workbook.Sheets[0].RID {
+			sheetPath = rel.Target
+			break
+		}
+	}
+	if sheetPath == "" {
+		return nil, fmt.Errorf("sheet %q not found in workbook", workbook.Sheets[0].Name)
+	}
+	if strings.HasPrefix(sheetPath, "/") {
+		sheetPath = strings.TrimPrefix(sheetPath, "/")
+	} else {
+		sheetPath = path.Join("xl", sheetPath)
+	}
+
+	// Shared strings are optional; workbooks with only inline strings omit them
+	var shared xlsxSharedStrings
+	if err := readXLSXPart(archive, "xl/sharedStrings.xml", &shared); err != nil && !isMissingXLSXPart(err) {
+		return nil, err
+	}
+
+	var sheet xlsxSheet
+	if err := readXLSXPart(archive, sheetPath, &sheet); err != nil {
+		return nil, err
+	}
+
+	records := make([][]string, 0, len(sheet.Rows))
+	width := 0
+	dateColumn := -1
+	for _, row := range sheet.Rows {
+		record := make([]string, 0, width)
+		for i, cell := range row.Cells {
+			column := i
+			if cell.Ref != "" {
+				column, err = xlsxColumnIndex(cell.Ref)
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			value, err := xlsxCellValue(cell.Type, cell.Value, cell.Inline, shared)
+			if err != nil {
+				return nil, fmt.Errorf("cell %s: %w", cell.Ref, err)
+			}
+			if column == dateColumn && (cell.Type == "" || cell.Type == "n") {
+				if date, ok := xlsxDate(cell.Value); ok {
+					value = date.Format("01.2006")
+				}
+			}
+
+			for len(record) <= column {
+				record = append(record, "")
+			}
+			record[column] = value
+		}
+
+		if width == 0 {
+			width = len(record)
+			for i, name := range record {
+				if normalizeHeaderName(name) == "first_registration" {
+					dateColumn = i
+				}
+			}
+		}
+		for len(record) < width {
+			record = append(record, "")
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// errMissingXLSXPart is returned when a workbook part does not exist
+type errMissingXLSXPart string
+
+func (e errMissingXLSXPart) Error() string {
+	return fmt.Sprintf("workbook part %s not found", string(e))
+}
+
+func isMissingXLSXPart(err error) bool {
+	_, ok := err.(errMissingXLSXPart)
+	return ok
+}
+
+// readXLSXPart decodes a single XML file from the workbook archive
+func readXLSXPart(archive *zip.Reader, name string, v interface{}) error {
+	for _, file := range archive.File {
+		if file.Name != name {
+			continue
+		}
+		rc, err := file.Open()
+		if err != nil {
+			return fmt.Errorf("error opening %s: %w", name, err)
+		}
+		defer rc.Close()
+
+		if err := xml.NewDecoder(io.LimitReader(rc, maxXLSXPartSize)).Decode(v); err != nil {
+			return fmt.Errorf("error decoding %s: %w", name, err)
+		}
+		return nil
+	}
+	return errMissingXLSXPart(name)
+}
+
+// xlsxColumnIndex converts a cell reference like "C12" into a zero-based
+// column index. Columns beyond XFD do not exist in Excel and are rejected.
+func xlsxColumnIndex(ref string) (int, error) {
+	column := 0
+	for _, r := range ref {
+		if r >= 'A' && r <= 'Z' {
+			column = column*26 + int(r-'A') + 1
+			if column > maxXLSXColumns {
+				return 0, fmt.Errorf("cell reference %q beyond column XFD", ref)
+			}
+			continue
+		}
+		break
+	}
+	if column == 0 {
+		return 0, fmt.Errorf("invalid cell reference %q", ref)
+	}
+	return column - 1, nil
+}
+
+// xlsxCellValue returns the text of a cell the way it would appear in a CSV export
+func xlsxCellValue(cellType, value string, inline xlsxRichText, shared xlsxSharedStrings) (string, error) {
+	switch cellType {
+	case "s":
+		i, err := strconv.Atoi(value)
+		if err != nil || i < 0 || i >= len(shared.Items) {
+			return "", fmt.Errorf("invalid shared string index %q", value)
+		}
+		return shared.Items[i].String(), nil
+	case "inlineStr":
+		return inline.String(), nil
+	case "b":
+		if value == "1" {
+			return "True", nil
+		}
+		return "False", nil
+	case "str", "e":
+		return value, nil
+	}
+
+	// Numeric cells: whole numbers are written without a decimal part so
+	// that they pass the integer parsing of parseCarRecord
+	if f, err := strconv.ParseFloat(value, 64); err == nil && f == float64(int64(f)) {
+		return strconv.FormatInt(int64(f), 10), nil
+	}
+	return value, nil
+}
+
+// xlsxDate converts an Excel date serial such as "44409" (1 August 2021) into
+// a date. The time of day in the fraction is ignored.
+func xlsxDate(value string) (time.Time, bool) {
+	serial, err := strconv.ParseFloat(value, 64)
+	// 2958465 is 31 December 9999, the last date Excel supports
+	if err != nil || serial < 1 || serial > 2958465 {
+		return time.Time{}, false
+	}
+	return xlsxDateEpoch.AddDate(0, 0, int(serial)), true
+}
@@ -123,6 +123,14 @@ resource "aws_api_gateway_method" "contact_post" {
   authorization = "NONE"
 }
 
+# API Gateway Method: GET /contact (Auswahlfelder der Formulare je Sprache)
+resource "aws_api_gateway_method" "contact_get" {
+  rest_api_id   = aws_api_gateway_rest_api.contact_form_api.id
+  resource_id   = aws_api_gateway_resource.contact_resource.id
+  http_method   = "GET"
+  authorization = "NONE"
+}
+
 # API Gateway Method: OPTIONS /contact (CORS)
 resource "aws_api_gateway_method" "contact_options" {
   rest_api_id   = aws_api_gateway_rest_api.contact_form_api.id
@@ -142,6 +150,17 @@ resource "aws_api_gateway_integration" "contact_integration" {
   uri                     = aws_lambda_function.contact_form.invoke_arn
 }
 
+# API Gateway Integration: GET /contact -> Lambda
+resource "aws_api_gateway_integration" "contact_get_integration" {
+  rest_api_id = aws_api_gateway_rest_api.contact_form_api.id
+  resource_id = aws_api_gateway_resource.contact_resource.id
+  http_method = aws_api_gateway_method.contact_get.http_method
+
+  integration_http_method = "POST"
+  type                    = "AWS_PROXY"
+  uri                     = aws_lambda_function.contact_form.invoke_arn
+}
+
 # CORS Integration for OPTIONS /contact
 resource "aws_api_gateway_integration" "contact_cors_integration" {
   rest_api_id = aws_api_gateway_rest_api.contact_form_api.id
@@ -185,7 +204,7 @@ resource "aws_api_gateway_integration_response" "contact_cors_integration_respon
 
   response_parameters = {
     "method.response.header.Access-Control-Allow-Headers" = "'Content-Type,X-Amz-Date,Authorization,X-Api-Key,X-Amz-Security-Token'"
-    "method.response.header.Access-Control-Allow-Methods" = "'POST,OPTIONS'"
+    "method.response.header.Access-Control-Allow-Methods" = "'GET,POST,OPTIONS'"
     "method.response.header.Access-Control-Allow-Origin"  = "'*'"
   }
 
@@ -206,6 +225,7 @@ resource "aws_lambda_permission" "contact_api_gateway_lambda" {
 resource "aws_api_gateway_deployment" "contact_form_deployment" {
   depends_on = [
     aws_api_gateway_integration.contact_integration,
+    aws_api_gateway_integration.contact_get_integration,
     aws_api_gateway_integration.contact_cors_integration,
   ]
 
@@ -215,8 +235,10 @@ resource "aws_api_gateway_deployment" "contact_form_deployment" {
     redeployment = sha1(jsonencode([
       aws_api_gateway_resource.contact_resource.id,
       aws_api_gateway_method.contact_post.id,
+      aws_api_gateway_method.contact_get.id,
       aws_api_gateway_method.contact_options.id,
       aws_api_gateway_integration.contact_integration.id,
+      aws_api_gateway_integration.contact_get_integration.id,
       aws_api_gateway_integration.contact_cors_integration.id,
     ]))
   }
@@ -97,12 +97,203 @@ resource "aws_iam_role_policy" "search_api_s3_coverage" {
   })
 }
 
+# DynamoDB permissions for the admin car store
+resource "aws_iam_role_policy" "search_api_cars_table" {
+  name = "search-api-cars-table-policy"
+  role = aws_iam_role.search_api_lambda_role.id
+
+  policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [
+      {
+        Effect = "Allow"
+        Action = [
+          "dynamodb:GetItem",
+          "dynamodb:PutItem",
+          "dynamodb:DeleteItem",
+          "dynamodb:Scan"
+        ]
+        Resource = aws_dynamodb_table.cars.arn
+      }
+    ]
+  })
+}
+
+# DynamoDB permissions for saved searches
+resource "aws_iam_role_policy" "search_api_saved_searches_table" {
+  name = "search-api-saved-searches-table-policy"
+  role = aws_iam_role.search_api_lambda_role.id
+
+  policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [
+      {
+        Effect = "Allow"
+        Action = [
+          "dynamodb:GetItem",
+          "dynamodb:PutItem",
+          "dynamodb:DeleteItem",
+          "dynamodb:Scan"
+        ]
+        Resource = aws_dynamodb_table.saved_searches.arn
+      },
+      {
+        Effect   = "Allow"
+        Action   = ["dynamodb:Query"]
+        Resource = "${aws_dynamodb_table.saved_searches.arn}/index/email-index"
+      }
+    ]
+  })
+}
+
+# SES permissions for the saved search emails, same sender as the contact form
+resource "aws_iam_role_policy" "search_api_ses" {
+  name = "search-api-ses-policy"
+  role = aws_iam_role.search_api_lambda_role.id
+
+  policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [
+      {
+        Effect = "Allow"
+        Action = [
+          "ses:SendEmail",
+          "ses:SendRawEmail"
+        ]
+        Resource = "*"
+        Condition = {
+          StringEquals = {
+            "ses:FromAddress" = var.sender_email
+          }
+        }
+      }
+    ]
+  })
+}
+
 # Attach basic execution policy to Lambda role
 resource "aws_iam_role_policy_attachment" "search_api_lambda_basic" {
   policy_arn = "arn:aws:iam::aws:policy/service-role/AWSLambdaBasicExecutionRole"
   role       = aws_iam_role.search_api_lambda_role.name
 }
 
+# Authentifizierung für Admin- und interne Endpunkte der Search API.
+# Der API Key wird nur als SHA-256-Hash an die Lambda übergeben.
+variable "admin_api_key" {
+  description = "API key for admin and internal endpoints"
+  type        = string
+  sensitive   = true
+  default     = ""
+}
+
+variable "auth_jwt_hs256_secret" {
+  description = "Shared secret for HS256 signed JWTs"
+  type        = string
+  sensitive   = true
+  default     = ""
+}
+
+variable "auth_jwt_rs256_public_key" {
+  description = "PEM encoded public key for RS256 signed JWTs"
+  type        = string
+  default     = ""
+}
+
+variable "financing_interest_rate" {
+  description = "Nominal yearly interest rate in percent for financing quotes"
+  type        = string
+  default     = "3.9"
+}
+
+variable "financing_included_mileage_km" {
+  description = "Yearly mileage included in the financing rate"
+  type        = number
+  default     = 10000
+}
+
+variable "financing_excess_mileage_rappen" {
+  description = "Charge in Rappen per km and year above the included mileage"
+  type        = number
+  default     = 5
+}
+
+variable "search_cache_control" {
+  description = "Cache-Control header of /search and /search/options responses, empty to omit"
+  type        = string
+  default     = "public, max-age=60"
+}
+
+variable "search_surrogate_control" {
+  description = "Surrogate-Control header of /search and /search/options responses, empty to omit"
+  type        = string
+  default     = "max-age=300"
+}
+
+variable "search_cursor_secret" {
+  description = "Secret for signing search cursors, shared by all Lambda instances"
+  type        = string
+  sensitive   = true
+  default     = ""
+}
+
+locals {
+  auth_environment = {
+    AUTH_API_KEY_SHA256       = var.admin_api_key == "" ? "" : sha256(var.admin_api_key)
+    AUTH_JWT_HS256_SECRET     = var.auth_jwt_hs256_secret
+    AUTH_JWT_RS256_PUBLIC_KEY = var.auth_jwt_rs256_public_key
+  }
+}
+
+# Fahrzeuge, die über die Admin-API gepflegt werden
+resource "aws_dynamodb_table" "cars" {
+  name         = "search-api-cars"
+  billing_mode = "PAY_PER_REQUEST"
+  hash_key     = "id"
+
+  attribute {
+    name = "id"
+    type = "N"
+  }
+
+  point_in_time_recovery {
+    enabled = true
+  }
+}
+
+# Suchaufträge mit E-Mail-Benachrichtigung, Schlüssel ist das Token aus den Links
+resource "aws_dynamodb_table" "saved_searches" {
+  name         = "search-api-saved-searches"
+  billing_mode = "PAY_PER_REQUEST"
+  hash_key     = "id"
+
+  attribute {
+    name = "id"
+    type = "S"
+  }
+
+  attribute {
+    name = "email_key"
+    type = "S"
+  }
+
+  # Zählt die Suchaufträge pro E-Mail-Adresse (kleingeschrieben) ohne Scan
+  global_secondary_index {
+    name            = "email-index"
+    hash_key        = "email_key"
+    projection_type = "KEYS_ONLY"
+  }
+
+  point_in_time_recovery {
+    enabled = true
+  }
+}
+
+variable "saved_search_alert_schedule" {
+  description = "Schedule expression of the saved search alert job"
+  type        = string
+  default     = "rate(1 hour)"
+}
+
 # Search API Lambda function mit DDoS Schutz
 resource "aws_lambda_function" "search_api" {
   filename      = data.archive_file.search_api_zip.output_path
@@ -120,9 +311,29 @@ resource "aws_lambda_function" "search_api" {
   source_code_hash = data.archive_file.search_api_zip.output_base64sha256
 
   environment {
-    variables = {
+    variables = merge(local.auth_environment, {
       ENV = "production"
-    }
+      # Katalog wird aus dem Data Bucket nachgeladen, ohne Cold Start
+      CATALOGUE_URL = "https://${aws_s3_bucket.data_bucket.bucket}.s3.${data.aws_region.current.name}.amazonaws.com/autos.csv"
+      CATALOGUE_TTL = "5m"
+      # Admin-API: Fahrzeuge in DynamoDB, autos.csv nur solange die Tabelle leer ist
+      CARS_TABLE = aws_dynamodb_table.cars.name
+      # Konditionen für /financing/quote
+      FINANCING_INTEREST_RATE         = var.financing_interest_rate
+      FINANCING_INCLUDED_MILEAGE_KM   = tostring(var.financing_included_mileage_km)
+      FINANCING_EXCESS_MILEAGE_RAPPEN = tostring(var.financing_excess_mileage_rappen)
+      # Signiert die next_cursor Tokens von /search
+      CURSOR_SECRET = var.search_cursor_secret
+      # Cache Header für /search und /search/options (ETag kommt aus der Lambda)
+      CACHE_CONTROL     = var.search_cache_control
+      SURROGATE_CONTROL = var.search_surrogate_control
+      # Suchaufträge: Bestätigungs- und Abmeldelinks zeigen direkt auf die API
+      # (die CloudFront URL würde einen Zyklus erzeugen), Alerts verlinken die Website
+      SAVED_SEARCHES_TABLE = aws_dynamodb_table.saved_searches.name
+      SENDER_EMAIL         = var.sender_email
+      PUBLIC_API_URL       = "https://${aws_api_gateway_rest_api.search_api_gateway.id}.execute-api.${data.aws_region.current.name}.amazonaws.com/prod"
+      SITE_URL             = "https://${aws_cloudfront_distribution.frontend.domain_name}"
+    })
   }
 
   depends_on = [
@@ -142,6 +353,10 @@ resource "aws_api_gateway_rest_api" "search_api_gateway" {
   name        = "search-api"
   description = "Search API for car listings"
 
+  # Komprimierte Antworten kommen base64-kodiert aus der Lambda und werden
+  # von API Gateway als Binärdaten ausgeliefert
+  binary_media_types = ["*/*"]
+
   endpoint_configuration {
     types = ["REGIONAL"]
   }
@@ -177,6 +392,14 @@ resource "aws_api_gateway_method" "search_post" {
   authorization = "NONE"
 }
 
+# API Gateway Method: GET /search (Filter als Query-Parameter, cachebar)
+resource "aws_api_gateway_method" "search_get" {
+  rest_api_id   = aws_api_gateway_rest_api.search_api_gateway.id
+  resource_id   = aws_api_gateway_resource.search_resource.id
+  http_method   = "GET"
+  authorization = "NONE"
+}
+
 # API Gateway Method: OPTIONS /search (CORS)
 resource "aws_api_gateway_method" "search_options" {
   rest_api_id   = aws_api_gateway_rest_api.search_api_gateway.id
@@ -215,6 +438,17 @@ resource "aws_api_gateway_integration" "search_integration" {
   uri                     = aws_lambda_function.search_api.invoke_arn
 }
 
+# API Gateway Integration: GET /search -> Lambda
+resource "aws_api_gateway_integration" "search_get_integration" {
+  rest_api_id = aws_api_gateway_rest_api.search_api_gateway.id
+  resource_id = aws_api_gateway_resource.search_resource.id
+  http_method = aws_api_gateway_method.search_get.http_method
+
+  integration_http_method = "POST"
+  type                    = "AWS_PROXY"
+  uri                     = aws_lambda_function.search_api.invoke_arn
+}
+
 # CORS Integration for OPTIONS /search
 resource "aws_api_gateway_integration" "search_cors_integration" {
   rest_api_id = aws_api_gateway_rest_api.search_api_gateway.id
@@ -283,6 +517,268 @@ resource "aws_api_gateway_method_response" "search_options_cors_response_200" {
   }
 }
 
+# API Gateway Resource: /search/suggest
+resource "aws_api_gateway_resource" "search_suggest_resource" {
+  rest_api_id = aws_api_gateway_rest_api.search_api_gateway.id
+  parent_id   = aws_api_gateway_resource.search_resource.id
+  path_part   = "suggest"
+}
+
+# API Gateway Method: ANY /search/suggest (GET und CORS in der Lambda)
+resource "aws_api_gateway_method" "search_suggest_any" {
+  rest_api_id   = aws_api_gateway_rest_api.search_api_gateway.id
+  resource_id   = aws_api_gateway_resource.search_suggest_resource.id
+  http_method   = "ANY"
+  authorization = "NONE"
+}
+
+# API Gateway Integration: /search/suggest -> Lambda
+resource "aws_api_gateway_integration" "search_suggest_integration" {
+  rest_api_id = aws_api_gateway_rest_api.search_api_gateway.id
+  resource_id = aws_api_gateway_resource.search_suggest_resource.id
+  http_method = aws_api_gateway_method.search_suggest_any.http_method
+
+  integration_http_method = "POST"
+  type                    = "AWS_PROXY"
+  uri                     = aws_lambda_function.search_api.invoke_arn
+}
+
+# API Gateway Resource: /saved-searches
+resource "aws_api_gateway_resource" "saved_searches_resource" {
+  rest_api_id = aws_api_gateway_rest_api.search_api_gateway.id
+  parent_id   = aws_api_gateway_rest_api.search_api_gateway.root_resource_id
+  path_part   = "saved-searches"
+}
+
+# API Gateway Method: ANY /saved-searches (POST und CORS in der Lambda)
+resource "aws_api_gateway_method" "saved_searches_any" {
+  rest_api_id   = aws_api_gateway_rest_api.search_api_gateway.id
+  resource_id   = aws_api_gateway_resource.saved_searches_resource.id
+  http_method   = "ANY"
+  authorization = "NONE"
+}
+
+# API Gateway Integration: /saved-searches -> Lambda
+resource "aws_api_gateway_integration" "saved_searches_integration" {
+  rest_api_id = aws_api_gateway_rest_api.search_api_gateway.id
+  resource_id = aws_api_gateway_resource.saved_searches_resource.id
+  http_method = aws_api_gateway_method.saved_searches_any.http_method
+
+  integration_http_method = "POST"
+  type                    = "AWS_PROXY"
+  uri                     = aws_lambda_function.search_api.invoke_arn
+}
+
+# API Gateway Resource: /saved-searches/confirm (Link aus der Bestätigungs-E-Mail)
+resource "aws_api_gateway_resource" "saved_search_confirm_resource" {
+  rest_api_id = aws_api_gateway_rest_api.search_api_gateway.id
+  parent_id   = aws_api_gateway_resource.saved_searches_resource.id
+  path_part   = "confirm"
+}
+
+# API Gateway Method: ANY /saved-searches/confirm
+resource "aws_api_gateway_method" "saved_search_confirm_any" {
+  rest_api_id   = aws_api_gateway_rest_api.search_api_gateway.id
+  resource_id   = aws_api_gateway_resource.saved_search_confirm_resource.id
+  http_method   = "ANY"
+  authorization = "NONE"
+}
+
+# API Gateway Integration: /saved-searches/confirm -> Lambda
+resource "aws_api_gateway_integration" "saved_search_confirm_integration" {
+  rest_api_id = aws_api_gateway_rest_api.search_api_gateway.id
+  resource_id = aws_api_gateway_resource.saved_search_confirm_resource.id
+  http_method = aws_api_gateway_method.saved_search_confirm_any.http_method
+
+  integration_http_method = "POST"
+  type                    = "AWS_PROXY"
+  uri                     = aws_lambda_function.search_api.invoke_arn
+}
+
+# API Gateway Resource: /saved-searches/unsubscribe (Link aus jeder Alert-E-Mail)
+resource "aws_api_gateway_resource" "saved_search_unsubscribe_resource" {
+  rest_api_id = aws_api_gateway_rest_api.search_api_gateway.id
+  parent_id   = aws_api_gateway_resource.saved_searches_resource.id
+  path_part   = "unsubscribe"
+}
+
+# API Gateway Method: ANY /saved-searches/unsubscribe
+resource "aws_api_gateway_method" "saved_search_unsubscribe_any" {
+  rest_api_id   = aws_api_gateway_rest_api.search_api_gateway.id
+  resource_id   = aws_api_gateway_resource.saved_search_unsubscribe_resource.id
+  http_method   = "ANY"
+  authorization = "NONE"
+}
+
+# API Gateway Integration: /saved-searches/unsubscribe -> Lambda
+resource "aws_api_gateway_integration" "saved_search_unsubscribe_integration" {
+  rest_api_id = aws_api_gateway_rest_api.search_api_gateway.id
+  resource_id = aws_api_gateway_resource.saved_search_unsubscribe_resource.id
+  http_method = aws_api_gateway_method.saved_search_unsubscribe_any.http_method
+
+  integration_http_method = "POST"
+  type                    = "AWS_PROXY"
+  uri                     = aws_lambda_function.search_api.invoke_arn
+}
+
+# API Gateway Resource: /cars
+resource "aws_api_gateway_resource" "cars_resource" {
+  rest_api_id = aws_api_gateway_rest_api.search_api_gateway.id
+  parent_id   = aws_api_gateway_rest_api.search_api_gateway.root_resource_id
+  path_part   = "cars"
+}
+
+# API Gateway Resource: /cars/{id}
+resource "aws_api_gateway_resource" "car_resource" {
+  rest_api_id = aws_api_gateway_rest_api.search_api_gateway.id
+  parent_id   = aws_api_gateway_resource.cars_resource.id
+  path_part   = "{id}"
+}
+
+# API Gateway Resource: /cars/{id}/similar
+resource "aws_api_gateway_resource" "car_similar_resource" {
+  rest_api_id = aws_api_gateway_rest_api.search_api_gateway.id
+  parent_id   = aws_api_gateway_resource.car_resource.id
+  path_part   = "similar"
+}
+
+# API Gateway Method: ANY /cars/{id}/similar (GET und CORS in der Lambda)
+resource "aws_api_gateway_method" "car_similar_any" {
+  rest_api_id   = aws_api_gateway_rest_api.search_api_gateway.id
+  resource_id   = aws_api_gateway_resource.car_similar_resource.id
+  http_method   = "ANY"
+  authorization = "NONE"
+}
+
+# API Gateway Integration: /cars/{id}/similar -> Lambda
+resource "aws_api_gateway_integration" "car_similar_integration" {
+  rest_api_id = aws_api_gateway_rest_api.search_api_gateway.id
+  resource_id = aws_api_gateway_resource.car_similar_resource.id
+  http_method = aws_api_gateway_method.car_similar_any.http_method
+
+  integration_http_method = "POST"
+  type                    = "AWS_PROXY"
+  uri                     = aws_lambda_function.search_api.invoke_arn
+}
+
+# API Gateway Resource: /cars/compare
+resource "aws_api_gateway_resource" "cars_compare_resource" {
+  rest_api_id = aws_api_gateway_rest_api.search_api_gateway.id
+  parent_id   = aws_api_gateway_resource.cars_resource.id
+  path_part   = "compare"
+}
+
+# API Gateway Method: ANY /cars/compare (POST und CORS in der Lambda)
+resource "aws_api_gateway_method" "cars_compare_any" {
+  rest_api_id   = aws_api_gateway_rest_api.search_api_gateway.id
+  resource_id   = aws_api_gateway_resource.cars_compare_resource.id
+  http_method   = "ANY"
+  authorization = "NONE"
+}
+
+# API Gateway Integration: /cars/compare -> Lambda
+resource "aws_api_gateway_integration" "cars_compare_integration" {
+  rest_api_id = aws_api_gateway_rest_api.search_api_gateway.id
+  resource_id = aws_api_gateway_resource.cars_compare_resource.id
+  http_method = aws_api_gateway_method.cars_compare_any.http_method
+
+  integration_http_method = "POST"
+  type                    = "AWS_PROXY"
+  uri                     = aws_lambda_function.search_api.invoke_arn
+}
+
+# API Gateway Resource: /financing
+resource "aws_api_gateway_resource" "financing_resource" {
+  rest_api_id = aws_api_gateway_rest_api.search_api_gateway.id
+  parent_id   = aws_api_gateway_rest_api.search_api_gateway.root_resource_id
+  path_part   = "financing"
+}
+
+# API Gateway Resource: /financing/quote
+resource "aws_api_gateway_resource" "financing_quote_resource" {
+  rest_api_id = aws_api_gateway_rest_api.search_api_gateway.id
+  parent_id   = aws_api_gateway_resource.financing_resource.id
+  path_part   = "quote"
+}
+
+# API Gateway Method: ANY /financing/quote (POST und CORS in der Lambda)
+resource "aws_api_gateway_method" "financing_quote_any" {
+  rest_api_id   = aws_api_gateway_rest_api.search_api_gateway.id
+  resource_id   = aws_api_gateway_resource.financing_quote_resource.id
+  http_method   = "ANY"
+  authorization = "NONE"
+}
+
+# API Gateway Integration: /financing/quote -> Lambda
+resource "aws_api_gateway_integration" "financing_quote_integration" {
+  rest_api_id = aws_api_gateway_rest_api.search_api_gateway.id
+  resource_id = aws_api_gateway_resource.financing_quote_resource.id
+  http_method = aws_api_gateway_method.financing_quote_any.http_method
+
+  integration_http_method = "POST"
+  type                    = "AWS_PROXY"
+  uri                     = aws_lambda_function.search_api.invoke_arn
+}
+
+# API Gateway Resource: /admin
+resource "aws_api_gateway_resource" "admin_resource" {
+  rest_api_id = aws_api_gateway_rest_api.search_api_gateway.id
+  parent_id   = aws_api_gateway_rest_api.search_api_gateway.root_resource_id
+  path_part   = "admin"
+}
+
+# API Gateway Resource: /admin/cars
+resource "aws_api_gateway_resource" "admin_cars_resource" {
+  rest_api_id = aws_api_gateway_rest_api.search_api_gateway.id
+  parent_id   = aws_api_gateway_resource.admin_resource.id
+  path_part   = "cars"
+}
+
+# API Gateway Resource: /admin/cars/{id}
+resource "aws_api_gateway_resource" "admin_car_resource" {
+  rest_api_id = aws_api_gateway_rest_api.search_api_gateway.id
+  parent_id   = aws_api_gateway_resource.admin_cars_resource.id
+  path_part   = "{id}"
+}
+
+# API Gateway Method: ANY /admin/cars (Authentifizierung in der Lambda)
+resource "aws_api_gateway_method" "admin_cars_any" {
+  rest_api_id   = aws_api_gateway_rest_api.search_api_gateway.id
+  resource_id   = aws_api_gateway_resource.admin_cars_resource.id
+  http_method   = "ANY"
+  authorization = "NONE"
+}
+
+# API Gateway Method: ANY /admin/cars/{id} (Authentifizierung in der Lambda)
+resource "aws_api_gateway_method" "admin_car_any" {
+  rest_api_id   = aws_api_gateway_rest_api.search_api_gateway.id
+  resource_id   = aws_api_gateway_resource.admin_car_resource.id
+  http_method   = "ANY"
+  authorization = "NONE"
+}
+
+# API Gateway Integration: /admin/cars -> Lambda
+resource "aws_api_gateway_integration" "admin_cars_integration" {
+  rest_api_id = aws_api_gateway_rest_api.search_api_gateway.id
+  resource_id = aws_api_gateway_resource.admin_cars_resource.id
+  http_method = aws_api_gateway_method.admin_cars_any.http_method
+
+  integration_http_method = "POST"
+  type                    = "AWS_PROXY"
+  uri                     = aws_lambda_function.search_api.invoke_arn
+}
+
+# API Gateway Integration: /admin/cars/{id} -> Lambda
+resource "aws_api_gateway_integration" "admin_car_integration" {
+  rest_api_id = aws_api_gateway_rest_api.search_api_gateway.id
+  resource_id = aws_api_gateway_resource.admin_car_resource.id
+  http_method = aws_api_gateway_method.admin_car_any.http_method
+
+  integration_http_method = "POST"
+  type                    = "AWS_PROXY"
+  uri                     = aws_lambda_function.search_api.invoke_arn
+}
+
 # Integration Response for OPTIONS /search (CORS)
 resource "aws_api_gateway_integration_response" "search_cors_integration_response" {
   rest_api_id = aws_api_gateway_rest_api.search_api_gateway.id
@@ -325,13 +821,69 @@ resource "aws_lambda_permission" "api_gateway_lambda" {
   source_arn = "${aws_api_gateway_rest_api.search_api_gateway.execution_arn}/*/*"
 }
 
+# Lambda permission for S3 to trigger a catalogue reload
+resource "aws_lambda_permission" "data_bucket_lambda" {
+  statement_id  = "AllowExecutionFromDataBucket"
+  action        = "lambda:InvokeFunction"
+  function_name = aws_lambda_function.search_api.function_name
+  principal     = "s3.amazonaws.com"
+  source_arn    = aws_s3_bucket.data_bucket.arn
+}
+
+# Katalog sofort neu laden, wenn autos.csv im Data Bucket ersetzt wird
+resource "aws_s3_bucket_notification" "catalogue_reload" {
+  bucket = aws_s3_bucket.data_bucket.id
+
+  lambda_function {
+    lambda_function_arn = aws_lambda_function.search_api.arn
+    events              = ["s3:ObjectCreated:*"]
+    filter_prefix       = "autos.csv"
+  }
+
+  depends_on = [aws_lambda_permission.data_bucket_lambda]
+}
+
+# Suchaufträge regelmässig gegen den Katalog laufen lassen und neue Treffer mailen
+resource "aws_cloudwatch_event_rule" "saved_search_alerts" {
+  name                = "search-api-saved-search-alerts"
+  description         = "Sendet E-Mail-Alerts für neue Fahrzeuge zu Suchaufträgen"
+  schedule_expression = var.saved_search_alert_schedule
+}
+
+resource "aws_cloudwatch_event_target" "saved_search_alerts" {
+  rule = aws_cloudwatch_event_rule.saved_search_alerts.name
+  arn  = aws_lambda_function.search_api.arn
+
+  # Konstanter Input statt des Scheduled Events, sonst würde nur der Katalog neu geladen
+  input = jsonencode({ job = "saved-search-alerts" })
+}
+
+# Lambda permission for EventBridge to run the saved search alerts
+resource "aws_lambda_permission" "saved_search_alerts_lambda" {
+  statement_id  = "AllowExecutionFromSavedSearchAlerts"
+  action        = "lambda:InvokeFunction"
+  function_name = aws_lambda_function.search_api.function_name
+  principal     = "events.amazonaws.com"
+  source_arn    = aws_cloudwatch_event_rule.saved_search_alerts.arn
+}
+
 # API Gateway Deployment
 resource "aws_api_gateway_deployment" "search_api_deployment" {
   depends_on = [
     aws_api_gateway_integration.search_options_integration,
     aws_api_gateway_integration.search_integration,
+    aws_api_gateway_integration.search_get_integration,
     aws_api_gateway_integration.search_cors_integration,
     aws_api_gateway_integration.search_options_cors_integration,
+    aws_api_gateway_integration.search_suggest_integration,
+    aws_api_gateway_integration.admin_cars_integration,
+    aws_api_gateway_integration.admin_car_integration,
+    aws_api_gateway_integration.car_similar_integration,
+    aws_api_gateway_integration.cars_compare_integration,
+    aws_api_gateway_integration.financing_quote_integration,
+    aws_api_gateway_integration.saved_searches_integration,
+    aws_api_gateway_integration.saved_search_confirm_integration,
+    aws_api_gateway_integration.saved_search_unsubscribe_integration,
   ]
 
   rest_api_id = aws_api_gateway_rest_api.search_api_gateway.id
@@ -342,12 +894,41 @@ resource "aws_api_gateway_deployment" "search_api_deployment" {
       aws_api_gateway_resource.search_options_resource.id,
       aws_api_gateway_method.search_options_get.id,
       aws_api_gateway_method.search_post.id,
+      aws_api_gateway_method.search_get.id,
       aws_api_gateway_method.search_options.id,
       aws_api_gateway_method.search_options_options.id,
       aws_api_gateway_integration.search_options_integration.id,
       aws_api_gateway_integration.search_integration.id,
+      aws_api_gateway_integration.search_get_integration.id,
       aws_api_gateway_integration.search_cors_integration.id,
       aws_api_gateway_integration.search_options_cors_integration.id,
+      aws_api_gateway_resource.search_suggest_resource.id,
+      aws_api_gateway_method.search_suggest_any.id,
+      aws_api_gateway_integration.search_suggest_integration.id,
+      aws_api_gateway_resource.admin_cars_resource.id,
+      aws_api_gateway_resource.admin_car_resource.id,
+      aws_api_gateway_method.admin_cars_any.id,
+      aws_api_gateway_method.admin_car_any.id,
+      aws_api_gateway_integration.admin_cars_integration.id,
+      aws_api_gateway_integration.admin_car_integration.id,
+      aws_api_gateway_resource.car_similar_resource.id,
+      aws_api_gateway_method.car_similar_any.id,
+      aws_api_gateway_integration.car_similar_integration.id,
+      aws_api_gateway_resource.cars_compare_resource.id,
+      aws_api_gateway_method.cars_compare_any.id,
+      aws_api_gateway_integration.cars_compare_integration.id,
+      aws_api_gateway_resource.financing_quote_resource.id,
+      aws_api_gateway_method.financing_quote_any.id,
+      aws_api_gateway_integration.financing_quote_integration.id,
+      aws_api_gateway_resource.saved_searches_resource.id,
+      aws_api_gateway_method.saved_searches_any.id,
+      aws_api_gateway_integration.saved_searches_integration.id,
+      aws_api_gateway_resource.saved_search_confirm_resource.id,
+      aws_api_gateway_method.saved_search_confirm_any.id,
+      aws_api_gateway_integration.saved_search_confirm_integration.id,
+      aws_api_gateway_resource.saved_search_unsubscribe_resource.id,
+      aws_api_gateway_method.saved_search_unsubscribe_any.id,
+      aws_api_gateway_integration.saved_search_unsubscribe_integration.id,
     ]))
   }
 
@@ -412,9 +993,10 @@ resource "aws_cloudfront_distribution" "search_api_cdn" {
     target_origin_id = "search-api-gateway"
     compress         = true
 
+    # Sprache per ?lang= oder Accept-Language gehört zum Cache-Key
     forwarded_values {
-      query_string = false
-      headers      = ["Origin", "Access-Control-Request-Headers", "Access-Control-Request-Method"]
+      query_string = true
+      headers      = ["Accept-Language", "Origin", "Access-Control-Request-Headers", "Access-Control-Request-Method"]
       cookies {
         forward = "none"
       }
@@ -426,7 +1008,73 @@ resource "aws_cloudfront_distribution" "search_api_cdn" {
     max_ttl                = 3600 # 1 Stunde max
   }
 
-  # Default Cache-Verhalten für /search (POST nicht cachebar)
+  # Cache-Verhalten für /search/suggest: je Eingabe gecacht, damit Tastenanschläge selten die Lambda erreichen
+  ordered_cache_behavior {
+    path_pattern     = "/search/suggest"
+    allowed_methods  = ["GET", "HEAD", "OPTIONS"]
+    cached_methods   = ["GET", "HEAD", "OPTIONS"]
+    target_origin_id = "search-api-gateway"
+    compress         = true
+
+    forwarded_values {
+      query_string = true
+      headers      = ["Accept-Language", "Origin", "Access-Control-Request-Headers", "Access-Control-Request-Method"]
+      cookies {
+        forward = "none"
+      }
+    }
+
+    viewer_protocol_policy = "redirect-to-https"
+    min_ttl                = 0
+    default_ttl            = 300 # 5 Minuten, wie CATALOGUE_TTL
+    max_ttl                = 900
+  }
+
+  # Cache-Verhalten für /cars/{id}/similar (ändert sich nur mit dem Katalog)
+  ordered_cache_behavior {
+    path_pattern     = "/cars/*/similar"
+    allowed_methods  = ["GET", "HEAD", "OPTIONS"]
+    cached_methods   = ["GET", "HEAD", "OPTIONS"]
+    target_origin_id = "search-api-gateway"
+    compress         = true
+
+    forwarded_values {
+      query_string = true
+      headers      = ["Accept-Language", "Origin", "Access-Control-Request-Headers", "Access-Control-Request-Method"]
+      cookies {
+        forward = "none"
+      }
+    }
+
+    viewer_protocol_policy = "redirect-to-https"
+    min_ttl                = 0
+    default_ttl            = 300 # 5 Minuten, wie CATALOGUE_TTL
+    max_ttl                = 900
+  }
+
+  # Cache-Verhalten für /search: GET wird pro Query-String gecacht, POST nie
+  ordered_cache_behavior {
+    path_pattern     = "/search"
+    allowed_methods  = ["DELETE", "GET", "HEAD", "OPTIONS", "PATCH", "POST", "PUT"]
+    cached_methods   = ["GET", "HEAD"]
+    target_origin_id = "search-api-gateway"
+    compress         = true
+
+    forwarded_values {
+      query_string = true
+      headers      = ["Accept-Language", "Content-Type", "Origin", "Access-Control-Request-Headers", "Access-Control-Request-Method"]
+      cookies {
+        forward = "none"
+      }
+    }
+
+    viewer_protocol_policy = "redirect-to-https"
+    min_ttl                = 0
+    default_ttl            = 300 # 5 Minuten, wie CATALOGUE_TTL
+    max_ttl                = 900
+  }
+
+  # Default Cache-Verhalten (POST nicht cachebar)
   default_cache_behavior {
     allowed_methods  = ["DELETE", "GET", "HEAD", "OPTIONS", "PATCH", "POST", "PUT"]
     cached_methods   = ["GET", "HEAD"]
@@ -435,7 +1083,7 @@ resource "aws_cloudfront_distribution" "search_api_cdn" {
 
     forwarded_values {
       query_string = true
-      headers      = ["Content-Type", "Origin", "Access-Control-Request-Headers", "Access-Control-Request-Method"]
+      headers      = ["Accept-Language", "Content-Type", "Origin", "Access-Control-Request-Headers", "Access-Control-Request-Method"]
       cookies {
         forward = "none"
       }